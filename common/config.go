@@ -4,6 +4,7 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
@@ -15,6 +16,53 @@ type Configuration struct {
 	DB_PASSWORD string
 	DB_PORT     int
 	JWT_SECRET  string
+
+	// PEM file of the RSA or Ed25519 private key signing access tokens,
+	// JWT_SECRET being used when there is none
+	JWT_SIGNING_KEY_FILE string
+
+	// PEM files of the previous keys, still verifying the tokens they signed
+	JWT_VERIFICATION_KEY_FILES []string
+
+	// number of days deleted items are kept in the trash before they can be purged
+	TRASH_RETENTION_DAYS int
+
+	// number of minutes between two refreshes of the recipe recommendations
+	RECOMMENDATION_REFRESH_MINUTES int
+
+	// number of minutes access tokens are valid
+	ACCESS_TOKEN_MINUTES int
+
+	// number of days refresh tokens are valid
+	REFRESH_TOKEN_DAYS int
+
+	// minimum number of characters of passwords
+	PASSWORD_MIN_LENGTH int
+
+	// minimum number of classes of characters of passwords, among lowercase
+	// letters, uppercase letters, digits and symbols
+	PASSWORD_MIN_CHAR_CLASSES int
+
+	// cost of the bcrypt hashes of passwords, rehashed on login when it changes
+	BCRYPT_COST int
+
+	// number of failed logins locking out a username, or an IP address
+	LOGIN_MAX_FAILURES        int
+	LOGIN_MAX_FAILURES_PER_IP int
+
+	// number of seconds to wait after a failed login, doubling with each next one
+	LOGIN_BACKOFF_SECONDS int
+
+	// number of minutes usernames and IP addresses are locked out
+	LOGIN_LOCKOUT_MINUTES int
+
+	// whether admins must log in with two-factor authentication
+	MFA_REQUIRED_FOR_ADMINS bool
+
+	// credentials of the admin created when there is none, a random
+	// password being generated when empty
+	ADMIN_USERNAME string
+	ADMIN_PASSWORD string
 }
 
 func LoadConfig() Configuration {
@@ -36,5 +84,112 @@ func LoadConfig() Configuration {
 	config.DB_USER = os.Getenv("DB_USER")
 	config.DB_PORT = port
 	config.JWT_SECRET = os.Getenv("JWT_SECRET")
+	config.JWT_SIGNING_KEY_FILE = os.Getenv("JWT_SIGNING_KEY_FILE")
+	for _, file := range strings.Split(os.Getenv("JWT_VERIFICATION_KEY_FILES"), ",") {
+		if file = strings.TrimSpace(file); file != "" {
+			config.JWT_VERIFICATION_KEY_FILES = append(config.JWT_VERIFICATION_KEY_FILES, file)
+		}
+	}
+
+	config.TRASH_RETENTION_DAYS = 30
+	if retention := os.Getenv("TRASH_RETENTION_DAYS"); retention != "" {
+		config.TRASH_RETENTION_DAYS, err = strconv.Atoi(retention)
+		if err != nil || config.TRASH_RETENTION_DAYS < 0 {
+			log.Fatal("Failed to parsed trash retention")
+		}
+	}
+
+	config.RECOMMENDATION_REFRESH_MINUTES = 15
+	if refresh := os.Getenv("RECOMMENDATION_REFRESH_MINUTES"); refresh != "" {
+		config.RECOMMENDATION_REFRESH_MINUTES, err = strconv.Atoi(refresh)
+		if err != nil || config.RECOMMENDATION_REFRESH_MINUTES < 1 {
+			log.Fatal("Failed to parsed recommendation refresh interval")
+		}
+	}
+
+	config.ACCESS_TOKEN_MINUTES = 15
+	if lifetime := os.Getenv("ACCESS_TOKEN_MINUTES"); lifetime != "" {
+		config.ACCESS_TOKEN_MINUTES, err = strconv.Atoi(lifetime)
+		if err != nil || config.ACCESS_TOKEN_MINUTES < 1 {
+			log.Fatal("Failed to parsed access token lifetime")
+		}
+	}
+
+	config.REFRESH_TOKEN_DAYS = 30
+	if lifetime := os.Getenv("REFRESH_TOKEN_DAYS"); lifetime != "" {
+		config.REFRESH_TOKEN_DAYS, err = strconv.Atoi(lifetime)
+		if err != nil || config.REFRESH_TOKEN_DAYS < 1 {
+			log.Fatal("Failed to parsed refresh token lifetime")
+		}
+	}
+
+	config.PASSWORD_MIN_LENGTH = 8
+	if length := os.Getenv("PASSWORD_MIN_LENGTH"); length != "" {
+		config.PASSWORD_MIN_LENGTH, err = strconv.Atoi(length)
+		if err != nil || config.PASSWORD_MIN_LENGTH < 1 {
+			log.Fatal("Failed to parsed password minimum length")
+		}
+	}
+
+	config.PASSWORD_MIN_CHAR_CLASSES = 2
+	if classes := os.Getenv("PASSWORD_MIN_CHAR_CLASSES"); classes != "" {
+		config.PASSWORD_MIN_CHAR_CLASSES, err = strconv.Atoi(classes)
+		if err != nil || config.PASSWORD_MIN_CHAR_CLASSES < 0 || config.PASSWORD_MIN_CHAR_CLASSES > 4 {
+			log.Fatal("Failed to parsed password character classes")
+		}
+	}
+
+	config.BCRYPT_COST = 10
+	if cost := os.Getenv("BCRYPT_COST"); cost != "" {
+		config.BCRYPT_COST, err = strconv.Atoi(cost)
+		if err != nil {
+			log.Fatal("Failed to parsed bcrypt cost")
+		}
+	}
+
+	config.LOGIN_MAX_FAILURES = 5
+	if failures := os.Getenv("LOGIN_MAX_FAILURES"); failures != "" {
+		config.LOGIN_MAX_FAILURES, err = strconv.Atoi(failures)
+		if err != nil || config.LOGIN_MAX_FAILURES < 1 {
+			log.Fatal("Failed to parsed maximum number of failed logins")
+		}
+	}
+
+	config.LOGIN_MAX_FAILURES_PER_IP = 20
+	if failures := os.Getenv("LOGIN_MAX_FAILURES_PER_IP"); failures != "" {
+		config.LOGIN_MAX_FAILURES_PER_IP, err = strconv.Atoi(failures)
+		if err != nil || config.LOGIN_MAX_FAILURES_PER_IP < 1 {
+			log.Fatal("Failed to parsed maximum number of failed logins per IP address")
+		}
+	}
+
+	config.LOGIN_BACKOFF_SECONDS = 1
+	if backoff := os.Getenv("LOGIN_BACKOFF_SECONDS"); backoff != "" {
+		config.LOGIN_BACKOFF_SECONDS, err = strconv.Atoi(backoff)
+		if err != nil || config.LOGIN_BACKOFF_SECONDS < 0 {
+			log.Fatal("Failed to parsed failed login backoff")
+		}
+	}
+
+	config.LOGIN_LOCKOUT_MINUTES = 15
+	if lockout := os.Getenv("LOGIN_LOCKOUT_MINUTES"); lockout != "" {
+		config.LOGIN_LOCKOUT_MINUTES, err = strconv.Atoi(lockout)
+		if err != nil || config.LOGIN_LOCKOUT_MINUTES < 1 {
+			log.Fatal("Failed to parsed login lockout duration")
+		}
+	}
+
+	if required := os.Getenv("MFA_REQUIRED_FOR_ADMINS"); required != "" {
+		config.MFA_REQUIRED_FOR_ADMINS, err = strconv.ParseBool(required)
+		if err != nil {
+			log.Fatal("Failed to parsed whether two-factor authentication is required for admins")
+		}
+	}
+
+	config.ADMIN_USERNAME = os.Getenv("ADMIN_USERNAME")
+	if config.ADMIN_USERNAME == "" {
+		config.ADMIN_USERNAME = "admin"
+	}
+	config.ADMIN_PASSWORD = os.Getenv("ADMIN_PASSWORD")
 	return config
 }
@@ -1,19 +1,23 @@
 package controller
 
 import (
-	"log"
 	"strconv"
 
+	"github.com/denisyao1/welsh-academy-api/exception"
+	"github.com/denisyao1/welsh-academy-api/middleware"
 	"github.com/gofiber/fiber/v2"
 )
 
 // BaseController contains common method for all controllers.
 type BaseController struct{}
 
-// HandleUnExpetedError handles errors the api didn't except.
-func (b BaseController) HandleUnExpetedError(err error, ctx *fiber.Ctx) error {
-	log.Println("UnExpectedError: ", err.Error())
-	return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+// LocalizeErrors translates in place the messages of the validation errors in a locale.
+func (b BaseController) LocalizeErrors(errs []error, locale string) {
+	for i, err := range errs {
+		if errValidation, ok := err.(exception.ErrValidation); ok {
+			errs[i] = errValidation.Localize(locale)
+		}
+	}
 }
 
 // GetConnectedUserID returns the connected user id.
@@ -21,7 +25,35 @@ func (b BaseController) GetConnectedUserID(ctx *fiber.Ctx) (int, error) {
 	return strconv.Atoi(ctx.Locals("userID").(string))
 }
 
+// GetSessionID returns the session of the connected user, or an empty string
+// if the request isn't authenticated.
+func (b BaseController) GetSessionID(ctx *fiber.Ctx) string {
+	sessionID, _ := ctx.Locals("sessionID").(string)
+	return sessionID
+}
+
 // ConvertParamToInt convert path or query param to int.
 func (b BaseController) ConvertParamToInt(paramName string, ctx *fiber.Ctx) (int, error) {
 	return strconv.Atoi(ctx.Params(paramName))
 }
+
+// GetLimit returns the limit query param, defaultLimit if it's missing.
+// It returns an error if the limit is not between 1 and maxLimit.
+func (b BaseController) GetLimit(ctx *fiber.Ctx) (int, error) {
+	limit := ctx.QueryInt("limit", defaultLimit)
+	if limit < 1 || limit > maxLimit {
+		return 0, exception.NewErrValidation("limit", exception.CodeInvalidLimit, maxLimit)
+	}
+	return limit, nil
+}
+
+// GetLocale returns the locale the user wants the content in, from the lang query param
+// or else the Accept-Language header, falling back to the default locale.
+// It sets the Content-Language header accordingly.
+func (b BaseController) GetLocale(ctx *fiber.Ctx) string {
+	locale := middleware.GetLocale(ctx)
+
+	ctx.Set(fiber.HeaderContentLanguage, locale)
+	ctx.Vary(fiber.HeaderAcceptLanguage)
+	return locale
+}
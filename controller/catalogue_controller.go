@@ -0,0 +1,152 @@
+package controller
+
+import (
+	"bufio"
+	"io"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/denisyao1/welsh-academy-api/exception"
+	"github.com/denisyao1/welsh-academy-api/schema"
+	"github.com/denisyao1/welsh-academy-api/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+// CatalogueController contains methods to route catalogue import and export requests.
+type CatalogueController struct {
+	BaseController
+	service service.CatalogueService
+}
+
+// NewCatalogueController returns new CatalogueController object.
+func NewCatalogueController(service service.CatalogueService) CatalogueController {
+	return CatalogueController{service: service}
+}
+
+//	Import creates ingredients and recipes from a CSV or NDJSON catalogue.
+//
+// @Summary      Import catalogue
+// @Description  Create ingredients and recipes from a CSV or NDJSON catalogue, either all of them or none.
+// @Description  The catalogue is sent as request body or as a multipart file named file.
+// @Description
+// @Description  Each row is a schema.CatalogueRow. CSV files start with the header
+// @Description  type,name,making,ingredients,yield,prepTime,cookTime,allergens,tags and separate
+// @Description  ingredient names, allergens and tags with a |. Files without the tags column are accepted.
+// @Description  Recipes can use ingredients created by previous rows.
+// @Description
+// @Description  In dry run mode, nothing is created and the response reports the invalid rows.
+// @Description
+// @Description  Require the recipe:create and ingredient:manage permissions.
+// @Param 		 format   query  string false "catalogue format, guessed from content type or file extension by default" Enums(csv, ndjson)
+// @Param 		 dryRun   query  bool false "only validate the catalogue"
+// @Tags         Catalogue
+// @Accept       plain
+// @Accept       mpfd
+// @Produce      json
+// @Success      200 {object} schema.ImportResponse "dry run report"
+// @Success      201 {object} schema.ImportResponse
+// @Failure      400 {object} schema.ImportResponse
+// @Failure      401 {object} schema.Problem
+// @Failure      403 {object} schema.Problem
+// @Failure      500 {object} schema.Problem
+// @Security JWT
+// @Security Bearer
+// @Router       /admin/import [post]
+func (c CatalogueController) Import(ctx *fiber.Ctx) error {
+	data := ctx.Body()
+	format := ctx.Query("format")
+	contentType := ctx.Get(fiber.HeaderContentType)
+
+	// the catalogue can also be uploaded as a file
+	if fileHeader, err := ctx.FormFile("file"); err == nil {
+		file, err := fileHeader.Open()
+		if err != nil {
+			return exception.New(exception.CodeInvalidFile)
+		}
+		defer file.Close()
+		if data, err = io.ReadAll(file); err != nil {
+			return exception.New(exception.CodeInvalidFile)
+		}
+		contentType = fileHeader.Header.Get(fiber.HeaderContentType)
+		if format == "" {
+			format = strings.TrimPrefix(filepath.Ext(fileHeader.Filename), ".")
+		}
+	}
+
+	if format == "" {
+		format = catalogueFormat(contentType)
+	}
+
+	response, err := c.service.Import(data, format, ctx.QueryBool("dryRun", false))
+	if err != nil {
+		return err
+	}
+
+	locale := c.GetLocale(ctx)
+	for _, rowErr := range response.Errors {
+		c.LocalizeErrors(rowErr.Errors, locale)
+	}
+
+	switch {
+	case response.DryRun:
+		return ctx.Status(OK).JSON(response)
+	case !response.Applied:
+		return ctx.Status(BadRequest).JSON(response)
+	}
+	return ctx.Status(Created).JSON(response)
+}
+
+//	Export streams the whole catalogue.
+//
+// @Summary      Export catalogue
+// @Description  Download all the ingredients and recipes in the catalogue import format.
+// @Description
+// @Description  Require the recipe:create and ingredient:manage permissions.
+// @Param 		 format   query  string false "catalogue format" Enums(csv, ndjson) default(ndjson)
+// @Tags         Catalogue
+// @Produce      plain
+// @Success      200 {array} schema.CatalogueRow
+// @Failure      400 {object} schema.Problem
+// @Failure      401 {object} schema.Problem
+// @Failure      403 {object} schema.Problem
+// @Failure      500 {object} schema.Problem
+// @Security JWT
+// @Security Bearer
+// @Router       /admin/export [get]
+func (c CatalogueController) Export(ctx *fiber.Ctx) error {
+	format := ctx.Query("format", schema.CatalogueNDJSON)
+
+	contentType := schema.MIMEApplicationNDJSON
+	switch format {
+	case schema.CatalogueCSV:
+		contentType = schema.MIMETextCSV
+	case schema.CatalogueNDJSON:
+	default:
+		formats := schema.CatalogueCSV + ", " + schema.CatalogueNDJSON
+		return exception.NewErrValidations(exception.NewErrValidation("format", exception.CodeInvalidFormat, format, formats))
+	}
+
+	ctx.Set(fiber.HeaderContentType, contentType)
+	ctx.Set(fiber.HeaderContentDisposition, `attachment; filename="catalogue.`+format+`"`)
+
+	// the status is already sent when the catalogue is written, errors can only be logged
+	ctx.Status(OK).Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := c.service.Export(w, format); err != nil {
+			log.Println("UnExpectedError: ", err.Error())
+		}
+	})
+	return nil
+}
+
+// catalogueFormat guesses a catalogue format from a content type.
+func catalogueFormat(contentType string) string {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	switch strings.TrimSpace(strings.ToLower(mediaType)) {
+	case schema.MIMETextCSV:
+		return schema.CatalogueCSV
+	case schema.MIMEApplicationNDJSON, "application/jsonl", "application/ndjson":
+		return schema.CatalogueNDJSON
+	}
+	return ""
+}
@@ -5,6 +5,7 @@ import (
 )
 
 const (
+	Accepted     = fiber.StatusAccepted
 	BadRequest   = fiber.StatusBadRequest
 	Conflict     = fiber.StatusConflict
 	Created      = fiber.StatusCreated
@@ -13,6 +14,12 @@ const (
 	Unauthorized = fiber.StatusUnauthorized
 )
 
+// default and maximum number of items returned by ranked listings
+const (
+	defaultLimit = 10
+	maxLimit     = 50
+)
+
 // Map is alias for fiber.Map.
 type Map = fiber.Map
 
@@ -25,13 +32,3 @@ type Message struct {
 func NewMessage(message string) Message {
 	return Message{Message: message}
 }
-
-// Contains ErrMessages  returned to user.
-type ErrMessage struct {
-	Error string
-}
-
-// NewErrMessage returns new ErrMessage object.
-func NewErrMessage(errMessage string) ErrMessage {
-	return ErrMessage{Error: errMessage}
-}
@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"bytes"
+
+	"github.com/denisyao1/welsh-academy-api/exception"
+	"github.com/denisyao1/welsh-academy-api/schema"
+	"github.com/denisyao1/welsh-academy-api/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+// file extensions of cookbook formats
+var cookbookExtensions = map[string]string{
+	schema.CookbookMarkdown: "md",
+	schema.CookbookHTML:     "html",
+	schema.CookbookPDF:      "pdf",
+}
+
+// CookbookController contains methods to route cookbook related requests.
+type CookbookController struct {
+	BaseController
+	service service.CookbookService
+}
+
+// NewCookbookController returns new CookbookController object.
+func NewCookbookController(service service.CookbookService) CookbookController {
+	return CookbookController{service: service}
+}
+
+//	ExportFavorites exports the connected user favorite recipes as a cookbook.
+//
+// @Summary      Export favorite recipes
+// @Description  Download the connected user favorite recipes as a printable cookbook
+// @Description  with a table of contents and one recipe per page, in the language asked with
+// @Description  the lang query param or the Accept-Language header.
+// @Param 		 format   query  string false "cookbook format" Enums(markdown, html, pdf) default(html)
+// @Param 		 lang   query  string false "content language" Enums(en, cy)
+// @Param 		 Accept-Language   header  string false "content language"
+// @Tags         User Profile
+// @Produce      html
+// @Produce      text/markdown
+// @Produce      application/pdf
+// @Success      200 {file} file
+// @Header       200 {string} Content-Language "content language"
+// @Failure      400 {object} schema.Problem
+// @Failure      401 {object} schema.Problem
+// @Failure      500 {object} schema.Problem
+// @Security JWT
+// @Security Bearer
+// @Router       /recipes/favorites/export [get]
+func (c CookbookController) ExportFavorites(ctx *fiber.Ctx) error {
+	userID, err := c.GetConnectedUserID(ctx)
+	if err != nil {
+		return exception.ErrMalFormedJWT
+	}
+
+	format := ctx.Query("format", schema.CookbookHTML)
+	var buf bytes.Buffer
+	if err = c.service.RenderFavorites(&buf, userID, format, c.GetLocale(ctx)); err != nil {
+		return err
+	}
+
+	return c.sendCookbook(ctx, buf.Bytes(), format)
+}
+
+//	CreateCookbook exports recipes as a cookbook.
+//
+// @Summary      Create cookbook
+// @Description  Download recipes as a printable cookbook with a table of contents
+// @Description  and one recipe per page, in the order of their IDs, in the language asked with
+// @Description  the lang query param or the Accept-Language header.
+// @Param request body schema.Cookbook true "Cookbook object"
+// @Param 		 format   query  string false "cookbook format" Enums(markdown, html, pdf) default(html)
+// @Param 		 lang   query  string false "content language" Enums(en, cy)
+// @Param 		 Accept-Language   header  string false "content language"
+// @Tags         Recipes
+// @Accept       json
+// @Produce      html
+// @Produce      text/markdown
+// @Produce      application/pdf
+// @Success      200 {file} file
+// @Header       200 {string} Content-Language "content language"
+// @Failure      400 {object} schema.Problem
+// @Failure      401 {object} schema.Problem
+// @Failure      500 {object} schema.Problem
+// @Security JWT
+// @Security Bearer
+// @Router       /cookbooks [post]
+func (c CookbookController) CreateCookbook(ctx *fiber.Ctx) error {
+	var cookbook schema.Cookbook
+	if err := ctx.BodyParser(&cookbook); err != nil {
+		return exception.New(exception.CodeInvalidBody)
+	}
+
+	validationErrs := schema.Validate(cookbook)
+	if validationErrs != nil {
+		return exception.NewErrValidations(validationErrs...)
+	}
+
+	format := ctx.Query("format", schema.CookbookHTML)
+	var buf bytes.Buffer
+	if err := c.service.Render(&buf, cookbook, format, c.GetLocale(ctx)); err != nil {
+		return err
+	}
+
+	return c.sendCookbook(ctx, buf.Bytes(), format)
+}
+
+func (c CookbookController) sendCookbook(ctx *fiber.Ctx, cookbook []byte, format string) error {
+	ctx.Set(fiber.HeaderContentType, schema.CookbookMIMETypes[format])
+	ctx.Set(fiber.HeaderContentDisposition, `attachment; filename="cookbook.`+cookbookExtensions[format]+`"`)
+	return ctx.Status(OK).Send(cookbook)
+}
@@ -0,0 +1,147 @@
+package controller
+
+import (
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/denisyao1/welsh-academy-api/exception"
+	"github.com/denisyao1/welsh-academy-api/schema"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+// MIMEApplicationProblemJSON is the media type of problem details.
+const MIMEApplicationProblemJSON = "application/problem+json"
+
+// problemTypePrefix prefixes the error code in the type of problem details.
+const problemTypePrefix = "urn:welsh-academy:problem:"
+
+// problemStatus is the status of the responses for each error code.
+var problemStatus = map[exception.Code]int{
+	exception.CodeInternal:                    fiber.StatusInternalServerError,
+	exception.CodeInvalidBody:                 fiber.StatusBadRequest,
+	exception.CodeInvalidQuery:                fiber.StatusBadRequest,
+	exception.CodeInvalidFile:                 fiber.StatusBadRequest,
+	exception.CodeInvalidID:                   fiber.StatusBadRequest,
+	exception.CodeInvalidRequest:              fiber.StatusBadRequest,
+	exception.CodeBadRequest:                  fiber.StatusBadRequest,
+	exception.CodePasswordSame:                fiber.StatusBadRequest,
+	exception.CodeDeleteOwnAccount:            fiber.StatusBadRequest,
+	exception.CodeDisableOwnAccount:           fiber.StatusBadRequest,
+	exception.CodeMalformedToken:              fiber.StatusUnauthorized,
+	exception.CodeInvalidToken:                fiber.StatusUnauthorized,
+	exception.CodeInvalidRefreshToken:         fiber.StatusUnauthorized,
+	exception.CodeInvalidCredentials:          fiber.StatusUnauthorized,
+	exception.CodeForbidden:                   fiber.StatusForbidden,
+	exception.CodeUserDisabled:                fiber.StatusForbidden,
+	exception.CodePasswordChangeRequired:      fiber.StatusForbidden,
+	exception.CodeMFASetupRequired:            fiber.StatusForbidden,
+	exception.CodeMFAMandatory:                fiber.StatusForbidden,
+	exception.CodeInvalidMFAChallenge:         fiber.StatusUnauthorized,
+	exception.CodeInvalidOTP:                  fiber.StatusUnauthorized,
+	exception.CodeMFAAlreadyEnabled:           fiber.StatusConflict,
+	exception.CodeMFANotEnrolled:              fiber.StatusConflict,
+	exception.CodeNotFound:                    fiber.StatusNotFound,
+	exception.CodeRouteNotFound:               fiber.StatusNotFound,
+	exception.CodeUserNotFound:                fiber.StatusNotFound,
+	exception.CodeIngredientNotFound:          fiber.StatusNotFound,
+	exception.CodeRecipeNotFound:              fiber.StatusNotFound,
+	exception.CodeSubstitutionNotFound:        fiber.StatusNotFound,
+	exception.CodeTranslationNotFound:         fiber.StatusNotFound,
+	exception.CodeTrashItemNotFound:           fiber.StatusNotFound,
+	exception.CodeSessionNotFound:             fiber.StatusNotFound,
+	exception.CodeInvitationNotFound:          fiber.StatusNotFound,
+	exception.CodeDuplicateKey:                fiber.StatusConflict,
+	exception.CodeUsernameExists:              fiber.StatusConflict,
+	exception.CodeIngredientExists:            fiber.StatusConflict,
+	exception.CodeRecipeExists:                fiber.StatusConflict,
+	exception.CodeSubstitutionExists:          fiber.StatusConflict,
+	exception.CodeIngredientTranslationExists: fiber.StatusConflict,
+	exception.CodeSimilarRecipes:              fiber.StatusConflict,
+	exception.CodeLastAdmin:                   fiber.StatusConflict,
+	exception.CodeTooManyLoginAttempts:        fiber.StatusTooManyRequests,
+}
+
+// ErrorHandler sends the errors returned by handlers as RFC 7807 problem details
+// in the user language. Unexpected errors are logged with the correlation ID
+// of the request instead of being shown to the user.
+func ErrorHandler(ctx *fiber.Ctx, err error) error {
+	var errCode *exception.Error
+	if errors.As(err, &errCode) && errCode.RetryAfter > 0 {
+		// whole seconds, rounded up not to retry too early
+		seconds := (errCode.RetryAfter + time.Second - 1) / time.Second
+		ctx.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(seconds)))
+	}
+
+	problem := NewProblem(ctx, err)
+	return SendProblem(ctx, problem.Status, problem)
+}
+
+// NewProblem returns the problem details of an error.
+func NewProblem(ctx *fiber.Ctx, err error) schema.Problem {
+	var (
+		errValidation  exception.ErrValidation
+		errValidations exception.ErrValidations
+		errCode        *exception.Error
+		errFiber       *fiber.Error
+	)
+
+	code := exception.CodeInternal
+	var args []any
+	var validationErrs []exception.ErrValidation
+
+	switch {
+	case errors.As(err, &errValidation):
+		code = exception.CodeInvalidRequest
+		validationErrs = []exception.ErrValidation{errValidation}
+	case errors.As(err, &errValidations):
+		code = exception.CodeInvalidRequest
+		validationErrs = errValidations
+	case errors.As(err, &errCode):
+		if _, ok := problemStatus[errCode.Code]; ok {
+			code, args = errCode.Code, errCode.Args
+		}
+	case errors.As(err, &errFiber):
+		switch {
+		case errFiber.Code == fiber.StatusNotFound:
+			code = exception.CodeRouteNotFound
+		case errFiber.Code < fiber.StatusInternalServerError:
+			code = exception.CodeBadRequest
+		}
+	}
+
+	status := problemStatus[code]
+	if errFiber != nil && code != exception.CodeInternal {
+		status = errFiber.Code
+	}
+
+	locale := BaseController{}.GetLocale(ctx)
+	problem := schema.Problem{
+		Type:     problemTypePrefix + string(code),
+		Title:    utils.StatusMessage(status),
+		Status:   status,
+		Detail:   exception.Message(locale, code, args...),
+		Instance: ctx.OriginalURL(),
+		Code:     code,
+	}
+	for _, errValidation := range validationErrs {
+		problem.Errors = append(problem.Errors, errValidation.Localize(locale))
+	}
+
+	if code == exception.CodeInternal {
+		problem.CorrelationID, _ = ctx.Locals("requestid").(string)
+		log.Printf("UnExpectedError [%s]: %s", problem.CorrelationID, err.Error())
+	}
+	return problem
+}
+
+// SendProblem sends a problem details body.
+func SendProblem(ctx *fiber.Ctx, status int, body any) error {
+	if err := ctx.Status(status).JSON(body); err != nil {
+		return err
+	}
+	ctx.Set(fiber.HeaderContentType, MIMEApplicationProblemJSON)
+	return nil
+}
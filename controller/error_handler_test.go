@@ -0,0 +1,79 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/denisyao1/welsh-academy-api/exception"
+	"github.com/denisyao1/welsh-academy-api/schema"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+)
+
+func TestErrorHandler(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	app.Use(requestid.New())
+	app.Get("/unexpected", func(ctx *fiber.Ctx) error {
+		return errors.New("connection refused by db:5432")
+	})
+	app.Get("/sentinel", func(ctx *fiber.Ctx) error {
+		return exception.ErrDuplicateKey
+	})
+	app.Get("/validation", func(ctx *fiber.Ctx) error {
+		return exception.NewErrValidations(
+			exception.NewErrValidation("name", exception.CodeRequired),
+			exception.NewErrValidation("password", exception.CodeTooShort, 4),
+		)
+	})
+
+	tests := []struct {
+		url    string
+		status int
+		code   exception.Code
+		errors int
+	}{
+		{"/unexpected", fiber.StatusInternalServerError, exception.CodeInternal, 0},
+		{"/sentinel", fiber.StatusConflict, exception.CodeDuplicateKey, 0},
+		{"/validation", fiber.StatusBadRequest, exception.CodeInvalidRequest, 2},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(fiber.MethodGet, tt.url, nil)
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != tt.status {
+			t.Errorf("%s: status = %d, want %d", tt.url, resp.StatusCode, tt.status)
+		}
+		if contentType := resp.Header.Get(fiber.HeaderContentType); contentType != MIMEApplicationProblemJSON {
+			t.Errorf("%s: content type = %s, want %s", tt.url, contentType, MIMEApplicationProblemJSON)
+		}
+
+		data, _ := io.ReadAll(resp.Body)
+		var problem schema.Problem
+		if err := json.Unmarshal(data, &problem); err != nil {
+			t.Fatal(err)
+		}
+		if problem.Code != tt.code || problem.Status != tt.status || problem.Instance != tt.url {
+			t.Errorf("%s: unexpected problem %+v", tt.url, problem)
+		}
+		if len(problem.Errors) != tt.errors {
+			t.Errorf("%s: %d field errors, want %d", tt.url, len(problem.Errors), tt.errors)
+		}
+
+		// unexpected errors are only identified by the correlation ID
+		if tt.code == exception.CodeInternal {
+			if problem.CorrelationID == "" || problem.CorrelationID != resp.Header.Get(fiber.HeaderXRequestID) {
+				t.Errorf("%s: correlation ID %q doesn't match the request ID", tt.url, problem.CorrelationID)
+			}
+			if strings.Contains(string(data), "db:5432") {
+				t.Errorf("%s: the error is exposed: %s", tt.url, data)
+			}
+		}
+	}
+}
@@ -9,7 +9,7 @@ import "github.com/gofiber/fiber/v2"
 // @Tags         Health
 // @Produce      json
 // @Success      200 {object} Message
-// @Failure      500
+// @Failure      500 {object} schema.Problem
 // @Router       /health [get]
 func HealthCheck(c *fiber.Ctx) error {
 	return c.JSON(NewMessage("Welsh Academy Api is running."))
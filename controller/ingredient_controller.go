@@ -2,10 +2,9 @@ package controller
 
 import (
 	"errors"
-	"fmt"
 
 	"github.com/denisyao1/welsh-academy-api/exception"
-	"github.com/denisyao1/welsh-academy-api/model"
+	"github.com/denisyao1/welsh-academy-api/schema"
 	"github.com/denisyao1/welsh-academy-api/service"
 	"github.com/gofiber/fiber/v2"
 )
@@ -13,12 +12,13 @@ import (
 // IngredientController contains methods to route ingredient related requests.
 type IngredientController struct {
 	BaseController
-	service service.IngredientService
+	service            service.IngredientService
+	translationService service.TranslationService
 }
 
 // NewIngredientController returns new IngredientController object.
-func NewIngredientController(service service.IngredientService) IngredientController {
-	return IngredientController{service: service}
+func NewIngredientController(service service.IngredientService, translationService service.TranslationService) IngredientController {
+	return IngredientController{service: service, translationService: translationService}
 }
 
 //	CreateIngredient creates new ingredient.
@@ -26,36 +26,37 @@ func NewIngredientController(service service.IngredientService) IngredientContro
 // @Summary      Create ingredient
 // @Description  Create an ingredient.
 // @Description
-// @Description  Require Admin Role.
+// @Description  Require the ingredient:manage permission.
 // @Param request body schema.Ingredient true "Ingredient object"
 // @Tags         Ingredients
 // @Produce      json
 // @Success      201 {object} model.Ingredient
-// @Failure      400 {object} exception.ErrValidation
-// @Failure      401 {object} ErrMessage
-// @Failure      409 {object} ErrMessage
-// @Failure      500
+// @Failure      400 {object} schema.Problem
+// @Failure      401 {object} schema.Problem
+// @Failure      403 {object} schema.Problem
+// @Failure      409 {object} schema.Problem
+// @Failure      500 {object} schema.Problem
 // @Security JWT
+// @Security Bearer
 // @Router       /ingredients [post]
 func (c IngredientController) CreateIngredient(ctx *fiber.Ctx) error {
-	var ingredient model.Ingredient
+	var input schema.Ingredient
 
-	if err := ctx.BodyParser(&ingredient); err != nil {
-		return ctx.Status(BadRequest).JSON(NewErrMessage("Failed to read request body."))
+	if err := ctx.BodyParser(&input); err != nil {
+		return exception.New(exception.CodeInvalidBody)
 	}
 
-	validationErr := c.service.Validate(ingredient)
-	if validationErr.Field != "" {
-		return ctx.Status(BadRequest).JSON(validationErr)
+	validationErrs := schema.Validate(input)
+	if validationErrs != nil {
+		return exception.NewErrValidations(validationErrs...)
 	}
 
-	err := c.service.Create(&ingredient)
+	ingredient, err := c.service.Create(input)
 	if err != nil {
 		if errors.Is(err, exception.ErrDuplicateKey) {
-			message := fmt.Sprintf("An ingredient named '%s' already exists.", ingredient.Name)
-			return ctx.Status(Conflict).JSON(NewErrMessage(message))
+			return exception.New(exception.CodeIngredientExists, ingredient.Name)
 		}
-		return c.HandleUnExpetedError(err, ctx)
+		return err
 	}
 
 	return ctx.Status(Created).JSON(ingredient)
@@ -64,19 +65,62 @@ func (c IngredientController) CreateIngredient(ctx *fiber.Ctx) error {
 //	ListIngredients lists all ingredients.
 //
 // @Summary      List ingredients
-// @Description  List ingredients.
+// @Description  List ingredients, named in the language asked with the lang query param
+// @Description  or the Accept-Language header.
+// @Param 		 lang   query  string false "content language" Enums(en, cy)
+// @Param 		 Accept-Language   header  string false "content language"
 // @Tags         Ingredients
 // @Produce      json
 // @Success      200 {object} schema.IngredientsResponse
-// @Failure      401 {object} ErrMessage
-// @Failure      500
+// @Header       200 {string} Content-Language "content language"
+// @Failure      401 {object} schema.Problem
+// @Failure      500 {object} schema.Problem
 // @Security JWT
+// @Security Bearer
 // @Router       /ingredients [get]
 func (c IngredientController) ListIngredients(ctx *fiber.Ctx) error {
 
 	ingredients, err := c.service.FindAll()
 	if err != nil {
-		return c.HandleUnExpetedError(err, ctx)
+		return err
+	}
+
+	if err = c.translationService.LocalizeIngredients(ingredients, c.GetLocale(ctx)); err != nil {
+		return err
 	}
 	return ctx.Status(OK).JSON(Map{"count": len(ingredients), "ingredients": ingredients})
 }
+
+//	DeleteIngredient moves an ingredient to the trash.
+//
+// @Summary      Delete ingredient
+// @Description  Move an ingredient to the trash. It can be restored until the trash is purged.
+// @Description
+// @Description  Require the ingredient:manage permission.
+// @Param 		 id   path  int true "ingredient ID"
+// @Tags         Ingredients
+// @Produce      json
+// @Success      200 {object} Message
+// @Failure      400 {object} schema.Problem
+// @Failure      401 {object} schema.Problem
+// @Failure      403 {object} schema.Problem
+// @Failure      404 {object} schema.Problem
+// @Failure      500 {object} schema.Problem
+// @Security JWT
+// @Security Bearer
+// @Router       /ingredients/{id} [delete]
+func (c IngredientController) DeleteIngredient(ctx *fiber.Ctx) error {
+	ingredientID, err := c.ConvertParamToInt("id", ctx)
+	if err != nil {
+		return exception.New(exception.CodeInvalidID)
+	}
+
+	if err = c.service.Delete(ingredientID); err != nil {
+		if errors.Is(err, exception.ErrRecordNotFound) {
+			return exception.New(exception.CodeIngredientNotFound)
+		}
+		return err
+	}
+
+	return ctx.Status(OK).JSON(NewMessage("ingredient moved to trash"))
+}
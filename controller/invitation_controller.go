@@ -0,0 +1,159 @@
+package controller
+
+import (
+	"errors"
+
+	"github.com/denisyao1/welsh-academy-api/exception"
+	"github.com/denisyao1/welsh-academy-api/schema"
+	"github.com/denisyao1/welsh-academy-api/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+// InvitationController contains methods to route invitation and registration requests.
+type InvitationController struct {
+	BaseController
+	service service.InvitationService
+}
+
+// NewInvitationController returns new InvitationController object.
+func NewInvitationController(service service.InvitationService) InvitationController {
+	return InvitationController{service: service}
+}
+
+//	CreateInvitation creates new invitation
+//
+// @Summary      Create invitation
+// @Description  Create an invitation code letting people register with POST /register, with a role
+// @Description  (reader by default), a number of uses (1 by default) and a validity in days (7 by default).
+// @Description  The code is only returned in this response.
+// @Description
+// @Description  Require the user:manage permission.
+// @Param request body schema.Invitation true "Invitation"
+// @Tags         User Management
+// @Accept       json
+// @Produce      json
+// @Success      201 {object} model.Invitation
+// @Failure      400 {object} schema.Problem
+// @Failure      401 {object} schema.Problem
+// @Failure      403 {object} schema.Problem
+// @Failure      500 {object} schema.Problem
+// @Security JWT
+// @Security Bearer
+// @Router       /invitations [post]
+func (c InvitationController) CreateInvitation(ctx *fiber.Ctx) error {
+	adminID, err := c.GetConnectedUserID(ctx)
+	if err != nil {
+		return exception.ErrMalFormedJWT
+	}
+
+	var input schema.Invitation
+	if err = ctx.BodyParser(&input); err != nil {
+		return exception.New(exception.CodeInvalidBody)
+	}
+
+	validationErrs := schema.Validate(input)
+	if validationErrs != nil {
+		return exception.NewErrValidations(validationErrs...)
+	}
+
+	invitation, err := c.service.Create(adminID, input)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(Created).JSON(invitation)
+}
+
+//	ListInvitations lists invitations
+//
+// @Summary      List invitations
+// @Description  List the invitations, most recent first, with the users who registered with them.
+// @Description
+// @Description  Require the user:manage permission.
+// @Tags         User Management
+// @Produce      json
+// @Success      200 {object} schema.InvitationsResponse
+// @Failure      401 {object} schema.Problem
+// @Failure      403 {object} schema.Problem
+// @Failure      500 {object} schema.Problem
+// @Security JWT
+// @Security Bearer
+// @Router       /invitations [get]
+func (c InvitationController) ListInvitations(ctx *fiber.Ctx) error {
+	invitations, err := c.service.List()
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(OK).JSON(schema.InvitationsResponse{Count: len(invitations), Invitations: invitations})
+}
+
+//	RevokeInvitation revokes an invitation
+//
+// @Summary      Revoke invitation
+// @Description  Revoke an invitation: its code can't be used anymore. The users who registered with it are kept.
+// @Description
+// @Description  Require the user:manage permission.
+// @Param 		 id   path  int true "invitation ID"
+// @Tags         User Management
+// @Produce      json
+// @Success      200 {object} Message
+// @Failure      400 {object} schema.Problem
+// @Failure      401 {object} schema.Problem
+// @Failure      403 {object} schema.Problem
+// @Failure      404 {object} schema.Problem
+// @Failure      500 {object} schema.Problem
+// @Security JWT
+// @Security Bearer
+// @Router       /invitations/{id} [delete]
+func (c InvitationController) RevokeInvitation(ctx *fiber.Ctx) error {
+	invitationID, err := c.ConvertParamToInt("id", ctx)
+	if err != nil {
+		return exception.New(exception.CodeInvalidID)
+	}
+
+	if err = c.service.Revoke(invitationID); err != nil {
+		if errors.Is(err, exception.ErrRecordNotFound) {
+			return exception.New(exception.CodeInvitationNotFound)
+		}
+		return err
+	}
+
+	return ctx.Status(OK).JSON(NewMessage("invitation revoked"))
+}
+
+//	Register creates an account with an invitation code
+//
+// @Summary      Register
+// @Description  Create an account with an invitation code, which gives its role. The username and
+// @Description  password are checked like when an admin creates a user. Log in with POST /login afterwards.
+// @Param request body schema.Registration true "Invitation code and credentials"
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Success      201 {object} model.User
+// @Failure      400 {object} schema.Problem
+// @Failure      409 {object} schema.Problem
+// @Failure      500 {object} schema.Problem
+// @Router       /register [post]
+func (c InvitationController) Register(ctx *fiber.Ctx) error {
+	var input schema.Registration
+	if err := ctx.BodyParser(&input); err != nil {
+		return exception.New(exception.CodeInvalidBody)
+	}
+
+	validationErrs := schema.Validate(input)
+	if validationErrs != nil {
+		return exception.NewErrValidations(validationErrs...)
+	}
+
+	user, err := c.service.Register(input)
+	if err != nil {
+		if errors.Is(err, exception.ErrDuplicateKey) {
+			return exception.New(exception.CodeUsernameExists, user.Username)
+		}
+		return err
+	}
+
+	return ctx.Status(Created).JSON(user)
+}
@@ -0,0 +1,26 @@
+package controller
+
+import (
+	"github.com/denisyao1/welsh-academy-api/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+// KeyController contains methods to route signing keys related requests.
+type KeyController struct {
+	BaseController
+	service service.KeyService
+}
+
+// NewKeyController returns new KeyController object.
+func NewKeyController(service service.KeyService) KeyController {
+	return KeyController{service: service}
+}
+
+// GetJWKS publishes the public keys verifying access tokens, so that other
+// services can verify them without sharing a secret. It is served at
+// /.well-known/jwks.json, outside the API base path of the swagger docs.
+func (c KeyController) GetJWKS(ctx *fiber.Ctx) error {
+	// keys change only on restart, clients can cache them a little
+	ctx.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return ctx.Status(OK).JSON(c.service.JWKS())
+}
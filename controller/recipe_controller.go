@@ -1,8 +1,9 @@
 package controller
 
 import (
+	"encoding/json"
 	"errors"
-	"fmt"
+	"io"
 
 	"github.com/denisyao1/welsh-academy-api/exception"
 	"github.com/denisyao1/welsh-academy-api/model"
@@ -14,12 +15,15 @@ import (
 // RecipeCOntroller contains methods to route recipes related requests.
 type RecipeController struct {
 	BaseController
-	service service.RecipeService
+	service             service.RecipeService
+	substitutionService service.SubstitutionService
+	translationService  service.TranslationService
 }
 
 // NewRecipeController returns new recipe controller.
-func NewRecipeController(service service.RecipeService) RecipeController {
-	return RecipeController{service: service}
+func NewRecipeController(service service.RecipeService, substitutionService service.SubstitutionService,
+	translationService service.TranslationService) RecipeController {
+	return RecipeController{service: service, substitutionService: substitutionService, translationService: translationService}
 }
 
 //	CreateRecipe creates new recipe.
@@ -27,40 +31,58 @@ func NewRecipeController(service service.RecipeService) RecipeController {
 // @Summary      Create recipe
 // @Description  Create recipe.
 // @Description
-// @Description  Require Admin Role.
+// @Description  Recipes with a close name and ingredients are returned as candidates in a 409 response,
+// @Description  unless force is true. A recipe name can't be used twice, even with force.
+// @Description
+// @Description  Require the recipe:create permission.
 // @Param request body schema.Recipe true "Recipe object"
+// @Param 		 force   query  bool false "create the recipe even if it is likely a duplicate"
 // @Tags         Recipes
 // @Accept       json
 // @Produce      json
 // @Success      201 {object} model.Recipe
-// @Failure      400 {array} exception.ErrValidation
-// @Failure      401 {object} ErrMessage
-// @Failure      409 {object} ErrMessage
-// @Failure      500
+// @Failure      400 {object} schema.Problem
+// @Failure      401 {object} schema.Problem
+// @Failure      403 {object} schema.Problem
+// @Failure      409 {object} schema.DuplicateResponse
+// @Failure      500 {object} schema.Problem
 // @Security JWT
+// @Security Bearer
 // @Router       /recipes [post]
 func (c RecipeController) CreateRecipe(ctx *fiber.Ctx) error {
-	var recipe model.Recipe
+	var input schema.Recipe
+
+	if err := ctx.BodyParser(&input); err != nil {
+		return exception.New(exception.CodeInvalidBody)
+	}
 
-	if err := ctx.BodyParser(&recipe); err != nil {
-		return ctx.Status(BadRequest).JSON(NewErrMessage("Failed to read request body"))
+	validationErrs := schema.Validate(input)
+	if validationErrs != nil {
+		return exception.NewErrValidations(validationErrs...)
 	}
 
-	validationErrs := c.service.Validate(&recipe)
+	recipe, validationErrs := c.service.Validate(input)
 	if validationErrs != nil {
-		if len(validationErrs) == 1 {
-			return ctx.Status(BadRequest).JSON(Map{"error": validationErrs[0]})
+		return exception.NewErrValidations(validationErrs...)
+	}
+
+	if !ctx.QueryBool("force", false) {
+		candidates, err := c.service.FindDuplicates(recipe)
+		if err != nil {
+			return err
+		}
+		if len(candidates) != 0 {
+			problem := NewProblem(ctx, exception.New(exception.CodeSimilarRecipes))
+			return SendProblem(ctx, Conflict, schema.DuplicateResponse{Problem: problem, Candidates: candidates})
 		}
-		return ctx.Status(BadRequest).JSON(Map{"errors": validationErrs})
 	}
 
 	err := c.service.Create(&recipe)
 	if err != nil {
 		if errors.Is(err, exception.ErrDuplicateKey) {
-			message := fmt.Sprintf("A recipe named '%s' already exists.", recipe.Name)
-			return ctx.Status(Conflict).JSON(NewErrMessage(message))
+			return exception.New(exception.CodeRecipeExists, recipe.Name)
 		}
-		return c.HandleUnExpetedError(err, ctx)
+		return err
 	}
 
 	return ctx.Status(Created).JSON(recipe)
@@ -70,29 +92,67 @@ func (c RecipeController) CreateRecipe(ctx *fiber.Ctx) error {
 //
 // @Summary      List all possible recipes
 // @Description  List all possible recipes.
+// @Description
+// @Description  With substitutes=true, recipes also match through the ingredients the listed
+// @Description  ones can replace, and the substitutions used are returned.
+// @Description
+// @Description  Recipes are shown in the language asked with the lang query param or the
+// @Description  Accept-Language header; ingredients can be named in any language.
 // @Param 		 ingredients   query  schema.IngredientQuery false "ingredients"
+// @Param 		 lang   query  string false "content language" Enums(en, cy)
+// @Param 		 Accept-Language   header  string false "content language"
 // @Tags         Recipes
 // @Accept       json
 // @Produce      json
 // @Success      200 {object} schema.RecipesResponse
-// @Failure      400 {object} ErrMessage
-// @Failure      401 {object} ErrMessage
-// @Failure      500
+// @Header       200 {string} Content-Language "content language"
+// @Failure      400 {object} schema.Problem
+// @Failure      401 {object} schema.Problem
+// @Failure      500 {object} schema.Problem
 // @Security JWT
+// @Security Bearer
 // @Router       /recipes [get]
 func (c RecipeController) ListRecipes(ctx *fiber.Ctx) error {
 	ingredientQuery := schema.IngredientQuery{}
 	errQuery := ctx.QueryParser(&ingredientQuery)
 	if errQuery != nil {
-		ctx.Status(BadRequest).JSON(NewErrMessage("Failed to read request query string"))
+		return exception.New(exception.CodeInvalidQuery)
+	}
+	locale := c.GetLocale(ctx)
+
+	// ingredients can be named in any language
+	ingredientNames, err := c.translationService.ResolveIngredientNames(ingredientQuery.Ingredients)
+	if err != nil {
+		return err
+	}
+
+	if !ingredientQuery.Substitutes || len(ingredientNames) == 0 {
+		recipes, err := c.service.ListAllPossible(ingredientNames)
+		if err != nil {
+			return err
+		}
+
+		if err = c.translationService.LocalizeRecipes(recipes, locale); err != nil {
+			return err
+		}
+		return ctx.Status(OK).JSON(Map{"count": len(recipes), "recipes": recipes})
+	}
+
+	// also match the ingredients the user can replace
+	ingredientNames, substitutions, err := c.substitutionService.Expand(ingredientNames)
+	if err != nil {
+		return err
 	}
-	ingredientNames := ingredientQuery.Ingredients
+
 	recipes, err := c.service.ListAllPossible(ingredientNames)
 	if err != nil {
-		return c.HandleUnExpetedError(err, ctx)
+		return err
 	}
 
-	return ctx.Status(OK).JSON(Map{"count": len(recipes), "recipes": recipes})
+	if err = c.translationService.LocalizeRecipes(recipes, locale); err != nil {
+		return err
+	}
+	return ctx.Status(OK).JSON(Map{"count": len(recipes), "recipes": recipes, "substitutions": substitutions})
 }
 
 //	FlagOrUnflag add or remove a recipe to user favorites.
@@ -104,33 +164,33 @@ func (c RecipeController) ListRecipes(ctx *fiber.Ctx) error {
 // @Accept       json
 // @Produce      json
 // @Success      200 {object} Message
-// @Failure      400 {object} ErrMessage
-// @Failure      404 {object} ErrMessage
-// @Failure      401 {object} ErrMessage
-// @Failure      500
+// @Failure      400 {object} schema.Problem
+// @Failure      404 {object} schema.Problem
+// @Failure      401 {object} schema.Problem
+// @Failure      500 {object} schema.Problem
 // @Security JWT
+// @Security Bearer
 // @Router       /recipes/{id}/flag-unflag [post]
 func (c RecipeController) FlagOrUnflag(ctx *fiber.Ctx) error {
 	// get userID
 	userID, err := c.GetConnectedUserID(ctx)
 	if err != nil {
-		return ctx.Status(BadRequest).JSON(NewErrMessage(exception.ErrMalFormedJWT.Error()))
+		return exception.ErrMalFormedJWT
 	}
 
 	// get recipe from path params ID
 	recipeID, err := c.ConvertParamToInt("id", ctx)
 	if err != nil {
-		msg := "Failed to convert recipe id."
-		return ctx.Status(BadRequest).JSON(NewErrMessage(msg))
+		return exception.New(exception.CodeInvalidID)
 	}
 
 	// add or remove recipe from user favorite
 	message, err := c.service.AddOrRemoveFavorite(userID, recipeID)
 	if err != nil {
 		if err == exception.ErrRecordNotFound {
-			return ctx.Status(NotFound).JSON(Map{"error": "recipe " + err.Error()})
+			return exception.New(exception.CodeRecipeNotFound)
 		}
-		return c.HandleUnExpetedError(err, ctx)
+		return err
 	}
 	return ctx.Status(OK).JSON(NewMessage(message))
 }
@@ -139,27 +199,279 @@ func (c RecipeController) FlagOrUnflag(ctx *fiber.Ctx) error {
 //
 // @Summary      List favorite recipes
 // @Description  list the connected user favorite recipes.
+// @Param 		 lang   query  string false "content language" Enums(en, cy)
+// @Param 		 Accept-Language   header  string false "content language"
 // @Tags         User Profile
 // @Accept       json
 // @Produce      json
 // @Success      200 {object} schema.RecipesResponse
-// @Failure      400 {object} ErrMessage
-// @Failure      401 {object} ErrMessage
-// @Failure      500
+// @Header       200 {string} Content-Language "content language"
+// @Failure      400 {object} schema.Problem
+// @Failure      401 {object} schema.Problem
+// @Failure      500 {object} schema.Problem
 // @Security JWT
+// @Security Bearer
 // @Router       /recipes/favorites [get]
 func (c RecipeController) ListUserFavorites(ctx *fiber.Ctx) error {
 	// get userID
 	userID, err := c.GetConnectedUserID(ctx)
 	if err != nil {
-		return ctx.Status(BadRequest).JSON(Map{"error": exception.ErrMalFormedJWT.Error()})
+		return exception.ErrMalFormedJWT
 	}
 
 	// return user favorites recipes from
 	recipes, err := c.service.FindUserFavorites(userID)
 	if err != nil {
-		return c.HandleUnExpetedError(err, ctx)
+		return err
+	}
+
+	if err = c.translationService.LocalizeRecipes(recipes, c.GetLocale(ctx)); err != nil {
+		return err
 	}
 
 	return ctx.Status(OK).JSON(Map{"count": len(recipes), "recipes": recipes})
 }
+
+//	DeleteRecipe moves a recipe to the trash.
+//
+// @Summary      Delete recipe
+// @Description  Move a recipe to the trash. It can be restored until the trash is purged.
+// @Description
+// @Description  Require the recipe:publish permission.
+// @Param 		 id   path  int true "recipe ID"
+// @Tags         Recipes
+// @Produce      json
+// @Success      200 {object} Message
+// @Failure      400 {object} schema.Problem
+// @Failure      401 {object} schema.Problem
+// @Failure      403 {object} schema.Problem
+// @Failure      404 {object} schema.Problem
+// @Failure      500 {object} schema.Problem
+// @Security JWT
+// @Security Bearer
+// @Router       /recipes/{id} [delete]
+func (c RecipeController) DeleteRecipe(ctx *fiber.Ctx) error {
+	recipeID, err := c.ConvertParamToInt("id", ctx)
+	if err != nil {
+		return exception.New(exception.CodeInvalidID)
+	}
+
+	if err = c.service.Delete(recipeID); err != nil {
+		if errors.Is(err, exception.ErrRecordNotFound) {
+			return exception.New(exception.CodeRecipeNotFound)
+		}
+		return err
+	}
+
+	return ctx.Status(OK).JSON(NewMessage("recipe moved to trash"))
+}
+
+//	GetRecipe returns a recipe.
+//
+// @Summary      Get recipe
+// @Description  Get a recipe.
+// @Description
+// @Description  The recipe is returned as a schema.org Recipe in JSON-LD when the format
+// @Description  query param is jsonld or the Accept header is application/ld+json
+// @Description  (see schema.RecipeJSONLD).
+// @Param 		 id   path  int true "recipe ID"
+// @Param 		 format   query  string false "response format" Enums(json, jsonld)
+// @Param 		 lang   query  string false "content language" Enums(en, cy)
+// @Param 		 Accept-Language   header  string false "content language"
+// @Tags         Recipes
+// @Produce      json
+// @Produce      application/ld+json
+// @Success      200 {object} model.Recipe
+// @Header       200 {string} Content-Language "content language"
+// @Failure      400 {object} schema.Problem
+// @Failure      401 {object} schema.Problem
+// @Failure      404 {object} schema.Problem
+// @Failure      500 {object} schema.Problem
+// @Security JWT
+// @Security Bearer
+// @Router       /recipes/{id} [get]
+func (c RecipeController) GetRecipe(ctx *fiber.Ctx) error {
+	recipeID, err := c.ConvertParamToInt("id", ctx)
+	if err != nil {
+		return exception.New(exception.CodeInvalidID)
+	}
+
+	recipe, err := c.service.GetByID(recipeID)
+	if err != nil {
+		if errors.Is(err, exception.ErrRecordNotFound) {
+			return exception.New(exception.CodeRecipeNotFound)
+		}
+		return err
+	}
+
+	locale := c.GetLocale(ctx)
+	recipes := []model.Recipe{recipe}
+	if err = c.translationService.LocalizeRecipes(recipes, locale); err != nil {
+		return err
+	}
+	recipe = recipes[0]
+
+	accepted := ctx.Accepts(fiber.MIMEApplicationJSON, schema.MIMEApplicationLDJSON)
+	if ctx.Query("format") == "jsonld" || accepted == schema.MIMEApplicationLDJSON {
+		jsonld := c.service.ToJSONLD(recipe)
+		jsonld.InLanguage = locale
+		body, err := json.Marshal(jsonld)
+		if err != nil {
+			return err
+		}
+		ctx.Set(fiber.HeaderContentType, schema.MIMEApplicationLDJSON)
+		return ctx.Status(OK).Send(body)
+	}
+
+	return ctx.Status(OK).JSON(recipe)
+}
+
+//	ListSimilarRecipes lists the recipes resembling a recipe.
+//
+// @Summary      Similar recipes
+// @Description  List the recipes sharing ingredients or tags with a recipe, most similar first.
+// @Description  Rare ingredients weigh more than common ones like salt or butter.
+// @Description  The shared and differing ingredients are listed for each recipe.
+// @Description
+// @Description  Recipes are shown in the language asked with the lang query param or the Accept-Language header.
+// @Param 		 id   path  int true "recipe ID"
+// @Param 		 limit   query  int false "maximum number of recipes" minimum(1) maximum(50) default(10)
+// @Param 		 lang   query  string false "content language" Enums(en, cy)
+// @Param 		 Accept-Language   header  string false "content language"
+// @Tags         Recipes
+// @Produce      json
+// @Success      200 {object} schema.SimilarRecipesResponse
+// @Header       200 {string} Content-Language "content language"
+// @Failure      400 {object} schema.Problem
+// @Failure      401 {object} schema.Problem
+// @Failure      404 {object} schema.Problem
+// @Failure      500 {object} schema.Problem
+// @Security JWT
+// @Security Bearer
+// @Router       /recipes/{id}/similar [get]
+func (c RecipeController) ListSimilarRecipes(ctx *fiber.Ctx) error {
+	recipeID, err := c.ConvertParamToInt("id", ctx)
+	if err != nil {
+		return exception.New(exception.CodeInvalidID)
+	}
+
+	limit, err := c.GetLimit(ctx)
+	if err != nil {
+		return exception.NewErrValidations(err)
+	}
+
+	recipes, err := c.service.FindSimilar(recipeID, limit)
+	if err != nil {
+		if errors.Is(err, exception.ErrRecordNotFound) {
+			return exception.New(exception.CodeRecipeNotFound)
+		}
+		return err
+	}
+
+	if err = c.localizeSimilarRecipes(recipes, c.GetLocale(ctx)); err != nil {
+		return err
+	}
+	return ctx.Status(OK).JSON(Map{"count": len(recipes), "recipes": recipes})
+}
+
+// localizeSimilarRecipes translates in place the similar recipes and their ingredient names in a locale.
+func (c RecipeController) localizeSimilarRecipes(similarRecipes []schema.SimilarRecipe, locale string) error {
+	recipes := make([]model.Recipe, 0, len(similarRecipes))
+	for _, similar := range similarRecipes {
+		recipes = append(recipes, similar.Recipe)
+	}
+	if err := c.translationService.LocalizeRecipes(recipes, locale); err != nil {
+		return err
+	}
+
+	for i := range similarRecipes {
+		similar := &similarRecipes[i]
+		similar.Recipe = recipes[i]
+		for _, names := range []*[]string{&similar.SharedIngredients, &similar.MissingIngredients, &similar.ExtraIngredients} {
+			localized, err := c.translationService.LocalizeIngredientNames(*names, locale)
+			if err != nil {
+				return err
+			}
+			*names = localized
+		}
+	}
+	return nil
+}
+
+//	ListDuplicates lists the groups of likely duplicate recipes.
+//
+// @Summary      List duplicate recipes
+// @Description  List the groups of recipes with a close name and ingredients.
+// @Description
+// @Description  Require the recipe:publish permission.
+// @Tags         Recipes
+// @Produce      json
+// @Success      200 {object} schema.DuplicateClustersResponse
+// @Failure      401 {object} schema.Problem
+// @Failure      403 {object} schema.Problem
+// @Failure      500 {object} schema.Problem
+// @Security JWT
+// @Security Bearer
+// @Router       /admin/recipes/duplicates [get]
+func (c RecipeController) ListDuplicates(ctx *fiber.Ctx) error {
+	clusters, err := c.service.FindDuplicateClusters()
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(OK).JSON(Map{"count": len(clusters), "clusters": clusters})
+}
+
+//	ImportRecipes creates recipes from schema.org JSON-LD.
+//
+// @Summary      Import recipes
+// @Description  Create recipes from a JSON-LD document or an HTML page embedding JSON-LD.
+// @Description  The document is sent as request body or as a multipart file named file.
+// @Description  Ingredients are matched by name and created when they don't exist.
+// @Description
+// @Description  Require the recipe:create permission.
+// @Param request body schema.RecipeJSONLD true "JSON-LD document"
+// @Tags         Recipes
+// @Accept       application/ld+json
+// @Accept       json
+// @Accept       html
+// @Accept       mpfd
+// @Produce      json
+// @Success      201 {object} schema.RecipeImportResponse
+// @Failure      400 {object} schema.RecipeImportResponse
+// @Failure      401 {object} schema.Problem
+// @Failure      403 {object} schema.Problem
+// @Failure      500 {object} schema.Problem
+// @Security JWT
+// @Security Bearer
+// @Router       /recipes/import [post]
+func (c RecipeController) ImportRecipes(ctx *fiber.Ctx) error {
+	data := ctx.Body()
+
+	// the document can also be uploaded as a file
+	if fileHeader, err := ctx.FormFile("file"); err == nil {
+		file, err := fileHeader.Open()
+		if err != nil {
+			return exception.New(exception.CodeInvalidFile)
+		}
+		defer file.Close()
+		if data, err = io.ReadAll(file); err != nil {
+			return exception.New(exception.CodeInvalidFile)
+		}
+	}
+
+	response, err := c.service.ImportJSONLD(data)
+	if err != nil {
+		return err
+	}
+
+	locale := c.GetLocale(ctx)
+	for _, recipeErr := range response.Errors {
+		c.LocalizeErrors(recipeErr.Errors, locale)
+	}
+
+	if response.Count == 0 {
+		return ctx.Status(BadRequest).JSON(response)
+	}
+	return ctx.Status(Created).JSON(response)
+}
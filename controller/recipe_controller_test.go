@@ -0,0 +1,39 @@
+package controller
+
+import (
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/denisyao1/welsh-academy-api/exception"
+	"github.com/denisyao1/welsh-academy-api/schema"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestListRecipesInvalidQuery(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	// the query is rejected before the services are used
+	app.Get("/recipes", NewRecipeController(nil, nil, nil).ListRecipes)
+
+	req := httptest.NewRequest(fiber.MethodGet, "/recipes?ingredients=milk&substitutes=maybe", nil)
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != fiber.StatusBadRequest {
+		t.Errorf("status = %d, want %d", resp.StatusCode, fiber.StatusBadRequest)
+	}
+	if contentType := resp.Header.Get(fiber.HeaderContentType); contentType != MIMEApplicationProblemJSON {
+		t.Errorf("content type = %s, want %s", contentType, MIMEApplicationProblemJSON)
+	}
+
+	data, _ := io.ReadAll(resp.Body)
+	var problem schema.Problem
+	if err := json.Unmarshal(data, &problem); err != nil {
+		t.Fatal(err)
+	}
+	if problem.Code != exception.CodeInvalidQuery {
+		t.Errorf("code = %s, want %s", problem.Code, exception.CodeInvalidQuery)
+	}
+}
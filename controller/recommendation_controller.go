@@ -0,0 +1,72 @@
+package controller
+
+import (
+	"github.com/denisyao1/welsh-academy-api/exception"
+	"github.com/denisyao1/welsh-academy-api/model"
+	"github.com/denisyao1/welsh-academy-api/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+// RecommendationController contains methods to route recommendation related requests.
+type RecommendationController struct {
+	BaseController
+	service            service.RecommendationService
+	translationService service.TranslationService
+}
+
+// NewRecommendationController returns new RecommendationController object.
+func NewRecommendationController(service service.RecommendationService,
+	translationService service.TranslationService) RecommendationController {
+	return RecommendationController{service: service, translationService: translationService}
+}
+
+//	ListRecommendations suggests recipes to the connected user.
+//
+// @Summary      Recommended recipes
+// @Description  Suggest recipes sharing ingredients with the connected user favorites,
+// @Description  or favorited by the users who like the same recipes. Favorites are left out.
+// @Description  Users without favorites get the most favorited recipes.
+// @Description
+// @Description  Recipes are shown in the language asked with the lang query param or the Accept-Language header.
+// @Param 		 limit   query  int false "maximum number of recipes" minimum(1) maximum(50) default(10)
+// @Param 		 lang   query  string false "content language" Enums(en, cy)
+// @Param 		 Accept-Language   header  string false "content language"
+// @Tags         User Profile
+// @Produce      json
+// @Success      200 {object} schema.RecommendationsResponse
+// @Header       200 {string} Content-Language "content language"
+// @Failure      400 {object} schema.Problem
+// @Failure      401 {object} schema.Problem
+// @Failure      500 {object} schema.Problem
+// @Security JWT
+// @Security Bearer
+// @Router       /recipes/recommended [get]
+func (c RecommendationController) ListRecommendations(ctx *fiber.Ctx) error {
+	userID, err := c.GetConnectedUserID(ctx)
+	if err != nil {
+		return exception.ErrMalFormedJWT
+	}
+
+	limit, err := c.GetLimit(ctx)
+	if err != nil {
+		return exception.NewErrValidations(err)
+	}
+
+	recommendations, err := c.service.Recommend(userID, limit)
+	if err != nil {
+		return err
+	}
+
+	recipes := make([]model.Recipe, 0, len(recommendations))
+	for _, recommendation := range recommendations {
+		recipes = append(recipes, recommendation.Recipe)
+	}
+	if err = c.translationService.LocalizeRecipes(recipes, c.GetLocale(ctx)); err != nil {
+		return err
+	}
+	for i := range recommendations {
+		recommendations[i].Recipe = recipes[i]
+	}
+
+	return ctx.Status(OK).JSON(Map{"count": len(recommendations), "recommendations": recommendations})
+}
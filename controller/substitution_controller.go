@@ -0,0 +1,159 @@
+package controller
+
+import (
+	"errors"
+
+	"github.com/denisyao1/welsh-academy-api/exception"
+	"github.com/denisyao1/welsh-academy-api/model"
+	"github.com/denisyao1/welsh-academy-api/schema"
+	"github.com/denisyao1/welsh-academy-api/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+// SubstitutionController contains methods to route substitution related requests.
+type SubstitutionController struct {
+	BaseController
+	service            service.SubstitutionService
+	translationService service.TranslationService
+}
+
+// NewSubstitutionController returns new SubstitutionController object.
+func NewSubstitutionController(service service.SubstitutionService,
+	translationService service.TranslationService) SubstitutionController {
+	return SubstitutionController{service: service, translationService: translationService}
+}
+
+//	CreateSubstitution creates new substitution.
+//
+// @Summary      Create substitution
+// @Description  Tell which ingredients can replace an ingredient, and in which quantity.
+// @Description  The ratio is the quantity of substitute per unit of the replaced ingredient (1 by default).
+// @Description
+// @Description  Require the ingredient:manage permission.
+// @Param request body schema.Substitution true "Substitution object"
+// @Tags         Ingredients
+// @Accept       json
+// @Produce      json
+// @Success      201 {object} model.Substitution
+// @Failure      400 {object} schema.Problem
+// @Failure      401 {object} schema.Problem
+// @Failure      403 {object} schema.Problem
+// @Failure      409 {object} schema.Problem
+// @Failure      500 {object} schema.Problem
+// @Security JWT
+// @Security Bearer
+// @Router       /substitutions [post]
+func (c SubstitutionController) CreateSubstitution(ctx *fiber.Ctx) error {
+	var input schema.Substitution
+	if err := ctx.BodyParser(&input); err != nil {
+		return exception.New(exception.CodeInvalidBody)
+	}
+
+	validationErrs := schema.Validate(input)
+	if validationErrs != nil {
+		return exception.NewErrValidations(validationErrs...)
+	}
+
+	substitution, validationErrs := c.service.Validate(input)
+	if validationErrs != nil {
+		return exception.NewErrValidations(validationErrs...)
+	}
+
+	err := c.service.Create(&substitution)
+	if err != nil {
+		if errors.Is(err, exception.ErrDuplicateKey) {
+			return exception.New(exception.CodeSubstitutionExists)
+		}
+		return err
+	}
+
+	return ctx.Status(Created).JSON(substitution)
+}
+
+//	DeleteSubstitution deletes a substitution.
+//
+// @Summary      Delete substitution
+// @Description  Delete a substitution.
+// @Description
+// @Description  Require the ingredient:manage permission.
+// @Param 		 id   path  int true "substitution ID"
+// @Tags         Ingredients
+// @Produce      json
+// @Success      200 {object} Message
+// @Failure      400 {object} schema.Problem
+// @Failure      401 {object} schema.Problem
+// @Failure      403 {object} schema.Problem
+// @Failure      404 {object} schema.Problem
+// @Failure      500 {object} schema.Problem
+// @Security JWT
+// @Security Bearer
+// @Router       /substitutions/{id} [delete]
+func (c SubstitutionController) DeleteSubstitution(ctx *fiber.Ctx) error {
+	substitutionID, err := c.ConvertParamToInt("id", ctx)
+	if err != nil {
+		return exception.New(exception.CodeInvalidID)
+	}
+
+	if err = c.service.Delete(substitutionID); err != nil {
+		if errors.Is(err, exception.ErrRecordNotFound) {
+			return exception.New(exception.CodeSubstitutionNotFound)
+		}
+		return err
+	}
+
+	return ctx.Status(OK).JSON(NewMessage("substitution deleted"))
+}
+
+//	ListSubstitutes lists what can replace an ingredient.
+//
+// @Summary      List substitutes
+// @Description  List the substitutions of an ingredient, named in the language asked with the lang
+// @Description  query param or the Accept-Language header.
+// @Param 		 id   path  int true "ingredient ID"
+// @Param 		 lang   query  string false "content language" Enums(en, cy)
+// @Param 		 Accept-Language   header  string false "content language"
+// @Tags         Ingredients
+// @Produce      json
+// @Success      200 {object} schema.SubstitutionsResponse
+// @Header       200 {string} Content-Language "content language"
+// @Failure      400 {object} schema.Problem
+// @Failure      401 {object} schema.Problem
+// @Failure      404 {object} schema.Problem
+// @Failure      500 {object} schema.Problem
+// @Security JWT
+// @Security Bearer
+// @Router       /ingredients/{id}/substitutes [get]
+func (c SubstitutionController) ListSubstitutes(ctx *fiber.Ctx) error {
+	ingredientID, err := c.ConvertParamToInt("id", ctx)
+	if err != nil {
+		return exception.New(exception.CodeInvalidID)
+	}
+
+	substitutions, err := c.service.ListForIngredient(ingredientID)
+	if err != nil {
+		if errors.Is(err, exception.ErrRecordNotFound) {
+			return exception.New(exception.CodeIngredientNotFound)
+		}
+		return err
+	}
+
+	// ingredients are localized all at once, then put back in their substitution
+	var ingredients []model.Ingredient
+	for _, substitution := range substitutions {
+		ingredients = append(ingredients, substitution.Ingredient)
+		for _, substitute := range substitution.Substitutes {
+			ingredients = append(ingredients, substitute.Ingredient)
+		}
+	}
+	if err = c.translationService.LocalizeIngredients(ingredients, c.GetLocale(ctx)); err != nil {
+		return err
+	}
+	for i := range substitutions {
+		substitutions[i].Ingredient, ingredients = ingredients[0], ingredients[1:]
+		for j := range substitutions[i].Substitutes {
+			substitutions[i].Substitutes[j].Ingredient, ingredients = ingredients[0], ingredients[1:]
+		}
+	}
+
+	return ctx.Status(OK).JSON(Map{"count": len(substitutions), "substitutions": substitutions})
+}
@@ -0,0 +1,250 @@
+package controller
+
+import (
+	"errors"
+
+	"github.com/denisyao1/welsh-academy-api/exception"
+	"github.com/denisyao1/welsh-academy-api/schema"
+	"github.com/denisyao1/welsh-academy-api/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+// TranslationController contains methods to route translation related requests.
+type TranslationController struct {
+	BaseController
+	service service.TranslationService
+}
+
+// NewTranslationController returns new TranslationController object.
+func NewTranslationController(service service.TranslationService) TranslationController {
+	return TranslationController{service: service}
+}
+
+//	ListIngredientTranslations lists the translations of an ingredient.
+//
+// @Summary      List ingredient translations
+// @Description  List the translations of an ingredient name.
+// @Description
+// @Description  Require the ingredient:manage permission.
+// @Param 		 id   path  int true "ingredient ID"
+// @Tags         Translations
+// @Produce      json
+// @Success      200 {object} schema.IngredientTranslationsResponse
+// @Failure      400 {object} schema.Problem
+// @Failure      401 {object} schema.Problem
+// @Failure      403 {object} schema.Problem
+// @Failure      404 {object} schema.Problem
+// @Failure      500 {object} schema.Problem
+// @Security JWT
+// @Security Bearer
+// @Router       /ingredients/{id}/translations [get]
+func (c TranslationController) ListIngredientTranslations(ctx *fiber.Ctx) error {
+	ingredientID, err := c.ConvertParamToInt("id", ctx)
+	if err != nil {
+		return exception.New(exception.CodeInvalidID)
+	}
+
+	translations, err := c.service.ListIngredientTranslations(ingredientID)
+	if err != nil {
+		return c.handleError(exception.CodeIngredientNotFound, err)
+	}
+
+	return ctx.Status(OK).JSON(Map{"count": len(translations), "translations": translations})
+}
+
+//	TranslateIngredient sets the name of an ingredient in a locale.
+//
+// @Summary      Translate ingredient
+// @Description  Create or replace the name of an ingredient in a locale.
+// @Description
+// @Description  Require the ingredient:manage permission.
+// @Param 		 id   path  int true "ingredient ID"
+// @Param 		 locale   path  string true "locale" Enums(cy)
+// @Param request body schema.IngredientTranslation true "Translation object"
+// @Tags         Translations
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} model.IngredientTranslation
+// @Failure      400 {object} schema.Problem
+// @Failure      401 {object} schema.Problem
+// @Failure      403 {object} schema.Problem
+// @Failure      404 {object} schema.Problem
+// @Failure      409 {object} schema.Problem
+// @Failure      500 {object} schema.Problem
+// @Security JWT
+// @Security Bearer
+// @Router       /ingredients/{id}/translations/{locale} [put]
+func (c TranslationController) TranslateIngredient(ctx *fiber.Ctx) error {
+	ingredientID, err := c.ConvertParamToInt("id", ctx)
+	if err != nil {
+		return exception.New(exception.CodeInvalidID)
+	}
+
+	var input schema.IngredientTranslation
+	if err := ctx.BodyParser(&input); err != nil {
+		return exception.New(exception.CodeInvalidBody)
+	}
+
+	validationErrs := schema.Validate(input)
+	if validationErrs != nil {
+		return exception.NewErrValidations(validationErrs...)
+	}
+
+	translation, err := c.service.TranslateIngredient(ingredientID, ctx.Params("locale"), input)
+	if err != nil {
+		if errors.Is(err, exception.ErrDuplicateKey) {
+			return exception.New(exception.CodeIngredientTranslationExists, translation.Name)
+		}
+		return c.handleError(exception.CodeIngredientNotFound, err)
+	}
+
+	return ctx.Status(OK).JSON(translation)
+}
+
+//	DeleteIngredientTranslation deletes the translation of an ingredient in a locale.
+//
+// @Summary      Delete ingredient translation
+// @Description  Delete the name of an ingredient in a locale.
+// @Description
+// @Description  Require the ingredient:manage permission.
+// @Param 		 id   path  int true "ingredient ID"
+// @Param 		 locale   path  string true "locale" Enums(cy)
+// @Tags         Translations
+// @Produce      json
+// @Success      200 {object} Message
+// @Failure      400 {object} schema.Problem
+// @Failure      401 {object} schema.Problem
+// @Failure      403 {object} schema.Problem
+// @Failure      404 {object} schema.Problem
+// @Failure      500 {object} schema.Problem
+// @Security JWT
+// @Security Bearer
+// @Router       /ingredients/{id}/translations/{locale} [delete]
+func (c TranslationController) DeleteIngredientTranslation(ctx *fiber.Ctx) error {
+	ingredientID, err := c.ConvertParamToInt("id", ctx)
+	if err != nil {
+		return exception.New(exception.CodeInvalidID)
+	}
+
+	if err = c.service.DeleteIngredientTranslation(ingredientID, ctx.Params("locale")); err != nil {
+		return c.handleError(exception.CodeTranslationNotFound, err)
+	}
+
+	return ctx.Status(OK).JSON(NewMessage("translation deleted"))
+}
+
+//	ListRecipeTranslations lists the translations of a recipe.
+//
+// @Summary      List recipe translations
+// @Description  List the translations of a recipe name and making.
+// @Description
+// @Description  Require the recipe:publish permission.
+// @Param 		 id   path  int true "recipe ID"
+// @Tags         Translations
+// @Produce      json
+// @Success      200 {object} schema.RecipeTranslationsResponse
+// @Failure      400 {object} schema.Problem
+// @Failure      401 {object} schema.Problem
+// @Failure      403 {object} schema.Problem
+// @Failure      404 {object} schema.Problem
+// @Failure      500 {object} schema.Problem
+// @Security JWT
+// @Security Bearer
+// @Router       /recipes/{id}/translations [get]
+func (c TranslationController) ListRecipeTranslations(ctx *fiber.Ctx) error {
+	recipeID, err := c.ConvertParamToInt("id", ctx)
+	if err != nil {
+		return exception.New(exception.CodeInvalidID)
+	}
+
+	translations, err := c.service.ListRecipeTranslations(recipeID)
+	if err != nil {
+		return c.handleError(exception.CodeRecipeNotFound, err)
+	}
+
+	return ctx.Status(OK).JSON(Map{"count": len(translations), "translations": translations})
+}
+
+//	TranslateRecipe sets the name and making of a recipe in a locale.
+//
+// @Summary      Translate recipe
+// @Description  Create or replace the name and making of a recipe in a locale.
+// @Description
+// @Description  Require the recipe:publish permission.
+// @Param 		 id   path  int true "recipe ID"
+// @Param 		 locale   path  string true "locale" Enums(cy)
+// @Param request body schema.RecipeTranslation true "Translation object"
+// @Tags         Translations
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} model.RecipeTranslation
+// @Failure      400 {object} schema.Problem
+// @Failure      401 {object} schema.Problem
+// @Failure      403 {object} schema.Problem
+// @Failure      404 {object} schema.Problem
+// @Failure      500 {object} schema.Problem
+// @Security JWT
+// @Security Bearer
+// @Router       /recipes/{id}/translations/{locale} [put]
+func (c TranslationController) TranslateRecipe(ctx *fiber.Ctx) error {
+	recipeID, err := c.ConvertParamToInt("id", ctx)
+	if err != nil {
+		return exception.New(exception.CodeInvalidID)
+	}
+
+	var input schema.RecipeTranslation
+	if err := ctx.BodyParser(&input); err != nil {
+		return exception.New(exception.CodeInvalidBody)
+	}
+
+	validationErrs := schema.Validate(input)
+	if validationErrs != nil {
+		return exception.NewErrValidations(validationErrs...)
+	}
+
+	translation, err := c.service.TranslateRecipe(recipeID, ctx.Params("locale"), input)
+	if err != nil {
+		return c.handleError(exception.CodeRecipeNotFound, err)
+	}
+
+	return ctx.Status(OK).JSON(translation)
+}
+
+//	DeleteRecipeTranslation deletes the translation of a recipe in a locale.
+//
+// @Summary      Delete recipe translation
+// @Description  Delete the name and making of a recipe in a locale.
+// @Description
+// @Description  Require the recipe:publish permission.
+// @Param 		 id   path  int true "recipe ID"
+// @Param 		 locale   path  string true "locale" Enums(cy)
+// @Tags         Translations
+// @Produce      json
+// @Success      200 {object} Message
+// @Failure      400 {object} schema.Problem
+// @Failure      401 {object} schema.Problem
+// @Failure      403 {object} schema.Problem
+// @Failure      404 {object} schema.Problem
+// @Failure      500 {object} schema.Problem
+// @Security JWT
+// @Security Bearer
+// @Router       /recipes/{id}/translations/{locale} [delete]
+func (c TranslationController) DeleteRecipeTranslation(ctx *fiber.Ctx) error {
+	recipeID, err := c.ConvertParamToInt("id", ctx)
+	if err != nil {
+		return exception.New(exception.CodeInvalidID)
+	}
+
+	if err = c.service.DeleteRecipeTranslation(recipeID, ctx.Params("locale")); err != nil {
+		return c.handleError(exception.CodeTranslationNotFound, err)
+	}
+
+	return ctx.Status(OK).JSON(NewMessage("translation deleted"))
+}
+
+func (c TranslationController) handleError(notFound exception.Code, err error) error {
+	if errors.Is(err, exception.ErrRecordNotFound) {
+		return exception.New(notFound)
+	}
+	return err
+}
@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"errors"
+
+	"github.com/denisyao1/welsh-academy-api/exception"
+	"github.com/denisyao1/welsh-academy-api/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+// TrashController contains methods to route trash related requests.
+type TrashController struct {
+	BaseController
+	service service.TrashService
+}
+
+// NewTrashController returns new TrashController object.
+func NewTrashController(service service.TrashService) TrashController {
+	return TrashController{service: service}
+}
+
+//	ListTrash lists deleted items.
+//
+// @Summary      List trash
+// @Description  List deleted ingredients, recipes and users.
+// @Description
+// @Description  Require the user:manage, ingredient:manage and recipe:publish permissions.
+// @Tags         Trash
+// @Produce      json
+// @Success      200 {object} schema.TrashResponse
+// @Failure      401 {object} schema.Problem
+// @Failure      403 {object} schema.Problem
+// @Failure      500 {object} schema.Problem
+// @Security JWT
+// @Security Bearer
+// @Router       /admin/trash [get]
+func (c TrashController) ListTrash(ctx *fiber.Ctx) error {
+	items, err := c.service.List()
+	if err != nil {
+		return err
+	}
+	return ctx.Status(OK).JSON(Map{"count": len(items), "items": items})
+}
+
+//	Restore takes an item out of the trash.
+//
+// @Summary      Restore item
+// @Description  Restore a deleted ingredient, recipe or user.
+// @Description
+// @Description  Require the user:manage, ingredient:manage and recipe:publish permissions.
+// @Param 		 type   path  string true "item type" Enums(ingredient, recipe, user)
+// @Param 		 id   path  int true "item ID"
+// @Tags         Trash
+// @Produce      json
+// @Success      200 {object} Message
+// @Failure      400 {object} schema.Problem
+// @Failure      401 {object} schema.Problem
+// @Failure      403 {object} schema.Problem
+// @Failure      404 {object} schema.Problem
+// @Failure      500 {object} schema.Problem
+// @Security JWT
+// @Security Bearer
+// @Router       /admin/trash/{type}/{id}/restore [post]
+func (c TrashController) Restore(ctx *fiber.Ctx) error {
+	itemType := ctx.Params("type")
+	itemID, err := c.ConvertParamToInt("id", ctx)
+	if err != nil {
+		return exception.New(exception.CodeInvalidID)
+	}
+
+	if err = c.service.Restore(itemType, itemID); err != nil {
+		if errors.Is(err, exception.ErrRecordNotFound) {
+			return exception.New(exception.CodeTrashItemNotFound)
+		}
+		return err
+	}
+
+	return ctx.Status(OK).JSON(NewMessage(itemType + " restored"))
+}
+
+//	Purge empties the trash.
+//
+// @Summary      Purge trash
+// @Description  Permanently remove items deleted for longer than the retention period.
+// @Description
+// @Description  Require the user:manage, ingredient:manage and recipe:publish permissions.
+// @Tags         Trash
+// @Produce      json
+// @Success      200 {object} schema.PurgeResponse
+// @Failure      401 {object} schema.Problem
+// @Failure      403 {object} schema.Problem
+// @Failure      500 {object} schema.Problem
+// @Security JWT
+// @Security Bearer
+// @Router       /admin/trash [delete]
+func (c TrashController) Purge(ctx *fiber.Ctx) error {
+	purged, err := c.service.Purge()
+	if err != nil {
+		return err
+	}
+	return ctx.Status(OK).JSON(purged)
+}
@@ -2,7 +2,6 @@ package controller
 
 import (
 	"errors"
-	"fmt"
 	"strconv"
 	"time"
 
@@ -12,6 +11,13 @@ import (
 	"github.com/gofiber/fiber/v2"
 )
 
+// names of the cookies holding the tokens; the refresh token is only sent to refresh them
+const (
+	authCookie        = "Auth"
+	refreshCookie     = "Refresh"
+	refreshCookiePath = "/api/v1/token"
+)
+
 // User controller contains informations and methods to route user related requests.
 type UserController struct {
 	BaseController
@@ -28,107 +34,254 @@ func NewUserController(service service.UserService) UserController {
 // @Summary      Create user
 // @Description  Create user.
 // @Description
-// @Description  Require Admin Role.
+// @Description  Require the user:manage permission.
 // @Param request body schema.User true "User object"
 // @Tags         User Management
 // @Accept       json
 // @Produce      json
 // @Success      201 {object} model.User
-// @Failure      400 {object} ErrMessage
-// @Failure      401 {object} ErrMessage
-// @Failure      500
+// @Failure      400 {object} schema.Problem
+// @Failure      401 {object} schema.Problem
+// @Failure      403 {object} schema.Problem
+// @Failure      500 {object} schema.Problem
 // @Security JWT
+// @Security Bearer
 // @Router       /users [post]
 func (c UserController) Create(ctx *fiber.Ctx) error {
 	var userSchema schema.User
 
 	if err := ctx.BodyParser(&userSchema); err != nil {
-		return ctx.Status(BadRequest).JSON(NewErrMessage("Failed to read request body"))
+		return exception.New(exception.CodeInvalidBody)
 	}
 
-	validationErrs := c.service.ValidateUserCreation(userSchema)
+	validationErrs := schema.Validate(userSchema)
 	if validationErrs != nil {
-		if len(validationErrs) == 1 {
-			return ctx.Status(BadRequest).JSON(Map{"error": validationErrs[0]})
-		}
-		return ctx.Status(BadRequest).JSON(Map{"errors": validationErrs})
+		return exception.NewErrValidations(validationErrs...)
 	}
 
 	user, err := c.service.Create(userSchema)
 
 	if err != nil {
 		if errors.Is(err, exception.ErrDuplicateKey) {
-			message := fmt.Sprintf("username '%s' already exists.", user.Username)
-			return ctx.Status(Conflict).JSON(Map{"error": message})
+			return exception.New(exception.CodeUsernameExists, user.Username)
 		}
-		return c.HandleUnExpetedError(err, ctx)
+		return err
 	}
 
 	return ctx.Status(fiber.StatusCreated).JSON(user)
 }
 
-//	Login creates new cookie access token
+//	Login creates new access token
 //
 // @Summary      Login
-// @Description  Get new cookie access token
+// @Description  Get new access token, valid for a few minutes, and a refresh token to renew it
+// @Description  with POST /token/refresh. They are set in the Auth and Refresh cookies by default.
+// @Description
+// @Description  With mode=token, the tokens are returned in the response body instead, the access
+// @Description  token to be sent in the Authorization header with the Bearer scheme.
+// @Description
+// @Description  Each failed login makes the next ones of the username and of the IP address wait longer,
+// @Description  until they are locked out for a while. They get a 429 response telling when to retry.
+// @Description
+// @Description  For users with two-factor authentication, a 202 response gives a challenge instead,
+// @Description  to complete with a code of their authenticator app at POST /login/mfa.
 // @Param request body schema.Login true "Credentials"
+// @Param 		 mode   query  string false "where to return the tokens" Enums(cookie, token) default(cookie)
 // @Tags         Auth
 // @Accept       json
 // @Produce      json
-// @Success      200 {object} Message
-// @Failure      401 {object} ErrMessage
-// @Failure      500
+// @Success      200 {object} schema.Token "the tokens with mode=token, else a message"
+// @Success      202 {object} schema.MFAChallenge
+// @Failure      400 {object} schema.Problem
+// @Failure      401 {object} schema.Problem
+// @Failure      403 {object} schema.Problem
+// @Failure      429 {object} schema.Problem
+// @Header       429 {integer} Retry-After "seconds to wait before retrying"
+// @Failure      500 {object} schema.Problem
 // @Router       /login [post]
 func (c UserController) Login(ctx *fiber.Ctx) error {
+	mode, err := c.getLoginMode(ctx)
+	if err != nil {
+		return err
+	}
+
 	// get request body
 	var loginSchema schema.Login
 	if err := ctx.BodyParser(&loginSchema); err != nil {
-		return ctx.Status(BadRequest).JSON(NewErrMessage("Failed to read request body."))
+		return exception.New(exception.CodeInvalidBody)
 	}
 
-	// create token
-	token, err := c.service.CreateAccessToken(loginSchema)
+	validationErrs := schema.Validate(loginSchema)
+	if validationErrs != nil {
+		return exception.NewErrValidations(validationErrs...)
+	}
+
+	// create tokens
+	token, challenge, err := c.service.CreateTokens(loginSchema, ctx.Get(fiber.HeaderUserAgent), ctx.IP())
 	if err != nil {
-		//if err is ErrInvalidCredentials
-		if errors.Is(err, exception.ErrInvalidCredentials) {
-			return ctx.Status(Unauthorized).JSON(NewErrMessage("invalid credentials"))
+		return err
+	}
+	if challenge != nil {
+		return ctx.Status(Accepted).JSON(challenge)
+	}
+
+	return c.sendToken(ctx, mode, token, "login successful")
+}
+
+//	LoginMFA completes a login with a code of the authenticator app
+//
+// @Summary      Login with two-factor authentication
+// @Description  Complete the challenge of POST /login with a code of the authenticator app,
+// @Description  or else with one of the recovery codes, each working once. The challenge expires
+// @Description  after a few minutes. Tokens are returned like with POST /login.
+// @Description
+// @Description  Wrong codes count as failed logins.
+// @Param request body schema.MFALogin true "Challenge and code"
+// @Param 		 mode   query  string false "where to return the tokens" Enums(cookie, token) default(cookie)
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} schema.Token "the tokens with mode=token, else a message"
+// @Failure      400 {object} schema.Problem
+// @Failure      401 {object} schema.Problem
+// @Failure      429 {object} schema.Problem
+// @Header       429 {integer} Retry-After "seconds to wait before retrying"
+// @Failure      500 {object} schema.Problem
+// @Router       /login/mfa [post]
+func (c UserController) LoginMFA(ctx *fiber.Ctx) error {
+	mode, err := c.getLoginMode(ctx)
+	if err != nil {
+		return err
+	}
+
+	var input schema.MFALogin
+	if err := ctx.BodyParser(&input); err != nil {
+		return exception.New(exception.CodeInvalidBody)
+	}
+
+	validationErrs := schema.Validate(input)
+	if validationErrs != nil {
+		return exception.NewErrValidations(validationErrs...)
+	}
+
+	token, err := c.service.CompleteMFALogin(input, ctx.Get(fiber.HeaderUserAgent), ctx.IP())
+	if err != nil {
+		return err
+	}
+
+	return c.sendToken(ctx, mode, token, "login successful")
+}
+
+//	RefreshToken replaces a refresh token with new tokens
+//
+// @Summary      Refresh tokens
+// @Description  Get new access and refresh tokens from a refresh token, given in the request body
+// @Description  or else in the Refresh cookie. A refresh token can only be used once: using it again
+// @Description  revokes all the tokens issued since the login.
+// @Description
+// @Description  Tokens are returned like with POST /login.
+// @Param request body schema.RefreshToken false "Refresh token"
+// @Param 		 mode   query  string false "where to return the tokens" Enums(cookie, token) default(cookie)
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} schema.Token "the tokens with mode=token, else a message"
+// @Failure      400 {object} schema.Problem
+// @Failure      401 {object} schema.Problem
+// @Failure      500 {object} schema.Problem
+// @Router       /token/refresh [post]
+func (c UserController) RefreshToken(ctx *fiber.Ctx) error {
+	mode, err := c.getLoginMode(ctx)
+	if err != nil {
+		return err
+	}
+
+	var input schema.RefreshToken
+	if len(ctx.Body()) != 0 {
+		if err := ctx.BodyParser(&input); err != nil {
+			return exception.New(exception.CodeInvalidBody)
 		}
-		return c.HandleUnExpetedError(err, ctx)
+	}
+	if input.RefreshToken == "" {
+		input.RefreshToken = ctx.Cookies(refreshCookie)
+	}
+	if input.RefreshToken == "" {
+		return exception.NewErrValidations(exception.NewErrValidation("refreshToken", exception.CodeRequired))
 	}
 
-	// create cookie
-	jwt_cookie := fiber.Cookie{
-		Name:     "Auth",
-		Value:    token,
-		Expires:  time.Now().Add(24 * time.Hour),
-		HTTPOnly: true,
-		SameSite: "lax",
+	token, err := c.service.RefreshTokens(input.RefreshToken, ctx.IP())
+	if err != nil {
+		return err
+	}
+
+	return c.sendToken(ctx, mode, token, "token refresh successful")
+}
+
+// getLoginMode returns the mode query param telling where to return tokens.
+func (c UserController) getLoginMode(ctx *fiber.Ctx) (string, error) {
+	mode := ctx.Query("mode", schema.LoginCookie)
+	if mode != schema.LoginCookie && mode != schema.LoginToken {
+		modes := schema.LoginCookie + ", " + schema.LoginToken
+		return "", exception.NewErrValidations(exception.NewErrValidation("mode", exception.CodeNotOneOf, modes))
+	}
+	return mode, nil
+}
+
+// sendToken returns the tokens in the response body or in cookies, according to the mode.
+func (c UserController) sendToken(ctx *fiber.Ctx, mode string, token schema.Token, message string) error {
+	if mode == schema.LoginToken {
+		return ctx.Status(OK).JSON(token)
 	}
 
-	// set cookie
-	ctx.Cookie(&jwt_cookie)
-	return ctx.Status(OK).JSON(Map{"message": "login successful"})
+	// set cookies
+	ctx.Cookie(&fiber.Cookie{
+		Name:     authCookie,
+		Value:    token.AccessToken,
+		Expires:  token.ExpiresAt,
+		HTTPOnly: true,
+		SameSite: "lax",
+	})
+	ctx.Cookie(&fiber.Cookie{
+		Name:     refreshCookie,
+		Value:    token.RefreshToken,
+		Path:     refreshCookiePath,
+		Expires:  token.RefreshExpiresAt,
+		HTTPOnly: true,
+		SameSite: "lax",
+	})
+	return ctx.Status(OK).JSON(Map{"message": message})
 }
 
 //	Logout Logs out the connected user
 //
 // @Summary      Logout
-// @Description  Logout
+// @Description  Logout, revoking the session of the access token if there is a valid one.
 // @Tags         Auth
 // @Produce      json
 // @Success      200 {object} Message
+// @Failure      500 {object} schema.Problem
 // @Router       /logout [get]
 func (c UserController) Logout(ctx *fiber.Ctx) error {
-	// create an expired cookie
-	cookie := fiber.Cookie{
-		Name:     "Auth",
+	if err := c.service.Logout(c.GetSessionID(ctx)); err != nil {
+		return err
+	}
+
+	// create expired cookies
+	ctx.Cookie(&fiber.Cookie{
+		Name:     authCookie,
 		Value:    "deleted",
 		Expires:  time.Now().Add(-5 * time.Second),
 		HTTPOnly: true,
 		SameSite: "lax",
-	}
-
-	ctx.Cookie(&cookie)
+	})
+	ctx.Cookie(&fiber.Cookie{
+		Name:     refreshCookie,
+		Value:    "deleted",
+		Path:     refreshCookiePath,
+		Expires:  time.Now().Add(-5 * time.Second),
+		HTTPOnly: true,
+		SameSite: "lax",
+	})
 
 	return ctx.Status(OK).JSON(Map{"message": "logout successful"})
 }
@@ -141,24 +294,25 @@ func (c UserController) Logout(ctx *fiber.Ctx) error {
 // @Accept       json
 // @Produce      json
 // @Success      200 {object} model.User
-// @Failure      400 {object} ErrMessage
-// @Failure      401 {object} ErrMessage
-// @Failure      404 {object} ErrMessage
-// @Failure      409 {object} ErrMessage
-// @Failure      500
+// @Failure      400 {object} schema.Problem
+// @Failure      401 {object} schema.Problem
+// @Failure      404 {object} schema.Problem
+// @Failure      409 {object} schema.Problem
+// @Failure      500 {object} schema.Problem
 // @Security JWT
+// @Security Bearer
 // @Router       /users/my-infos [get]
 func (c UserController) GetInfos(ctx *fiber.Ctx) error {
 	userID, err := strconv.Atoi(ctx.Locals("userID").(string))
 	if err != nil {
-		ctx.Status(Unauthorized).JSON(Map{"message": "Missing or malformed token"})
+		return exception.ErrMalFormedJWT
 	}
 	user, err := c.service.GetInfos(userID)
 	if err != nil {
 		if errors.Is(err, exception.ErrRecordNotFound) {
-			return ctx.Status(NotFound).JSON(Map{"error": "user " + err.Error()})
+			return exception.New(exception.CodeUserNotFound)
 		}
-		c.HandleUnExpetedError(err, ctx)
+		return err
 	}
 
 	return ctx.Status(fiber.StatusOK).JSON(user)
@@ -167,44 +321,686 @@ func (c UserController) GetInfos(ctx *fiber.Ctx) error {
 //	UpdatePassword updates connected user's password
 //
 // @Summary      Update password
-// @Description  Update connected user's password
+// @Description  Update connected user's password, given its current password. The other sessions
+// @Description  of the user are revoked, and new tokens are issued for the current session: in the
+// @Description  Auth and Refresh cookies by default, or in the response body with mode=token.
+// @Description
+// @Description  Users who must change their password can only do this, the new tokens lifting the restriction.
 // @Param request body schema.Password true "Password"
+// @Param 		 mode   query  string false "where to return the tokens" Enums(cookie, token) default(cookie)
 // @Tags         User Profile
 // @Accept       json
 // @Produce      json
-// @Success      200 {object} Message
-// @Failure      400 {object} ErrMessage
-// @Failure      401 {object} ErrMessage
-// @Failure      404 {object} ErrMessage
-// @Failure      500
+// @Success      200 {object} schema.Token "the tokens with mode=token, else a message"
+// @Failure      400 {object} schema.Problem
+// @Failure      401 {object} schema.Problem
+// @Failure      404 {object} schema.Problem
+// @Failure      500 {object} schema.Problem
 // @Security JWT
+// @Security Bearer
 // @Router       /users/password-change [patch]
 func (c UserController) UpdatePassword(ctx *fiber.Ctx) error {
+	mode, err := c.getLoginMode(ctx)
+	if err != nil {
+		return err
+	}
+
 	//get user id
 	userID, err := c.GetConnectedUserID(ctx)
 	if err != nil {
-		return ctx.Status(Unauthorized).
-			JSON(NewErrMessage("Missing or malformed token"))
+		return exception.ErrMalFormedJWT
 	}
 
 	// get password input
 	var pwdSchema schema.Password
 	if err = ctx.BodyParser(&pwdSchema); err != nil {
-		return ctx.Status(BadRequest).JSON(NewErrMessage("can't read request body"))
+		return exception.New(exception.CodeInvalidBody)
+	}
+
+	validationErrs := schema.Validate(pwdSchema)
+	if validationErrs != nil {
+		return exception.NewErrValidations(validationErrs...)
 	}
 
 	//update password
-	if err = c.service.UpdatePaswword(userID, pwdSchema); err != nil {
-		if errors.Is(err, exception.ErrInvalidPassword) ||
-			errors.Is(err, exception.ErrPasswordSame) {
-			return ctx.Status(BadRequest).JSON(NewErrMessage(err.Error()))
+	token, err := c.service.UpdatePaswword(userID, c.GetSessionID(ctx), ctx.IP(), pwdSchema)
+	if err != nil {
+		if errors.Is(err, exception.ErrRecordNotFound) {
+			return exception.New(exception.CodeUserNotFound)
+		}
+		return err
+	}
+
+	return c.sendToken(ctx, mode, token, "password update successful")
+}
+
+//	Delete moves a user to the trash
+//
+// @Summary      Delete user
+// @Description  Move a user to the trash, remove its favorites and revoke its sessions.
+// @Description  It can be restored until the trash is purged.
+// @Description
+// @Description  Require the user:manage permission.
+// @Param 		 id   path  int true "user ID"
+// @Tags         User Management
+// @Produce      json
+// @Success      200 {object} Message
+// @Failure      400 {object} schema.Problem
+// @Failure      401 {object} schema.Problem
+// @Failure      403 {object} schema.Problem
+// @Failure      404 {object} schema.Problem
+// @Failure      409 {object} schema.Problem
+// @Failure      500 {object} schema.Problem
+// @Security JWT
+// @Security Bearer
+// @Router       /users/{id} [delete]
+func (c UserController) Delete(ctx *fiber.Ctx) error {
+	connectedUserID, err := c.GetConnectedUserID(ctx)
+	if err != nil {
+		return exception.ErrMalFormedJWT
+	}
+
+	userID, err := c.ConvertParamToInt("id", ctx)
+	if err != nil {
+		return exception.New(exception.CodeInvalidID)
+	}
+
+	// an admin can't lock himself out
+	if userID == connectedUserID {
+		return exception.New(exception.CodeDeleteOwnAccount)
+	}
+
+	if err = c.service.Delete(userID); err != nil {
+		if errors.Is(err, exception.ErrRecordNotFound) {
+			return exception.New(exception.CodeUserNotFound)
+		}
+		return err
+	}
+
+	return ctx.Status(OK).JSON(NewMessage("user moved to trash"))
+}
+
+//	ListSessions lists the sessions of the connected user
+//
+// @Summary      My sessions
+// @Description  List the active sessions of the connected user, with the device and IP address
+// @Description  they were last used from, most recently used first.
+// @Tags         User Profile
+// @Produce      json
+// @Success      200 {object} schema.SessionsResponse
+// @Failure      401 {object} schema.Problem
+// @Failure      500 {object} schema.Problem
+// @Security JWT
+// @Security Bearer
+// @Router       /users/me/sessions [get]
+func (c UserController) ListSessions(ctx *fiber.Ctx) error {
+	userID, err := c.GetConnectedUserID(ctx)
+	if err != nil {
+		return exception.ErrMalFormedJWT
+	}
+
+	sessions, err := c.service.ListSessions(userID, c.GetSessionID(ctx))
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(OK).JSON(schema.SessionsResponse{Count: len(sessions), Sessions: sessions})
+}
+
+//	RevokeSession revokes a session of the connected user
+//
+// @Summary      Revoke session
+// @Description  Revoke a session of the connected user: its tokens are rejected from now on.
+// @Param 		 id   path  string true "session ID"
+// @Tags         User Profile
+// @Produce      json
+// @Success      200 {object} Message
+// @Failure      401 {object} schema.Problem
+// @Failure      404 {object} schema.Problem
+// @Failure      500 {object} schema.Problem
+// @Security JWT
+// @Security Bearer
+// @Router       /users/me/sessions/{id} [delete]
+func (c UserController) RevokeSession(ctx *fiber.Ctx) error {
+	userID, err := c.GetConnectedUserID(ctx)
+	if err != nil {
+		return exception.ErrMalFormedJWT
+	}
+
+	if err = c.service.RevokeSession(userID, ctx.Params("id")); err != nil {
+		if errors.Is(err, exception.ErrRecordNotFound) {
+			return exception.New(exception.CodeSessionNotFound)
+		}
+		return err
+	}
+
+	return ctx.Status(OK).JSON(NewMessage("session revoked"))
+}
+
+//	EnrolTOTP generates a TOTP secret for the connected user
+//
+// @Summary      Enrol in two-factor authentication
+// @Description  Generate a TOTP secret (RFC 6238) for the connected user, to add to an authenticator app
+// @Description  with the otpauth URI or its QR code. Two-factor authentication is enabled once a code
+// @Description  of the app is verified with POST /users/me/mfa/totp/verify. Enrolling again replaces the secret.
+// @Description  The current password of the user is required.
+// @Param request body schema.PasswordConfirmation true "Current password"
+// @Tags         User Profile
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} schema.TOTPEnrolment
+// @Failure      400 {object} schema.Problem
+// @Failure      401 {object} schema.Problem
+// @Failure      404 {object} schema.Problem
+// @Failure      409 {object} schema.Problem
+// @Failure      500 {object} schema.Problem
+// @Security JWT
+// @Security Bearer
+// @Router       /users/me/mfa/totp [post]
+func (c UserController) EnrolTOTP(ctx *fiber.Ctx) error {
+	userID, err := c.GetConnectedUserID(ctx)
+	if err != nil {
+		return exception.ErrMalFormedJWT
+	}
+
+	var input schema.PasswordConfirmation
+	if err = ctx.BodyParser(&input); err != nil {
+		return exception.New(exception.CodeInvalidBody)
+	}
+
+	validationErrs := schema.Validate(input)
+	if validationErrs != nil {
+		return exception.NewErrValidations(validationErrs...)
+	}
+
+	enrolment, err := c.service.EnrolTOTP(userID, input)
+	if err != nil {
+		if errors.Is(err, exception.ErrRecordNotFound) {
+			return exception.New(exception.CodeUserNotFound)
+		}
+		return err
+	}
+
+	return ctx.Status(OK).JSON(enrolment)
+}
+
+//	EnableTOTP enables two-factor authentication of the connected user
+//
+// @Summary      Enable two-factor authentication
+// @Description  Enable two-factor authentication of the connected user, given a code of the secret
+// @Description  enrolled with POST /users/me/mfa/totp. The response gives recovery codes, shown only once,
+// @Description  each letting the user log in once without the authenticator app.
+// @Description
+// @Description  Admins who must use two-factor authentication can only do this, refreshing their tokens
+// @Description  lifts the restriction.
+// @Param request body schema.TOTPCode true "Code of the authenticator app"
+// @Tags         User Profile
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} schema.RecoveryCodes
+// @Failure      400 {object} schema.Problem
+// @Failure      401 {object} schema.Problem
+// @Failure      404 {object} schema.Problem
+// @Failure      409 {object} schema.Problem
+// @Failure      500 {object} schema.Problem
+// @Security JWT
+// @Security Bearer
+// @Router       /users/me/mfa/totp/verify [post]
+func (c UserController) EnableTOTP(ctx *fiber.Ctx) error {
+	userID, err := c.GetConnectedUserID(ctx)
+	if err != nil {
+		return exception.ErrMalFormedJWT
+	}
+
+	var input schema.TOTPCode
+	if err = ctx.BodyParser(&input); err != nil {
+		return exception.New(exception.CodeInvalidBody)
+	}
+
+	validationErrs := schema.Validate(input)
+	if validationErrs != nil {
+		return exception.NewErrValidations(validationErrs...)
+	}
+
+	codes, err := c.service.EnableTOTP(userID, input)
+	if err != nil {
+		if errors.Is(err, exception.ErrRecordNotFound) {
+			return exception.New(exception.CodeUserNotFound)
+		}
+		return err
+	}
+
+	return ctx.Status(OK).JSON(codes)
+}
+
+//	DisableTOTP disables two-factor authentication of the connected user
+//
+// @Summary      Disable two-factor authentication
+// @Description  Disable two-factor authentication of the connected user, given a code of the authenticator
+// @Description  app or a recovery code. The recovery codes are removed.
+// @Description
+// @Description  Admins can't disable it when it is mandatory for them.
+// @Param request body schema.TOTPCode true "Code of the authenticator app, or recovery code"
+// @Tags         User Profile
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} Message
+// @Failure      400 {object} schema.Problem
+// @Failure      401 {object} schema.Problem
+// @Failure      403 {object} schema.Problem
+// @Failure      404 {object} schema.Problem
+// @Failure      409 {object} schema.Problem
+// @Failure      500 {object} schema.Problem
+// @Security JWT
+// @Security Bearer
+// @Router       /users/me/mfa/totp [delete]
+func (c UserController) DisableTOTP(ctx *fiber.Ctx) error {
+	userID, err := c.GetConnectedUserID(ctx)
+	if err != nil {
+		return exception.ErrMalFormedJWT
+	}
+
+	var input schema.TOTPCode
+	if err = ctx.BodyParser(&input); err != nil {
+		return exception.New(exception.CodeInvalidBody)
+	}
+
+	validationErrs := schema.Validate(input)
+	if validationErrs != nil {
+		return exception.NewErrValidations(validationErrs...)
+	}
+
+	if err = c.service.DisableTOTP(userID, input); err != nil {
+		if errors.Is(err, exception.ErrRecordNotFound) {
+			return exception.New(exception.CodeUserNotFound)
 		}
+		return err
+	}
+
+	return ctx.Status(OK).JSON(NewMessage("two-factor authentication disabled"))
+}
+
+//	RevokeUserSessions revokes all the sessions of a user
+//
+// @Summary      Revoke user sessions
+// @Description  Revoke all the sessions of a user: the user has to log in again.
+// @Description
+// @Description  Require the user:manage permission.
+// @Param 		 id   path  int true "user ID"
+// @Tags         User Management
+// @Produce      json
+// @Success      200 {object} Message
+// @Failure      400 {object} schema.Problem
+// @Failure      401 {object} schema.Problem
+// @Failure      403 {object} schema.Problem
+// @Failure      404 {object} schema.Problem
+// @Failure      500 {object} schema.Problem
+// @Security JWT
+// @Security Bearer
+// @Router       /users/{id}/sessions [delete]
+func (c UserController) RevokeUserSessions(ctx *fiber.Ctx) error {
+	userID, err := c.ConvertParamToInt("id", ctx)
+	if err != nil {
+		return exception.New(exception.CodeInvalidID)
+	}
+
+	if _, err = c.service.GetInfos(userID); err != nil {
+		if errors.Is(err, exception.ErrRecordNotFound) {
+			return exception.New(exception.CodeUserNotFound)
+		}
+		return err
+	}
+
+	if err = c.service.RevokeAllSessions(userID, ""); err != nil {
+		return err
+	}
+
+	return ctx.Status(OK).JSON(NewMessage("sessions revoked"))
+}
+
+//	ListRoles lists the roles which can be assigned to users
+//
+// @Summary      List roles
+// @Description  List the roles which can be assigned to users, with their permissions.
+// @Description
+// @Description  Require the user:manage permission.
+// @Tags         User Management
+// @Produce      json
+// @Success      200 {object} schema.RolesResponse
+// @Failure      401 {object} schema.Problem
+// @Failure      403 {object} schema.Problem
+// @Failure      500 {object} schema.Problem
+// @Security JWT
+// @Security Bearer
+// @Router       /roles [get]
+func (c UserController) ListRoles(ctx *fiber.Ctx) error {
+	roles := c.service.ListRoles()
+	return ctx.Status(OK).JSON(schema.RolesResponse{Count: len(roles), Roles: roles})
+}
+
+//	SetRoles assigns roles to a user
+//
+// @Summary      Assign user roles
+// @Description  Replace the roles of a user. The tokens issued before keep the former roles
+// @Description  until they expire, unless the sessions of the user are revoked.
+// @Description  The last admin can't lose the admin role.
+// @Description
+// @Description  Require the user:manage permission.
+// @Param 		 id   path  int true "user ID"
+// @Param request body schema.UserRoles true "roles of the user"
+// @Tags         User Management
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} model.User
+// @Failure      400 {object} schema.Problem
+// @Failure      401 {object} schema.Problem
+// @Failure      403 {object} schema.Problem
+// @Failure      404 {object} schema.Problem
+// @Failure      409 {object} schema.Problem
+// @Failure      500 {object} schema.Problem
+// @Security JWT
+// @Security Bearer
+// @Router       /users/{id}/roles [put]
+func (c UserController) SetRoles(ctx *fiber.Ctx) error {
+	userID, err := c.ConvertParamToInt("id", ctx)
+	if err != nil {
+		return exception.New(exception.CodeInvalidID)
+	}
+
+	var input schema.UserRoles
+	if err = ctx.BodyParser(&input); err != nil {
+		return exception.New(exception.CodeInvalidBody)
+	}
+
+	validationErrs := schema.Validate(input)
+	if validationErrs != nil {
+		return exception.NewErrValidations(validationErrs...)
+	}
+
+	user, err := c.service.SetRoles(userID, input)
+	if err != nil {
+		if errors.Is(err, exception.ErrRecordNotFound) {
+			return exception.New(exception.CodeUserNotFound)
+		}
+		return err
+	}
+
+	return ctx.Status(OK).JSON(user)
+}
+
+//	ListUsers lists the users
+//
+// @Summary      List users
+// @Description  List the users by username, a page at a time. Users in the trash are left out.
+// @Description
+// @Description  Require the user:manage permission.
+// @Param 		 search  query  string false "part of the username, ignoring case and spaces"
+// @Param 		 page    query  int    false "page number" minimum(1) default(1)
+// @Param 		 limit   query  int    false "users per page" minimum(1) maximum(50) default(10)
+// @Tags         User Management
+// @Produce      json
+// @Success      200 {object} schema.UsersResponse
+// @Failure      400 {object} schema.Problem
+// @Failure      401 {object} schema.Problem
+// @Failure      403 {object} schema.Problem
+// @Failure      500 {object} schema.Problem
+// @Security JWT
+// @Security Bearer
+// @Router       /users [get]
+func (c UserController) ListUsers(ctx *fiber.Ctx) error {
+	query := schema.UserQuery{Page: 1, Limit: defaultLimit}
+	if err := ctx.QueryParser(&query); err != nil {
+		return exception.New(exception.CodeInvalidQuery)
+	}
+
+	validationErrs := schema.Validate(query)
+	if validationErrs != nil {
+		return exception.NewErrValidations(validationErrs...)
+	}
+
+	users, total, err := c.service.List(query.Search, query.Page, query.Limit)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(OK).JSON(schema.UsersResponse{
+		Count: len(users),
+		Total: total,
+		Page:  query.Page,
+		Limit: query.Limit,
+		Users: users,
+	})
+}
+
+//	GetUser returns a user
+//
+// @Summary      Get user
+// @Description  Show a user with its roles.
+// @Description
+// @Description  Require the user:manage permission.
+// @Param 		 id   path  int true "user ID"
+// @Tags         User Management
+// @Produce      json
+// @Success      200 {object} model.User
+// @Failure      400 {object} schema.Problem
+// @Failure      401 {object} schema.Problem
+// @Failure      403 {object} schema.Problem
+// @Failure      404 {object} schema.Problem
+// @Failure      500 {object} schema.Problem
+// @Security JWT
+// @Security Bearer
+// @Router       /users/{id} [get]
+func (c UserController) GetUser(ctx *fiber.Ctx) error {
+	userID, err := c.ConvertParamToInt("id", ctx)
+	if err != nil {
+		return exception.New(exception.CodeInvalidID)
+	}
+
+	user, err := c.service.GetInfos(userID)
+	if err != nil {
+		if errors.Is(err, exception.ErrRecordNotFound) {
+			return exception.New(exception.CodeUserNotFound)
+		}
+		return err
+	}
+
+	return ctx.Status(OK).JSON(user)
+}
+
+//	UpdateUser updates a user
+//
+// @Summary      Update user
+// @Description  Change the roles of a user, like PUT /users/{id}/roles. Missing fields are left unchanged.
+// @Description
+// @Description  Require the user:manage permission.
+// @Param 		 id   path  int true "user ID"
+// @Param request body schema.UserUpdate true "changed fields"
+// @Tags         User Management
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} model.User
+// @Failure      400 {object} schema.Problem
+// @Failure      401 {object} schema.Problem
+// @Failure      403 {object} schema.Problem
+// @Failure      404 {object} schema.Problem
+// @Failure      409 {object} schema.Problem
+// @Failure      500 {object} schema.Problem
+// @Security JWT
+// @Security Bearer
+// @Router       /users/{id} [patch]
+func (c UserController) UpdateUser(ctx *fiber.Ctx) error {
+	userID, err := c.ConvertParamToInt("id", ctx)
+	if err != nil {
+		return exception.New(exception.CodeInvalidID)
+	}
+
+	var input schema.UserUpdate
+	if err = ctx.BodyParser(&input); err != nil {
+		return exception.New(exception.CodeInvalidBody)
+	}
+
+	validationErrs := schema.Validate(input)
+	if validationErrs != nil {
+		return exception.NewErrValidations(validationErrs...)
+	}
+
+	user, err := c.service.Update(userID, input)
+	if err != nil {
+		if errors.Is(err, exception.ErrRecordNotFound) {
+			return exception.New(exception.CodeUserNotFound)
+		}
+		return err
+	}
+
+	return ctx.Status(OK).JSON(user)
+}
+
+//	DisableUser disables a user
+//
+// @Summary      Disable user
+// @Description  Prevent a user from logging in and revoke its sessions.
+// @Description
+// @Description  Require the user:manage permission.
+// @Param 		 id   path  int true "user ID"
+// @Tags         User Management
+// @Produce      json
+// @Success      200 {object} Message
+// @Failure      400 {object} schema.Problem
+// @Failure      401 {object} schema.Problem
+// @Failure      403 {object} schema.Problem
+// @Failure      404 {object} schema.Problem
+// @Failure      409 {object} schema.Problem
+// @Failure      500 {object} schema.Problem
+// @Security JWT
+// @Security Bearer
+// @Router       /users/{id}/disable [post]
+func (c UserController) DisableUser(ctx *fiber.Ctx) error {
+	connectedUserID, err := c.GetConnectedUserID(ctx)
+	if err != nil {
+		return exception.ErrMalFormedJWT
+	}
+
+	userID, err := c.ConvertParamToInt("id", ctx)
+	if err != nil {
+		return exception.New(exception.CodeInvalidID)
+	}
+
+	// an admin can't lock himself out
+	if userID == connectedUserID {
+		return exception.New(exception.CodeDisableOwnAccount)
+	}
+
+	if err = c.service.Disable(userID); err != nil {
+		if errors.Is(err, exception.ErrRecordNotFound) {
+			return exception.New(exception.CodeUserNotFound)
+		}
+		return err
+	}
+
+	return ctx.Status(OK).JSON(NewMessage("user disabled"))
+}
+
+//	EnableUser enables a disabled user
+//
+// @Summary      Enable user
+// @Description  Let a disabled user log in again.
+// @Description
+// @Description  Require the user:manage permission.
+// @Param 		 id   path  int true "user ID"
+// @Tags         User Management
+// @Produce      json
+// @Success      200 {object} Message
+// @Failure      400 {object} schema.Problem
+// @Failure      401 {object} schema.Problem
+// @Failure      403 {object} schema.Problem
+// @Failure      404 {object} schema.Problem
+// @Failure      500 {object} schema.Problem
+// @Security JWT
+// @Security Bearer
+// @Router       /users/{id}/enable [post]
+func (c UserController) EnableUser(ctx *fiber.Ctx) error {
+	userID, err := c.ConvertParamToInt("id", ctx)
+	if err != nil {
+		return exception.New(exception.CodeInvalidID)
+	}
+
+	if err = c.service.Enable(userID); err != nil {
+		if errors.Is(err, exception.ErrRecordNotFound) {
+			return exception.New(exception.CodeUserNotFound)
+		}
+		return err
+	}
+
+	return ctx.Status(OK).JSON(NewMessage("user enabled"))
+}
+
+//	UnlockUser lets a locked out user log in again
+//
+// @Summary      Unlock user
+// @Description  Let a user locked out after too many failed logins log in again at once.
+// @Description
+// @Description  Require the user:manage permission.
+// @Param 		 id   path  int true "user ID"
+// @Tags         User Management
+// @Produce      json
+// @Success      200 {object} Message
+// @Failure      400 {object} schema.Problem
+// @Failure      401 {object} schema.Problem
+// @Failure      403 {object} schema.Problem
+// @Failure      404 {object} schema.Problem
+// @Failure      500 {object} schema.Problem
+// @Security JWT
+// @Security Bearer
+// @Router       /users/{id}/unlock [post]
+func (c UserController) UnlockUser(ctx *fiber.Ctx) error {
+	userID, err := c.ConvertParamToInt("id", ctx)
+	if err != nil {
+		return exception.New(exception.CodeInvalidID)
+	}
+
+	if err = c.service.Unlock(userID); err != nil {
+		if errors.Is(err, exception.ErrRecordNotFound) {
+			return exception.New(exception.CodeUserNotFound)
+		}
+		return err
+	}
+
+	return ctx.Status(OK).JSON(NewMessage("user unlocked"))
+}
+
+//	ResetPassword gives a user a temporary password
+//
+// @Summary      Reset user password
+// @Description  Replace the password of a user with a random one and revoke its sessions.
+// @Description  The temporary password is only shown in this response and must be changed on login.
+// @Description
+// @Description  Require the user:manage permission.
+// @Param 		 id   path  int true "user ID"
+// @Tags         User Management
+// @Produce      json
+// @Success      200 {object} schema.TemporaryPassword
+// @Failure      400 {object} schema.Problem
+// @Failure      401 {object} schema.Problem
+// @Failure      403 {object} schema.Problem
+// @Failure      404 {object} schema.Problem
+// @Failure      500 {object} schema.Problem
+// @Security JWT
+// @Security Bearer
+// @Router       /users/{id}/reset-password [post]
+func (c UserController) ResetPassword(ctx *fiber.Ctx) error {
+	userID, err := c.ConvertParamToInt("id", ctx)
+	if err != nil {
+		return exception.New(exception.CodeInvalidID)
+	}
+
+	password, err := c.service.ResetPassword(userID)
+	if err != nil {
 		if errors.Is(err, exception.ErrRecordNotFound) {
-			msg := "user " + err.Error()
-			return ctx.Status(NotFound).JSON(NewErrMessage(msg))
+			return exception.New(exception.CodeUserNotFound)
 		}
-		return c.HandleUnExpetedError(err, ctx)
+		return err
 	}
 
-	return ctx.Status(OK).JSON(NewMessage("password update successful"))
+	ctx.Set(fiber.HeaderCacheControl, "no-store")
+	return ctx.Status(OK).JSON(schema.TemporaryPassword{Password: password})
 }
@@ -50,6 +50,11 @@ func (r *realDB) GetDB() *gorm.DB {
 }
 
 func (r *realDB) MigrateAll() {
-	r.db.AutoMigrate(&model.Ingredient{}, &model.Recipe{}, &model.User{})
+	r.db.AutoMigrate(&model.Ingredient{}, &model.Tag{}, &model.Recipe{}, &model.User{},
+		&model.Substitution{}, &model.Substitute{}, &model.IngredientTranslation{}, &model.RecipeTranslation{}, &model.Session{},
+		&model.RefreshToken{}, &model.Revocation{}, &model.UserRole{}, &model.LoginFailure{}, &model.RecoveryCode{}, &model.MFAChallenge{},
+		&model.Invitation{}, &model.InvitationUse{})
+	migrateUserRoles(r.db)
+	migrateNames(r.db)
 	log.Println("Datase migrated successfully")
 }
@@ -4,7 +4,9 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/denisyao1/welsh-academy-api/model"
 	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
 )
 
 func TestSharedInMemoryDB(t *testing.T) {
@@ -25,3 +27,68 @@ func TestNotSharedInMemoryDB(t *testing.T) {
 	assert.False(strings.Contains(file, "&cache=shared"))
 
 }
+
+func TestMigrateNameKeys(t *testing.T) {
+	assert := assert.New(t)
+
+	db, err := NewInMemoryDB(false)
+	assert.NoError(err)
+	db.Migrate(model.Ingredient{}, model.Recipe{}, model.User{})
+
+	// rows created before name keys existed
+	gormDB := db.GetDB()
+	for _, name := range []string{"Tomato", "tomato ", "Leek"} {
+		gormDB.Exec("INSERT INTO ingredients (name) VALUES (?)", name)
+	}
+
+	collisions, err := migrateNameKeys(gormDB)
+	assert.NoError(err)
+	assert.Equal([]nameCollision{{Table: "ingredients", Key: "tomato", Names: []string{"Tomato", "tomato "}}}, collisions)
+
+	var keys []string
+	gormDB.Table("ingredients").Order("id").Pluck("name_key", &keys)
+	assert.Equal([]string{"tomato", "tomato", "leek"}, keys, "name keys should be filled")
+
+	// the key is unique once collisions are fixed
+	gormDB.Exec("DELETE FROM ingredients WHERE name = ?", "tomato ")
+	collisions, err = migrateNameKeys(gormDB)
+	assert.NoError(err)
+	assert.Empty(collisions)
+	err = gormDB.Exec("INSERT INTO ingredients (name, name_key) VALUES (?, ?)", "TOMATO", "tomato").Error
+	assert.Error(err, "name key should be unique")
+}
+
+func TestMigrateRoles(t *testing.T) {
+	assert := assert.New(t)
+
+	db, err := NewInMemoryDB(false)
+	assert.NoError(err)
+	gormDB := db.GetDB()
+
+	// users created before roles existed
+	type legacyUser struct {
+		ID          int
+		Username    string
+		UsernameKey string
+		Password    string
+		IsAdmin     bool
+		DeletedAt   gorm.DeletedAt
+	}
+	gormDB.Table("users").AutoMigrate(&legacyUser{})
+	db.Migrate(model.User{}, model.UserRole{})
+	gormDB.Exec("INSERT INTO users (username, username_key, password, is_admin) VALUES ('chef', 'chef', 'x', true)")
+	gormDB.Exec("INSERT INTO users (username, username_key, password, is_admin) VALUES ('cook', 'cook', 'x', false)")
+
+	assert.NoError(migrateRoles(gormDB))
+	assert.False(gormDB.Migrator().HasColumn(&model.User{}, "is_admin"), "is_admin column should be dropped")
+
+	var users []model.User
+	gormDB.Preload("Roles").Order("id").Find(&users)
+	if assert.Len(users, 2) {
+		assert.Equal([]model.Role{model.RoleAdmin}, users[0].RoleNames(), "admin should get the admin role")
+		assert.Equal([]model.Role{model.RoleReader}, users[1].RoleNames(), "other users should get the reader role")
+	}
+
+	// nothing left to migrate
+	assert.NoError(migrateRoles(gormDB))
+}
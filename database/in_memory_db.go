@@ -32,7 +32,12 @@ func (m InMemorySQLite) GetDB() *gorm.DB {
 }
 
 func (m InMemorySQLite) MigrateAll() {
-	m.db.AutoMigrate(&model.Ingredient{}, &model.Recipe{}, &model.User{})
+	m.db.AutoMigrate(&model.Ingredient{}, &model.Tag{}, &model.Recipe{}, &model.User{},
+		&model.Substitution{}, &model.Substitute{}, &model.IngredientTranslation{}, &model.RecipeTranslation{}, &model.Session{},
+		&model.RefreshToken{}, &model.Revocation{}, &model.UserRole{}, &model.LoginFailure{}, &model.RecoveryCode{}, &model.MFAChallenge{},
+		&model.Invitation{}, &model.InvitationUse{})
+	migrateUserRoles(m.db)
+	migrateNames(m.db)
 	log.Println("Test Datase migrated successfully")
 }
 
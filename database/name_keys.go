@@ -0,0 +1,99 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/denisyao1/welsh-academy-api/util"
+	"gorm.io/gorm"
+)
+
+// nameKeyColumns lists the tables whose names are unique, with their name and name key columns.
+var nameKeyColumns = []struct {
+	table, name, key string
+}{
+	{"ingredients", "name", "name_key"},
+	{"recipes", "name", "name_key"},
+	{"users", "username", "username_key"},
+}
+
+// nameCollision is a set of rows whose names only differ by case, spaces or Unicode form.
+type nameCollision struct {
+	Table string
+	Key   string
+	Names []string
+}
+
+// migrateNameKeys fills the name keys of the existing rows and makes them unique.
+//
+// The key of a table with colliding names can't be made unique: the collisions
+// are returned so that the rows can be renamed, and the index is created on the
+// next migration.
+func migrateNameKeys(db *gorm.DB) ([]nameCollision, error) {
+	var collisions []nameCollision
+
+	for _, c := range nameKeyColumns {
+		var rows []struct {
+			ID         int
+			Name       string
+			CurrentKey string
+		}
+		err := db.Table(c.table).
+			Select(fmt.Sprintf("id, %s AS name, %s AS current_key", c.name, c.key)).
+			Order("id").
+			Find(&rows).Error
+		if err != nil {
+			return nil, err
+		}
+
+		names := make(map[string][]string)
+		for _, row := range rows {
+			key := util.NameKey(row.Name)
+			names[key] = append(names[key], row.Name)
+			if key == row.CurrentKey {
+				continue
+			}
+			if err = db.Table(c.table).Where("id = ?", row.ID).Update(c.key, key).Error; err != nil {
+				return nil, err
+			}
+		}
+
+		var keys []string
+		for key := range names {
+			if len(names[key]) > 1 {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			collisions = append(collisions, nameCollision{Table: c.table, Key: key, Names: names[key]})
+		}
+		if len(keys) != 0 {
+			continue
+		}
+
+		err = db.Exec(fmt.Sprintf("CREATE UNIQUE INDEX IF NOT EXISTS idx_%s_%s ON %s (%s)",
+			c.table, c.key, c.table, c.key)).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return collisions, nil
+}
+
+// migrateNames runs migrateNameKeys and logs the name collisions.
+func migrateNames(db *gorm.DB) {
+	collisions, err := migrateNameKeys(db)
+	if err != nil {
+		log.Println("Failed to migrate name keys: ", err.Error())
+		return
+	}
+
+	for _, collision := range collisions {
+		log.Printf("Name collision in %s: '%s' are the same name, rename all but one of them\n",
+			collision.Table, strings.Join(collision.Names, "', '"))
+	}
+}
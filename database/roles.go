@@ -0,0 +1,41 @@
+package database
+
+import (
+	"log"
+
+	"github.com/denisyao1/welsh-academy-api/model"
+	"gorm.io/gorm"
+)
+
+// migrateRoles replaces the former is_admin column of users with role assignments:
+// admins get the admin role and the other users the reader role.
+//
+// Each step can run again, so that an interrupted migration is completed on the next one.
+func migrateRoles(db *gorm.DB) error {
+	if !db.Migrator().HasColumn(&model.User{}, "is_admin") {
+		return nil
+	}
+
+	err := db.Exec(`INSERT INTO user_roles (user_id, role)
+		SELECT id, ? FROM users WHERE is_admin AND id NOT IN (SELECT user_id FROM user_roles)`,
+		model.RoleAdmin).Error
+	if err != nil {
+		return err
+	}
+
+	err = db.Exec(`INSERT INTO user_roles (user_id, role)
+		SELECT id, ? FROM users WHERE id NOT IN (SELECT user_id FROM user_roles)`,
+		model.RoleReader).Error
+	if err != nil {
+		return err
+	}
+
+	return db.Migrator().DropColumn(&model.User{}, "is_admin")
+}
+
+// migrateUserRoles runs migrateRoles and logs its failure.
+func migrateUserRoles(db *gorm.DB) {
+	if err := migrateRoles(db); err != nil {
+		log.Println("Failed to migrate user roles: ", err.Error())
+	}
+}
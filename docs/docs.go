@@ -23,361 +23,324 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/health": {
-            "get": {
-                "description": "Check Api is running",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Health"
-                ],
-                "summary": "Health check",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/controller.Message"
-                        }
-                    },
-                    "500": {
-                        "description": "Internal Server Error"
-                    }
-                }
-            }
-        },
-        "/ingredients": {
+        "/admin/export": {
             "get": {
                 "security": [
                     {
                         "JWT": []
-                    }
-                ],
-                "description": "List ingredients.",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Ingredients"
-                ],
-                "summary": "List ingredients",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/schema.IngredientsResponse"
-                        }
-                    },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "$ref": "#/definitions/controller.ErrMessage"
-                        }
                     },
-                    "500": {
-                        "description": "Internal Server Error"
-                    }
-                }
-            },
-            "post": {
-                "security": [
                     {
-                        "JWT": []
+                        "Bearer": []
                     }
                 ],
-                "description": "Create an ingredient.\n\nRequire Admin Role.",
+                "description": "Download all the ingredients and recipes in the catalogue import format.\n\nRequire the recipe:create and ingredient:manage permissions.",
                 "produces": [
-                    "application/json"
+                    "text/plain"
                 ],
                 "tags": [
-                    "Ingredients"
+                    "Catalogue"
                 ],
-                "summary": "Create ingredient",
+                "summary": "Export catalogue",
                 "parameters": [
                     {
-                        "description": "Ingredient object",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/schema.Ingredient"
-                        }
+                        "enum": [
+                            "csv",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "default": "ndjson",
+                        "description": "catalogue format",
+                        "name": "format",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.Ingredient"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/schema.CatalogueRow"
+                            }
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/exception.ErrValidation"
+                            "$ref": "#/definitions/schema.Problem"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/controller.ErrMessage"
+                            "$ref": "#/definitions/schema.Problem"
                         }
                     },
-                    "409": {
-                        "description": "Conflict",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/controller.ErrMessage"
+                            "$ref": "#/definitions/schema.Problem"
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error"
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
                     }
                 }
             }
         },
-        "/login": {
+        "/admin/import": {
             "post": {
-                "description": "Get new cookie access token",
+                "security": [
+                    {
+                        "JWT": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Create ingredients and recipes from a CSV or NDJSON catalogue, either all of them or none.\nThe catalogue is sent as request body or as a multipart file named file.\n\nEach row is a schema.CatalogueRow. CSV files start with the header\ntype,name,making,ingredients,yield,prepTime,cookTime,allergens,tags and separate\ningredient names, allergens and tags with a |. Files without the tags column are accepted.\nRecipes can use ingredients created by previous rows.\n\nIn dry run mode, nothing is created and the response reports the invalid rows.\n\nRequire the recipe:create and ingredient:manage permissions.",
                 "consumes": [
-                    "application/json"
+                    "text/plain",
+                    "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "Catalogue"
                 ],
-                "summary": "Login",
+                "summary": "Import catalogue",
                 "parameters": [
                     {
-                        "description": "Credentials",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/schema.Login"
-                        }
+                        "enum": [
+                            "csv",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "description": "catalogue format, guessed from content type or file extension by default",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "only validate the catalogue",
+                        "name": "dryRun",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "dry run report",
                         "schema": {
-                            "$ref": "#/definitions/controller.Message"
+                            "$ref": "#/definitions/schema.ImportResponse"
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/schema.ImportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schema.ImportResponse"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/controller.ErrMessage"
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error"
-                    }
-                }
-            }
-        },
-        "/logout": {
-            "get": {
-                "description": "Logout",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Auth"
-                ],
-                "summary": "Logout",
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                        "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/controller.Message"
+                            "$ref": "#/definitions/schema.Problem"
                         }
                     }
                 }
             }
         },
-        "/recipes": {
+        "/admin/recipes/duplicates": {
             "get": {
                 "security": [
                     {
                         "JWT": []
+                    },
+                    {
+                        "Bearer": []
                     }
                 ],
-                "description": "List all possible recipes.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "List the groups of recipes with a close name and ingredients.\n\nRequire the recipe:publish permission.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Recipes"
                 ],
-                "summary": "List all possible recipes",
-                "parameters": [
-                    {
-                        "type": "array",
-                        "items": {
-                            "type": "string"
-                        },
-                        "collectionFormat": "csv",
-                        "name": "ingredients",
-                        "in": "query"
-                    }
-                ],
+                "summary": "List duplicate recipes",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/schema.RecipesResponse"
+                            "$ref": "#/definitions/schema.DuplicateClustersResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/controller.ErrMessage"
+                            "$ref": "#/definitions/schema.Problem"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/controller.ErrMessage"
+                            "$ref": "#/definitions/schema.Problem"
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error"
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
                     }
                 }
-            },
-            "post": {
+            }
+        },
+        "/admin/trash": {
+            "get": {
                 "security": [
                     {
                         "JWT": []
+                    },
+                    {
+                        "Bearer": []
                     }
                 ],
-                "description": "Create recipe.\n\nRequire Admin Role.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "List deleted ingredients, recipes and users.\n\nRequire the user:manage, ingredient:manage and recipe:publish permissions.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Recipes"
-                ],
-                "summary": "Create recipe",
-                "parameters": [
-                    {
-                        "description": "Recipe object",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/schema.Recipe"
-                        }
-                    }
+                    "Trash"
                 ],
+                "summary": "List trash",
                 "responses": {
-                    "201": {
-                        "description": "Created",
-                        "schema": {
-                            "$ref": "#/definitions/model.Recipe"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/exception.ErrValidation"
-                            }
+                            "$ref": "#/definitions/schema.TrashResponse"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/controller.ErrMessage"
+                            "$ref": "#/definitions/schema.Problem"
                         }
                     },
-                    "409": {
-                        "description": "Conflict",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/controller.ErrMessage"
+                            "$ref": "#/definitions/schema.Problem"
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error"
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
                     }
                 }
-            }
-        },
-        "/recipes/favorites": {
-            "get": {
+            },
+            "delete": {
                 "security": [
                     {
                         "JWT": []
+                    },
+                    {
+                        "Bearer": []
                     }
                 ],
-                "description": "list the connected user favorite recipes.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Permanently remove items deleted for longer than the retention period.\n\nRequire the user:manage, ingredient:manage and recipe:publish permissions.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "User Profile"
+                    "Trash"
                 ],
-                "summary": "List favorite recipes",
+                "summary": "Purge trash",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/schema.RecipesResponse"
+                            "$ref": "#/definitions/schema.PurgeResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/controller.ErrMessage"
+                            "$ref": "#/definitions/schema.Problem"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/controller.ErrMessage"
+                            "$ref": "#/definitions/schema.Problem"
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error"
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
                     }
                 }
             }
         },
-        "/recipes/{id}/flag-unflag": {
+        "/admin/trash/{type}/{id}/restore": {
             "post": {
                 "security": [
                     {
                         "JWT": []
+                    },
+                    {
+                        "Bearer": []
                     }
                 ],
-                "description": "Add or remove a recipe to your favorites.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Restore a deleted ingredient, recipe or user.\n\nRequire the user:manage, ingredient:manage and recipe:publish permissions.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Recipes"
+                    "Trash"
                 ],
-                "summary": "Flag or Unflag recipe",
+                "summary": "Restore item",
                 "parameters": [
+                    {
+                        "enum": [
+                            "ingredient",
+                            "recipe",
+                            "user"
+                        ],
+                        "type": "string",
+                        "description": "item type",
+                        "name": "type",
+                        "in": "path",
+                        "required": true
+                    },
                     {
                         "type": "integer",
-                        "description": "recipe ID",
+                        "description": "item ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -393,377 +356,5389 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/controller.ErrMessage"
+                            "$ref": "#/definitions/schema.Problem"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/controller.ErrMessage"
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/controller.ErrMessage"
+                            "$ref": "#/definitions/schema.Problem"
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error"
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
                     }
                 }
             }
         },
-        "/users": {
+        "/cookbooks": {
             "post": {
                 "security": [
                     {
                         "JWT": []
+                    },
+                    {
+                        "Bearer": []
                     }
                 ],
-                "description": "Create user.\n\nRequire Admin Role.",
+                "description": "Download recipes as a printable cookbook with a table of contents\nand one recipe per page, in the order of their IDs, in the language asked with\nthe lang query param or the Accept-Language header.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "text/html",
+                    "text/markdown",
+                    "application/pdf"
                 ],
                 "tags": [
-                    "User Management"
+                    "Recipes"
                 ],
-                "summary": "Create user",
+                "summary": "Create cookbook",
                 "parameters": [
                     {
-                        "description": "User object",
+                        "description": "Cookbook object",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/schema.User"
+                            "$ref": "#/definitions/schema.Cookbook"
                         }
+                    },
+                    {
+                        "enum": [
+                            "markdown",
+                            "html",
+                            "pdf"
+                        ],
+                        "type": "string",
+                        "default": "html",
+                        "description": "cookbook format",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "en",
+                            "cy"
+                        ],
+                        "type": "string",
+                        "description": "content language",
+                        "name": "lang",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "content language",
+                        "name": "Accept-Language",
+                        "in": "header"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.User"
+                            "type": "file"
+                        },
+                        "headers": {
+                            "Content-Language": {
+                                "type": "string",
+                                "description": "content language"
+                            }
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/controller.ErrMessage"
+                            "$ref": "#/definitions/schema.Problem"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/controller.ErrMessage"
+                            "$ref": "#/definitions/schema.Problem"
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error"
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
                     }
                 }
             }
         },
-        "/users/my-infos": {
+        "/health": {
+            "get": {
+                "description": "Check Api is running",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Health check",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Message"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/ingredients": {
             "get": {
                 "security": [
                     {
                         "JWT": []
+                    },
+                    {
+                        "Bearer": []
                     }
                 ],
-                "description": "Show connected user informations.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "List ingredients, named in the language asked with the lang query param\nor the Accept-Language header.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "User Profile"
+                    "Ingredients"
+                ],
+                "summary": "List ingredients",
+                "parameters": [
+                    {
+                        "enum": [
+                            "en",
+                            "cy"
+                        ],
+                        "type": "string",
+                        "description": "content language",
+                        "name": "lang",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "content language",
+                        "name": "Accept-Language",
+                        "in": "header"
+                    }
                 ],
-                "summary": "My infos",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.User"
+                            "$ref": "#/definitions/schema.IngredientsResponse"
+                        },
+                        "headers": {
+                            "Content-Language": {
+                                "type": "string",
+                                "description": "content language"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Create an ingredient.\n\nRequire the ingredient:manage permission.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Ingredients"
+                ],
+                "summary": "Create ingredient",
+                "parameters": [
+                    {
+                        "description": "Ingredient object",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.Ingredient"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.Ingredient"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/controller.ErrMessage"
+                            "$ref": "#/definitions/schema.Problem"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/controller.ErrMessage"
+                            "$ref": "#/definitions/schema.Problem"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/controller.ErrMessage"
+                            "$ref": "#/definitions/schema.Problem"
                         }
                     },
                     "409": {
                         "description": "Conflict",
                         "schema": {
-                            "$ref": "#/definitions/controller.ErrMessage"
+                            "$ref": "#/definitions/schema.Problem"
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error"
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
                     }
                 }
             }
         },
-        "/users/password-change": {
-            "patch": {
+        "/ingredients/{id}": {
+            "delete": {
                 "security": [
                     {
                         "JWT": []
+                    },
+                    {
+                        "Bearer": []
                     }
                 ],
-                "description": "Update connected user's password",
-                "consumes": [
+                "description": "Move an ingredient to the trash. It can be restored until the trash is purged.\n\nRequire the ingredient:manage permission.",
+                "produces": [
                     "application/json"
                 ],
+                "tags": [
+                    "Ingredients"
+                ],
+                "summary": "Delete ingredient",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ingredient ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/ingredients/{id}/substitutes": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "List the substitutions of an ingredient, named in the language asked with the lang\nquery param or the Accept-Language header.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "User Profile"
+                    "Ingredients"
                 ],
-                "summary": "Update password",
+                "summary": "List substitutes",
                 "parameters": [
                     {
-                        "description": "Password",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
+                        "type": "integer",
+                        "description": "ingredient ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "en",
+                            "cy"
+                        ],
+                        "type": "string",
+                        "description": "content language",
+                        "name": "lang",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "content language",
+                        "name": "Accept-Language",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/schema.Password"
+                            "$ref": "#/definitions/schema.SubstitutionsResponse"
+                        },
+                        "headers": {
+                            "Content-Language": {
+                                "type": "string",
+                                "description": "content language"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
                         }
                     }
+                }
+            }
+        },
+        "/ingredients/{id}/translations": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "List the translations of an ingredient name.\n\nRequire the ingredient:manage permission.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Translations"
+                ],
+                "summary": "List ingredient translations",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ingredient ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/controller.Message"
+                            "$ref": "#/definitions/schema.IngredientTranslationsResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/controller.ErrMessage"
+                            "$ref": "#/definitions/schema.Problem"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/controller.ErrMessage"
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/controller.ErrMessage"
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/ingredients/{id}/translations/{locale}": {
+            "put": {
+                "security": [
+                    {
+                        "JWT": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Create or replace the name of an ingredient in a locale.\n\nRequire the ingredient:manage permission.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Translations"
+                ],
+                "summary": "Translate ingredient",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ingredient ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "cy"
+                        ],
+                        "type": "string",
+                        "description": "locale",
+                        "name": "locale",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Translation object",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.IngredientTranslation"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.IngredientTranslation"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "JWT": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Delete the name of an ingredient in a locale.\n\nRequire the ingredient:manage permission.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Translations"
+                ],
+                "summary": "Delete ingredient translation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ingredient ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "cy"
+                        ],
+                        "type": "string",
+                        "description": "locale",
+                        "name": "locale",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/invitations": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "List the invitations, most recent first, with the users who registered with them.\n\nRequire the user:manage permission.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Management"
+                ],
+                "summary": "List invitations",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/schema.InvitationsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Create an invitation code letting people register with POST /register, with a role\n(reader by default), a number of uses (1 by default) and a validity in days (7 by default).\nThe code is only returned in this response.\n\nRequire the user:manage permission.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Management"
+                ],
+                "summary": "Create invitation",
+                "parameters": [
+                    {
+                        "description": "Invitation",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.Invitation"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.Invitation"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/invitations/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "JWT": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Revoke an invitation: its code can't be used anymore. The users who registered with it are kept.\n\nRequire the user:manage permission.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Management"
+                ],
+                "summary": "Revoke invitation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "invitation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/login": {
+            "post": {
+                "description": "Get new access token, valid for a few minutes, and a refresh token to renew it\nwith POST /token/refresh. They are set in the Auth and Refresh cookies by default.\n\nWith mode=token, the tokens are returned in the response body instead, the access\ntoken to be sent in the Authorization header with the Bearer scheme.\n\nEach failed login makes the next ones of the username and of the IP address wait longer,\nuntil they are locked out for a while. They get a 429 response telling when to retry.\n\nFor users with two-factor authentication, a 202 response gives a challenge instead,\nto complete with a code of their authenticator app at POST /login/mfa.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Login",
+                "parameters": [
+                    {
+                        "description": "Credentials",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.Login"
+                        }
+                    },
+                    {
+                        "enum": [
+                            "cookie",
+                            "token"
+                        ],
+                        "type": "string",
+                        "default": "cookie",
+                        "description": "where to return the tokens",
+                        "name": "mode",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "the tokens with mode=token, else a message",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Token"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/schema.MFAChallenge"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        },
+                        "headers": {
+                            "Retry-After": {
+                                "type": "integer",
+                                "description": "seconds to wait before retrying"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/login/mfa": {
+            "post": {
+                "description": "Complete the challenge of POST /login with a code of the authenticator app,\nor else with one of the recovery codes, each working once. The challenge expires\nafter a few minutes. Tokens are returned like with POST /login.\n\nWrong codes count as failed logins.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Login with two-factor authentication",
+                "parameters": [
+                    {
+                        "description": "Challenge and code",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.MFALogin"
+                        }
+                    },
+                    {
+                        "enum": [
+                            "cookie",
+                            "token"
+                        ],
+                        "type": "string",
+                        "default": "cookie",
+                        "description": "where to return the tokens",
+                        "name": "mode",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "the tokens with mode=token, else a message",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Token"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        },
+                        "headers": {
+                            "Retry-After": {
+                                "type": "integer",
+                                "description": "seconds to wait before retrying"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/logout": {
+            "get": {
+                "description": "Logout, revoking the session of the access token if there is a valid one.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Logout",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Message"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/recipes": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "List all possible recipes.\n\nWith substitutes=true, recipes also match through the ingredients the listed\nones can replace, and the substitutions used are returned.\n\nRecipes are shown in the language asked with the lang query param or the\nAccept-Language header; ingredients can be named in any language.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Recipes"
+                ],
+                "summary": "List all possible recipes",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "name": "ingredients",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "also match ingredients the listed ones can replace",
+                        "name": "substitutes",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "en",
+                            "cy"
+                        ],
+                        "type": "string",
+                        "description": "content language",
+                        "name": "lang",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "content language",
+                        "name": "Accept-Language",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/schema.RecipesResponse"
+                        },
+                        "headers": {
+                            "Content-Language": {
+                                "type": "string",
+                                "description": "content language"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Create recipe.\n\nRecipes with a close name and ingredients are returned as candidates in a 409 response,\nunless force is true. A recipe name can't be used twice, even with force.\n\nRequire the recipe:create permission.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Recipes"
+                ],
+                "summary": "Create recipe",
+                "parameters": [
+                    {
+                        "description": "Recipe object",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.Recipe"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "create the recipe even if it is likely a duplicate",
+                        "name": "force",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.Recipe"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/schema.DuplicateResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/recipes/favorites": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "list the connected user favorite recipes.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Profile"
+                ],
+                "summary": "List favorite recipes",
+                "parameters": [
+                    {
+                        "enum": [
+                            "en",
+                            "cy"
+                        ],
+                        "type": "string",
+                        "description": "content language",
+                        "name": "lang",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "content language",
+                        "name": "Accept-Language",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/schema.RecipesResponse"
+                        },
+                        "headers": {
+                            "Content-Language": {
+                                "type": "string",
+                                "description": "content language"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/recipes/favorites/export": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Download the connected user favorite recipes as a printable cookbook\nwith a table of contents and one recipe per page, in the language asked with\nthe lang query param or the Accept-Language header.",
+                "produces": [
+                    "text/html",
+                    "text/markdown",
+                    "application/pdf"
+                ],
+                "tags": [
+                    "User Profile"
+                ],
+                "summary": "Export favorite recipes",
+                "parameters": [
+                    {
+                        "enum": [
+                            "markdown",
+                            "html",
+                            "pdf"
+                        ],
+                        "type": "string",
+                        "default": "html",
+                        "description": "cookbook format",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "en",
+                            "cy"
+                        ],
+                        "type": "string",
+                        "description": "content language",
+                        "name": "lang",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "content language",
+                        "name": "Accept-Language",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        },
+                        "headers": {
+                            "Content-Language": {
+                                "type": "string",
+                                "description": "content language"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/recipes/import": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Create recipes from a JSON-LD document or an HTML page embedding JSON-LD.\nThe document is sent as request body or as a multipart file named file.\nIngredients are matched by name and created when they don't exist.\n\nRequire the recipe:create permission.",
+                "consumes": [
+                    "application/ld+json",
+                    "application/json",
+                    "text/html",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Recipes"
+                ],
+                "summary": "Import recipes",
+                "parameters": [
+                    {
+                        "description": "JSON-LD document",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.RecipeJSONLD"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/schema.RecipeImportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schema.RecipeImportResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/recipes/recommended": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Suggest recipes sharing ingredients with the connected user favorites,\nor favorited by the users who like the same recipes. Favorites are left out.\nUsers without favorites get the most favorited recipes.\n\nRecipes are shown in the language asked with the lang query param or the Accept-Language header.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Profile"
+                ],
+                "summary": "Recommended recipes",
+                "parameters": [
+                    {
+                        "maximum": 50,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 10,
+                        "description": "maximum number of recipes",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "en",
+                            "cy"
+                        ],
+                        "type": "string",
+                        "description": "content language",
+                        "name": "lang",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "content language",
+                        "name": "Accept-Language",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/schema.RecommendationsResponse"
+                        },
+                        "headers": {
+                            "Content-Language": {
+                                "type": "string",
+                                "description": "content language"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/recipes/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Get a recipe.\n\nThe recipe is returned as a schema.org Recipe in JSON-LD when the format\nquery param is jsonld or the Accept header is application/ld+json\n(see schema.RecipeJSONLD).",
+                "produces": [
+                    "application/json",
+                    "application/ld+json"
+                ],
+                "tags": [
+                    "Recipes"
+                ],
+                "summary": "Get recipe",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "recipe ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "jsonld"
+                        ],
+                        "type": "string",
+                        "description": "response format",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "en",
+                            "cy"
+                        ],
+                        "type": "string",
+                        "description": "content language",
+                        "name": "lang",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "content language",
+                        "name": "Accept-Language",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Recipe"
+                        },
+                        "headers": {
+                            "Content-Language": {
+                                "type": "string",
+                                "description": "content language"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "JWT": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Move a recipe to the trash. It can be restored until the trash is purged.\n\nRequire the recipe:publish permission.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Recipes"
+                ],
+                "summary": "Delete recipe",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "recipe ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/recipes/{id}/flag-unflag": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Add or remove a recipe to your favorites.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Recipes"
+                ],
+                "summary": "Flag or Unflag recipe",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "recipe ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/recipes/{id}/similar": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "List the recipes sharing ingredients or tags with a recipe, most similar first.\nRare ingredients weigh more than common ones like salt or butter.\nThe shared and differing ingredients are listed for each recipe.\n\nRecipes are shown in the language asked with the lang query param or the Accept-Language header.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Recipes"
+                ],
+                "summary": "Similar recipes",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "recipe ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "maximum": 50,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 10,
+                        "description": "maximum number of recipes",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "en",
+                            "cy"
+                        ],
+                        "type": "string",
+                        "description": "content language",
+                        "name": "lang",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "content language",
+                        "name": "Accept-Language",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/schema.SimilarRecipesResponse"
+                        },
+                        "headers": {
+                            "Content-Language": {
+                                "type": "string",
+                                "description": "content language"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/recipes/{id}/translations": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "List the translations of a recipe name and making.\n\nRequire the recipe:publish permission.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Translations"
+                ],
+                "summary": "List recipe translations",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "recipe ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/schema.RecipeTranslationsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/recipes/{id}/translations/{locale}": {
+            "put": {
+                "security": [
+                    {
+                        "JWT": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Create or replace the name and making of a recipe in a locale.\n\nRequire the recipe:publish permission.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Translations"
+                ],
+                "summary": "Translate recipe",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "recipe ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "cy"
+                        ],
+                        "type": "string",
+                        "description": "locale",
+                        "name": "locale",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Translation object",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.RecipeTranslation"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.RecipeTranslation"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "JWT": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Delete the name and making of a recipe in a locale.\n\nRequire the recipe:publish permission.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Translations"
+                ],
+                "summary": "Delete recipe translation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "recipe ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "cy"
+                        ],
+                        "type": "string",
+                        "description": "locale",
+                        "name": "locale",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/register": {
+            "post": {
+                "description": "Create an account with an invitation code, which gives its role. The username and\npassword are checked like when an admin creates a user. Log in with POST /login afterwards.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Register",
+                "parameters": [
+                    {
+                        "description": "Invitation code and credentials",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.Registration"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/roles": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "List the roles which can be assigned to users, with their permissions.\n\nRequire the user:manage permission.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Management"
+                ],
+                "summary": "List roles",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/schema.RolesResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/substitutions": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Tell which ingredients can replace an ingredient, and in which quantity.\nThe ratio is the quantity of substitute per unit of the replaced ingredient (1 by default).\n\nRequire the ingredient:manage permission.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Ingredients"
+                ],
+                "summary": "Create substitution",
+                "parameters": [
+                    {
+                        "description": "Substitution object",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.Substitution"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.Substitution"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/substitutions/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "JWT": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Delete a substitution.\n\nRequire the ingredient:manage permission.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Ingredients"
+                ],
+                "summary": "Delete substitution",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "substitution ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/token/refresh": {
+            "post": {
+                "description": "Get new access and refresh tokens from a refresh token, given in the request body\nor else in the Refresh cookie. A refresh token can only be used once: using it again\nrevokes all the tokens issued since the login.\n\nTokens are returned like with POST /login.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Refresh tokens",
+                "parameters": [
+                    {
+                        "description": "Refresh token",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/schema.RefreshToken"
+                        }
+                    },
+                    {
+                        "enum": [
+                            "cookie",
+                            "token"
+                        ],
+                        "type": "string",
+                        "default": "cookie",
+                        "description": "where to return the tokens",
+                        "name": "mode",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "the tokens with mode=token, else a message",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Token"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "List the users by username, a page at a time. Users in the trash are left out.\n\nRequire the user:manage permission.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Management"
+                ],
+                "summary": "List users",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "part of the username, ignoring case and spaces",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 50,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 10,
+                        "description": "users per page",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/schema.UsersResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Create user.\n\nRequire the user:manage permission.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Management"
+                ],
+                "summary": "Create user",
+                "parameters": [
+                    {
+                        "description": "User object",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.User"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/mfa/totp": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Generate a TOTP secret (RFC 6238) for the connected user, to add to an authenticator app\nwith the otpauth URI or its QR code. Two-factor authentication is enabled once a code\nof the app is verified with POST /users/me/mfa/totp/verify. Enrolling again replaces the secret.\nThe current password of the user is required.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Profile"
+                ],
+                "summary": "Enrol in two-factor authentication",
+                "parameters": [
+                    {
+                        "description": "Current password",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.PasswordConfirmation"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/schema.TOTPEnrolment"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "JWT": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Disable two-factor authentication of the connected user, given a code of the authenticator\napp or a recovery code. The recovery codes are removed.\n\nAdmins can't disable it when it is mandatory for them.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Profile"
+                ],
+                "summary": "Disable two-factor authentication",
+                "parameters": [
+                    {
+                        "description": "Code of the authenticator app, or recovery code",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.TOTPCode"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/mfa/totp/verify": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Enable two-factor authentication of the connected user, given a code of the secret\nenrolled with POST /users/me/mfa/totp. The response gives recovery codes, shown only once,\neach letting the user log in once without the authenticator app.\n\nAdmins who must use two-factor authentication can only do this, refreshing their tokens\nlifts the restriction.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Profile"
+                ],
+                "summary": "Enable two-factor authentication",
+                "parameters": [
+                    {
+                        "description": "Code of the authenticator app",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.TOTPCode"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/schema.RecoveryCodes"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/sessions": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "List the active sessions of the connected user, with the device and IP address\nthey were last used from, most recently used first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Profile"
+                ],
+                "summary": "My sessions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/schema.SessionsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/sessions/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "JWT": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Revoke a session of the connected user: its tokens are rejected from now on.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Profile"
+                ],
+                "summary": "Revoke session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Message"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/my-infos": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Show connected user informations.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Profile"
+                ],
+                "summary": "My infos",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/password-change": {
+            "patch": {
+                "security": [
+                    {
+                        "JWT": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Update connected user's password, given its current password. The other sessions\nof the user are revoked, and new tokens are issued for the current session: in the\nAuth and Refresh cookies by default, or in the response body with mode=token.\n\nUsers who must change their password can only do this, the new tokens lifting the restriction.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Profile"
+                ],
+                "summary": "Update password",
+                "parameters": [
+                    {
+                        "description": "Password",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.Password"
+                        }
+                    },
+                    {
+                        "enum": [
+                            "cookie",
+                            "token"
+                        ],
+                        "type": "string",
+                        "default": "cookie",
+                        "description": "where to return the tokens",
+                        "name": "mode",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "the tokens with mode=token, else a message",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Token"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Show a user with its roles.\n\nRequire the user:manage permission.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Management"
+                ],
+                "summary": "Get user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "user ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "JWT": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Move a user to the trash, remove its favorites and revoke its sessions.\nIt can be restored until the trash is purged.\n\nRequire the user:manage permission.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Management"
+                ],
+                "summary": "Delete user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "user ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "JWT": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Change the roles of a user, like PUT /users/{id}/roles. Missing fields are left unchanged.\n\nRequire the user:manage permission.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Management"
+                ],
+                "summary": "Update user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "user ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "changed fields",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.UserUpdate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/disable": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Prevent a user from logging in and revoke its sessions.\n\nRequire the user:manage permission.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Management"
+                ],
+                "summary": "Disable user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "user ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/enable": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Let a disabled user log in again.\n\nRequire the user:manage permission.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Management"
+                ],
+                "summary": "Enable user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "user ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/reset-password": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Replace the password of a user with a random one and revoke its sessions.\nThe temporary password is only shown in this response and must be changed on login.\n\nRequire the user:manage permission.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Management"
+                ],
+                "summary": "Reset user password",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "user ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/schema.TemporaryPassword"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/roles": {
+            "put": {
+                "security": [
+                    {
+                        "JWT": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Replace the roles of a user. The tokens issued before keep the former roles\nuntil they expire, unless the sessions of the user are revoked.\nThe last admin can't lose the admin role.\n\nRequire the user:manage permission.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Management"
+                ],
+                "summary": "Assign user roles",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "user ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "roles of the user",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schema.UserRoles"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/sessions": {
+            "delete": {
+                "security": [
+                    {
+                        "JWT": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Revoke all the sessions of a user: the user has to log in again.\n\nRequire the user:manage permission.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Management"
+                ],
+                "summary": "Revoke user sessions",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "user ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/unlock": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    },
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Let a user locked out after too many failed logins log in again at once.\n\nRequire the user:manage permission.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Management"
+                ],
+                "summary": "Unlock user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "user ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/schema.Problem"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "controller.Message": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "exception.Code": {
+            "type": "string",
+            "enum": [
+                "internal_error",
+                "invalid_body",
+                "invalid_query",
+                "invalid_file",
+                "invalid_id",
+                "invalid_request",
+                "bad_request",
+                "malformed_token",
+                "invalid_token",
+                "invalid_refresh_token",
+                "invalid_credentials",
+                "password_same",
+                "delete_own_account",
+                "disable_own_account",
+                "forbidden",
+                "user_disabled",
+                "password_change_required",
+                "last_admin",
+                "too_many_login_attempts",
+                "mfa_setup_required",
+                "mfa_mandatory",
+                "invalid_mfa_challenge",
+                "invalid_otp",
+                "mfa_already_enabled",
+                "mfa_not_enrolled",
+                "not_found",
+                "route_not_found",
+                "user_not_found",
+                "ingredient_not_found",
+                "recipe_not_found",
+                "substitution_not_found",
+                "translation_not_found",
+                "trash_item_not_found",
+                "session_not_found",
+                "invitation_not_found",
+                "duplicate_key",
+                "username_exists",
+                "ingredient_exists",
+                "recipe_exists",
+                "substitution_exists",
+                "ingredient_translation_exists",
+                "similar_recipes",
+                "required",
+                "too_short",
+                "empty_list",
+                "duplicate_items",
+                "too_long",
+                "too_few_items",
+                "too_many_items",
+                "too_small",
+                "too_large",
+                "not_one_of",
+                "invalid_limit",
+                "invalid_format",
+                "invalid_item_type",
+                "invalid_row_type",
+                "invalid_duration",
+                "invalid_minutes",
+                "invalid_document",
+                "invalid_jsonld",
+                "invalid_json_line",
+                "invalid_header",
+                "field_count",
+                "no_recipe_in_document",
+                "no_favorites",
+                "unknown_ingredient",
+                "unknown_recipe",
+                "self_substitute",
+                "default_locale",
+                "unsupported_locale",
+                "password_too_long",
+                "password_char_classes",
+                "password_is_username",
+                "password_breached",
+                "wrong_password",
+                "invalid_invitation"
+            ],
+            "x-enum-varnames": [
+                "CodeInternal",
+                "CodeInvalidBody",
+                "CodeInvalidQuery",
+                "CodeInvalidFile",
+                "CodeInvalidID",
+                "CodeInvalidRequest",
+                "CodeBadRequest",
+                "CodeMalformedToken",
+                "CodeInvalidToken",
+                "CodeInvalidRefreshToken",
+                "CodeInvalidCredentials",
+                "CodePasswordSame",
+                "CodeDeleteOwnAccount",
+                "CodeDisableOwnAccount",
+                "CodeForbidden",
+                "CodeUserDisabled",
+                "CodePasswordChangeRequired",
+                "CodeLastAdmin",
+                "CodeTooManyLoginAttempts",
+                "CodeMFASetupRequired",
+                "CodeMFAMandatory",
+                "CodeInvalidMFAChallenge",
+                "CodeInvalidOTP",
+                "CodeMFAAlreadyEnabled",
+                "CodeMFANotEnrolled",
+                "CodeNotFound",
+                "CodeRouteNotFound",
+                "CodeUserNotFound",
+                "CodeIngredientNotFound",
+                "CodeRecipeNotFound",
+                "CodeSubstitutionNotFound",
+                "CodeTranslationNotFound",
+                "CodeTrashItemNotFound",
+                "CodeSessionNotFound",
+                "CodeInvitationNotFound",
+                "CodeDuplicateKey",
+                "CodeUsernameExists",
+                "CodeIngredientExists",
+                "CodeRecipeExists",
+                "CodeSubstitutionExists",
+                "CodeIngredientTranslationExists",
+                "CodeSimilarRecipes",
+                "CodeRequired",
+                "CodeTooShort",
+                "CodeEmptyList",
+                "CodeDuplicateItems",
+                "CodeTooLong",
+                "CodeTooFewItems",
+                "CodeTooManyItems",
+                "CodeTooSmall",
+                "CodeTooLarge",
+                "CodeNotOneOf",
+                "CodeInvalidLimit",
+                "CodeInvalidFormat",
+                "CodeInvalidItemType",
+                "CodeInvalidRowType",
+                "CodeInvalidDuration",
+                "CodeInvalidMinutes",
+                "CodeInvalidDocument",
+                "CodeInvalidJSONLD",
+                "CodeInvalidJSONLine",
+                "CodeInvalidHeader",
+                "CodeFieldCount",
+                "CodeNoRecipeInDocument",
+                "CodeNoFavorites",
+                "CodeUnknownIngredient",
+                "CodeUnknownRecipe",
+                "CodeSelfSubstitute",
+                "CodeDefaultLocale",
+                "CodeUnsupportedLocale",
+                "CodePasswordTooLong",
+                "CodePasswordCharClasses",
+                "CodePasswordIsUsername",
+                "CodePasswordBreached",
+                "CodeWrongPassword",
+                "CodeInvalidInvitation"
+            ]
+        },
+        "exception.ErrValidation": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "$ref": "#/definitions/exception.Code"
+                },
+                "field": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.Ingredient": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer",
+                    "x-order": "1",
+                    "example": 1
+                },
+                "allergens": {
+                    "type": "array",
+                    "items": {
+                        "type": "string",
+                        "enum": [
+                            "celery",
+                            "gluten",
+                            "crustaceans",
+                            "eggs",
+                            "fish",
+                            "lupin",
+                            "milk",
+                            "molluscs",
+                            "mustard",
+                            "nuts",
+                            "peanuts",
+                            "sesame",
+                            "soya",
+                            "sulphites"
+                        ]
+                    }
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Tomato"
+                }
+            }
+        },
+        "model.IngredientTranslation": {
+            "type": "object",
+            "properties": {
+                "locale": {
+                    "type": "string",
+                    "x-order": "1",
+                    "example": "cy"
+                },
+                "name": {
+                    "type": "string",
+                    "x-order": "2",
+                    "example": "Caws"
+                }
+            }
+        },
+        "model.Invitation": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer",
+                    "x-order": "1",
+                    "example": 1
+                },
+                "uses": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.InvitationUse"
+                    },
+                    "x-order": "10"
+                },
+                "code": {
+                    "description": "only returned on creation",
+                    "type": "string",
+                    "x-order": "2"
+                },
+                "role": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.Role"
+                        }
+                    ],
+                    "x-order": "3",
+                    "example": "reader"
+                },
+                "maxUses": {
+                    "type": "integer",
+                    "x-order": "4",
+                    "example": 1
+                },
+                "useCount": {
+                    "type": "integer",
+                    "x-order": "5",
+                    "example": 0
+                },
+                "expiresAt": {
+                    "type": "string",
+                    "x-order": "6"
+                },
+                "createdBy": {
+                    "description": "ID of the admin",
+                    "type": "integer",
+                    "x-order": "7",
+                    "example": 1
+                },
+                "createdAt": {
+                    "type": "string",
+                    "x-order": "8"
+                },
+                "revokedAt": {
+                    "type": "string",
+                    "x-order": "9"
+                }
+            }
+        },
+        "model.InvitationUse": {
+            "type": "object",
+            "properties": {
+                "userId": {
+                    "type": "integer",
+                    "x-order": "1",
+                    "example": 2
+                },
+                "username": {
+                    "description": "kept when the user is purged",
+                    "type": "string",
+                    "x-order": "2"
+                },
+                "usedAt": {
+                    "type": "string",
+                    "x-order": "3"
+                }
+            }
+        },
+        "model.Permission": {
+            "type": "string",
+            "enum": [
+                "recipe:create",
+                "recipe:publish",
+                "ingredient:manage",
+                "user:manage",
+                "review:moderate"
+            ],
+            "x-enum-comments": {
+                "PermIngredientManage": "create, translate, substitute and delete ingredients",
+                "PermRecipeCreate": "create and import recipes",
+                "PermRecipePublish": "translate, merge and delete recipes",
+                "PermReviewModerate": "moderate the reviews of recipes",
+                "PermUserManage": "create and delete users, assign their roles"
+            },
+            "x-enum-varnames": [
+                "PermRecipeCreate",
+                "PermRecipePublish",
+                "PermIngredientManage",
+                "PermUserManage",
+                "PermReviewModerate"
+            ]
+        },
+        "model.Recipe": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer",
+                    "x-order": "1",
+                    "example": 1
+                },
+                "name": {
+                    "type": "string",
+                    "x-order": "2"
+                },
+                "making": {
+                    "type": "string",
+                    "x-order": "3"
+                },
+                "cookTime": {
+                    "description": "in minutes",
+                    "type": "integer",
+                    "example": 10
+                },
+                "ingredients": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Ingredient"
+                    }
+                },
+                "prepTime": {
+                    "description": "in minutes",
+                    "type": "integer",
+                    "example": 15
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Tag"
+                    }
+                },
+                "yield": {
+                    "type": "string",
+                    "example": "4 servings"
+                }
+            }
+        },
+        "model.RecipeTranslation": {
+            "type": "object",
+            "properties": {
+                "locale": {
+                    "type": "string",
+                    "x-order": "1",
+                    "example": "cy"
+                },
+                "name": {
+                    "type": "string",
+                    "x-order": "2",
+                    "example": "Caws Pobi"
+                },
+                "making": {
+                    "type": "string",
+                    "x-order": "3"
+                }
+            }
+        },
+        "model.Role": {
+            "type": "string",
+            "enum": [
+                "admin",
+                "editor",
+                "contributor",
+                "reader"
+            ],
+            "x-enum-varnames": [
+                "RoleAdmin",
+                "RoleEditor",
+                "RoleContributor",
+                "RoleReader"
+            ]
+        },
+        "model.Substitute": {
+            "type": "object",
+            "properties": {
+                "ingredient": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.Ingredient"
+                        }
+                    ],
+                    "x-order": "1"
+                },
+                "ratio": {
+                    "description": "quantity per unit of the replaced ingredient",
+                    "type": "number",
+                    "x-order": "2",
+                    "example": 1
+                }
+            }
+        },
+        "model.Substitution": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer",
+                    "x-order": "1",
+                    "example": 1
+                },
+                "ingredient": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.Ingredient"
+                        }
+                    ],
+                    "x-order": "2"
+                },
+                "substitutes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Substitute"
+                    },
+                    "x-order": "3"
+                },
+                "notes": {
+                    "type": "string",
+                    "x-order": "4",
+                    "example": "Gives a crumblier sauce."
+                }
+            }
+        },
+        "model.Tag": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "soup"
+                }
+            }
+        },
+        "model.User": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer",
+                    "x-order": "0",
+                    "example": 1
+                },
+                "username": {
+                    "type": "string",
+                    "x-order": "1"
+                },
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "x-order": "2",
+                    "example": [
+                        "reader"
+                    ]
+                },
+                "disabledAt": {
+                    "description": "disabled users can't log in",
+                    "type": "string",
+                    "x-order": "3"
+                },
+                "mustChangePassword": {
+                    "description": "temporary passwords only let the user change them",
+                    "type": "boolean",
+                    "x-order": "4"
+                },
+                "passwordChangedAt": {
+                    "description": "last change by the user",
+                    "type": "string",
+                    "x-order": "5"
+                },
+                "totpEnabled": {
+                    "description": "logins need a code of the TOTP secret",
+                    "type": "boolean",
+                    "x-order": "6"
+                }
+            }
+        },
+        "schema.CatalogueRow": {
+            "type": "object",
+            "properties": {
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "ingredient",
+                        "recipe"
+                    ],
+                    "x-order": "1"
+                },
+                "name": {
+                    "type": "string",
+                    "x-order": "2"
+                },
+                "making": {
+                    "type": "string",
+                    "x-order": "3"
+                },
+                "ingredients": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "x-order": "4"
+                },
+                "yield": {
+                    "type": "string",
+                    "x-order": "5"
+                },
+                "prepTime": {
+                    "description": "in minutes",
+                    "type": "integer",
+                    "x-order": "6"
+                },
+                "cookTime": {
+                    "description": "in minutes",
+                    "type": "integer",
+                    "x-order": "7"
+                },
+                "allergens": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "x-order": "8"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "x-order": "9"
+                }
+            }
+        },
+        "schema.Cookbook": {
+            "type": "object",
+            "properties": {
+                "title": {
+                    "type": "string",
+                    "x-order": "1",
+                    "example": "Cheddar classics"
+                },
+                "recipeIds": {
+                    "type": "array",
+                    "minItems": 1,
+                    "uniqueItems": true,
+                    "items": {
+                        "type": "integer"
+                    },
+                    "x-order": "2"
+                }
+            }
+        },
+        "schema.DuplicateCandidate": {
+            "type": "object",
+            "properties": {
+                "recipe": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.Recipe"
+                        }
+                    ],
+                    "x-order": "1"
+                },
+                "nameSimilarity": {
+                    "type": "number",
+                    "x-order": "2",
+                    "example": 1
+                },
+                "ingredientSimilarity": {
+                    "type": "number",
+                    "x-order": "3",
+                    "example": 0.8
+                }
+            }
+        },
+        "schema.DuplicateCluster": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "recipes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Recipe"
+                    }
+                }
+            }
+        },
+        "schema.DuplicateClustersResponse": {
+            "type": "object",
+            "properties": {
+                "clusters": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.DuplicateCluster"
+                    }
+                },
+                "count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "schema.DuplicateResponse": {
+            "type": "object",
+            "properties": {
+                "type": {
+                    "type": "string",
+                    "x-order": "1",
+                    "example": "urn:welsh-academy:problem:recipe_not_found"
+                },
+                "title": {
+                    "type": "string",
+                    "x-order": "2",
+                    "example": "Not Found"
+                },
+                "status": {
+                    "type": "integer",
+                    "x-order": "3",
+                    "example": 404
+                },
+                "detail": {
+                    "description": "in the user language",
+                    "type": "string",
+                    "x-order": "4",
+                    "example": "Recipe not found."
+                },
+                "instance": {
+                    "type": "string",
+                    "x-order": "5",
+                    "example": "/api/v1/recipes/42"
+                },
+                "code": {
+                    "type": "string",
+                    "x-order": "6",
+                    "example": "recipe_not_found"
+                },
+                "errors": {
+                    "description": "invalid fields of the request",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/exception.ErrValidation"
+                    },
+                    "x-order": "7"
+                },
+                "correlationId": {
+                    "description": "only for unexpected errors",
+                    "type": "string",
+                    "x-order": "8"
+                },
+                "candidates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.DuplicateCandidate"
+                    },
+                    "x-order": "9"
+                }
+            }
+        },
+        "schema.HowToStep": {
+            "type": "object",
+            "properties": {
+                "@type": {
+                    "type": "string",
+                    "example": "HowToStep"
+                },
+                "text": {
+                    "type": "string",
+                    "example": "Melt the butter."
+                }
+            }
+        },
+        "schema.ImportResponse": {
+            "type": "object",
+            "properties": {
+                "dryRun": {
+                    "type": "boolean",
+                    "x-order": "1"
+                },
+                "applied": {
+                    "type": "boolean",
+                    "x-order": "2"
+                },
+                "ingredients": {
+                    "description": "number of valid ingredients",
+                    "type": "integer",
+                    "x-order": "3"
+                },
+                "recipes": {
+                    "description": "number of valid recipes",
+                    "type": "integer",
+                    "x-order": "4"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.ImportRowError"
+                    },
+                    "x-order": "5"
+                }
+            }
+        },
+        "schema.ImportRowError": {
+            "type": "object",
+            "properties": {
+                "line": {
+                    "type": "integer",
+                    "x-order": "1"
+                },
+                "type": {
+                    "type": "string",
+                    "x-order": "2"
+                },
+                "name": {
+                    "type": "string",
+                    "x-order": "3"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "type": "object"
+                    },
+                    "x-order": "4"
+                }
+            }
+        },
+        "schema.Ingredient": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "x-order": "1",
+                    "example": "Tomato"
+                },
+                "allergens": {
+                    "type": "array",
+                    "uniqueItems": true,
+                    "items": {
+                        "type": "string",
+                        "enum": [
+                            "celery",
+                            "gluten",
+                            "crustaceans",
+                            "eggs",
+                            "fish",
+                            "lupin",
+                            "milk",
+                            "molluscs",
+                            "mustard",
+                            "nuts",
+                            "peanuts",
+                            "sesame",
+                            "soya",
+                            "sulphites"
+                        ]
+                    },
+                    "x-order": "2"
+                }
+            }
+        },
+        "schema.IngredientTranslation": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "Caws"
+                }
+            }
+        },
+        "schema.IngredientTranslationsResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "translations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.IngredientTranslation"
+                    }
+                }
+            }
+        },
+        "schema.IngredientsResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "ingredients": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Ingredient"
+                    }
+                }
+            }
+        },
+        "schema.Invitation": {
+            "type": "object",
+            "properties": {
+                "role": {
+                    "description": "reader by default",
+                    "enum": [
+                        "admin",
+                        "editor",
+                        "contributor",
+                        "reader"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.Role"
+                        }
+                    ],
+                    "x-order": "1",
+                    "example": "reader"
+                },
+                "maxUses": {
+                    "description": "1 by default",
+                    "type": "integer",
+                    "maximum": 1000,
+                    "minimum": 0,
+                    "x-order": "2",
+                    "example": 1
+                },
+                "validDays": {
+                    "description": "7 by default",
+                    "type": "integer",
+                    "maximum": 365,
+                    "minimum": 0,
+                    "x-order": "3",
+                    "example": 7
+                }
+            }
+        },
+        "schema.InvitationsResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "invitations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Invitation"
+                    }
+                }
+            }
+        },
+        "schema.Login": {
+            "type": "object",
+            "required": [
+                "password",
+                "username"
+            ],
+            "properties": {
+                "username": {
+                    "type": "string",
+                    "x-order": "1",
+                    "example": "username"
+                },
+                "password": {
+                    "type": "string",
+                    "x-order": "2",
+                    "example": "password"
+                }
+            }
+        },
+        "schema.MFAChallenge": {
+            "type": "object",
+            "properties": {
+                "challengeToken": {
+                    "type": "string",
+                    "x-order": "1"
+                },
+                "expiresAt": {
+                    "type": "string",
+                    "x-order": "2"
+                },
+                "expiresIn": {
+                    "description": "in seconds",
+                    "type": "integer",
+                    "x-order": "3",
+                    "example": 300
+                }
+            }
+        },
+        "schema.MFALogin": {
+            "type": "object",
+            "required": [
+                "challengeToken",
+                "code"
+            ],
+            "properties": {
+                "challengeToken": {
+                    "type": "string",
+                    "x-order": "1"
+                },
+                "code": {
+                    "type": "string",
+                    "x-order": "2",
+                    "example": "123456"
+                }
+            }
+        },
+        "schema.Password": {
+            "type": "object",
+            "required": [
+                "currentPassword",
+                "password"
+            ],
+            "properties": {
+                "currentPassword": {
+                    "type": "string",
+                    "x-order": "1"
+                },
+                "password": {
+                    "description": "the new password",
+                    "type": "string",
+                    "x-order": "2"
+                }
+            }
+        },
+        "schema.PasswordConfirmation": {
+            "type": "object",
+            "required": [
+                "currentPassword"
+            ],
+            "properties": {
+                "currentPassword": {
+                    "type": "string"
+                }
+            }
+        },
+        "schema.Problem": {
+            "type": "object",
+            "properties": {
+                "type": {
+                    "type": "string",
+                    "x-order": "1",
+                    "example": "urn:welsh-academy:problem:recipe_not_found"
+                },
+                "title": {
+                    "type": "string",
+                    "x-order": "2",
+                    "example": "Not Found"
+                },
+                "status": {
+                    "type": "integer",
+                    "x-order": "3",
+                    "example": 404
+                },
+                "detail": {
+                    "description": "in the user language",
+                    "type": "string",
+                    "x-order": "4",
+                    "example": "Recipe not found."
+                },
+                "instance": {
+                    "type": "string",
+                    "x-order": "5",
+                    "example": "/api/v1/recipes/42"
+                },
+                "code": {
+                    "type": "string",
+                    "x-order": "6",
+                    "example": "recipe_not_found"
+                },
+                "errors": {
+                    "description": "invalid fields of the request",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/exception.ErrValidation"
+                    },
+                    "x-order": "7"
+                },
+                "correlationId": {
+                    "description": "only for unexpected errors",
+                    "type": "string",
+                    "x-order": "8"
+                }
+            }
+        },
+        "schema.PurgeResponse": {
+            "type": "object",
+            "properties": {
+                "ingredients": {
+                    "type": "integer"
+                },
+                "recipes": {
+                    "type": "integer"
+                },
+                "users": {
+                    "type": "integer"
+                }
+            }
+        },
+        "schema.Recipe": {
+            "type": "object",
+            "required": [
+                "making",
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "x-order": "1"
+                },
+                "making": {
+                    "type": "string",
+                    "x-order": "2"
+                },
+                "ingredients": {
+                    "type": "array",
+                    "minItems": 1,
+                    "uniqueItems": true,
+                    "items": {
+                        "$ref": "#/definitions/schema.Ingredient"
+                    },
+                    "x-order": "3"
+                },
+                "yield": {
+                    "type": "string",
+                    "x-order": "4",
+                    "example": "4 servings"
+                },
+                "prepTime": {
+                    "description": "in minutes",
+                    "type": "integer",
+                    "minimum": 0,
+                    "x-order": "5",
+                    "example": 15
+                },
+                "cookTime": {
+                    "description": "in minutes",
+                    "type": "integer",
+                    "minimum": 0,
+                    "x-order": "6",
+                    "example": 10
+                },
+                "tags": {
+                    "type": "array",
+                    "uniqueItems": true,
+                    "items": {
+                        "$ref": "#/definitions/schema.Tag"
+                    },
+                    "x-order": "7"
+                }
+            }
+        },
+        "schema.RecipeImportError": {
+            "type": "object",
+            "properties": {
+                "index": {
+                    "description": "position of the recipe in the document",
+                    "type": "integer",
+                    "x-order": "1"
+                },
+                "name": {
+                    "type": "string",
+                    "x-order": "2"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "type": "object"
+                    },
+                    "x-order": "3"
+                }
+            }
+        },
+        "schema.RecipeImportResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "x-order": "1"
+                },
+                "recipes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Recipe"
+                    },
+                    "x-order": "2"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.RecipeImportError"
+                    },
+                    "x-order": "3"
+                }
+            }
+        },
+        "schema.RecipeJSONLD": {
+            "type": "object",
+            "properties": {
+                "@context": {
+                    "type": "string",
+                    "x-order": "1",
+                    "example": "https://schema.org"
+                },
+                "recipeInstructions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.HowToStep"
+                    },
+                    "x-order": "10"
+                },
+                "inLanguage": {
+                    "type": "string",
+                    "x-order": "11",
+                    "example": "en"
+                },
+                "@type": {
+                    "type": "string",
+                    "x-order": "2",
+                    "example": "Recipe"
+                },
+                "identifier": {
+                    "type": "integer",
+                    "x-order": "3",
+                    "example": 1
+                },
+                "name": {
+                    "type": "string",
+                    "x-order": "4"
+                },
+                "recipeYield": {
+                    "type": "string",
+                    "x-order": "5",
+                    "example": "4 servings"
+                },
+                "prepTime": {
+                    "type": "string",
+                    "x-order": "6",
+                    "example": "PT15M"
+                },
+                "cookTime": {
+                    "type": "string",
+                    "x-order": "7",
+                    "example": "PT10M"
+                },
+                "totalTime": {
+                    "type": "string",
+                    "x-order": "8",
+                    "example": "PT25M"
+                },
+                "recipeIngredient": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "x-order": "9"
+                }
+            }
+        },
+        "schema.RecipeTranslation": {
+            "type": "object",
+            "required": [
+                "making",
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "x-order": "1",
+                    "example": "Caws Pobi"
+                },
+                "making": {
+                    "type": "string",
+                    "x-order": "2"
+                }
+            }
+        },
+        "schema.RecipeTranslationsResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "translations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.RecipeTranslation"
+                    }
+                }
+            }
+        },
+        "schema.RecipesResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "recipes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Recipe"
+                    }
+                },
+                "substitutions": {
+                    "description": "only with the substitutes query param",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Substitution"
+                    }
+                }
+            }
+        },
+        "schema.Recommendation": {
+            "type": "object",
+            "properties": {
+                "recipe": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.Recipe"
                         }
+                    ],
+                    "x-order": "1"
+                },
+                "score": {
+                    "type": "number",
+                    "x-order": "2",
+                    "example": 0.42
+                },
+                "because": {
+                    "description": "favorite recipes the suggestion is based on",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
                     },
-                    "500": {
-                        "description": "Internal Server Error"
+                    "x-order": "3"
+                }
+            }
+        },
+        "schema.RecommendationsResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "recommendations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.Recommendation"
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "controller.ErrMessage": {
+        },
+        "schema.RecoveryCodes": {
             "type": "object",
             "properties": {
-                "error": {
-                    "type": "string"
+                "codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "3f9a0-c5d1b"
+                    ]
                 }
             }
         },
-        "controller.Message": {
+        "schema.RefreshToken": {
             "type": "object",
             "properties": {
-                "message": {
+                "refreshToken": {
                     "type": "string"
                 }
             }
         },
-        "exception.ErrValidation": {
+        "schema.Registration": {
             "type": "object",
+            "required": [
+                "invitationCode",
+                "password",
+                "username"
+            ],
             "properties": {
-                "description": {
-                    "type": "string"
+                "invitationCode": {
+                    "type": "string",
+                    "x-order": "1"
                 },
-                "field": {
-                    "type": "string"
+                "username": {
+                    "type": "string",
+                    "minLength": 3,
+                    "x-order": "2"
+                },
+                "password": {
+                    "type": "string",
+                    "x-order": "3"
                 }
             }
         },
-        "model.Ingredient": {
+        "schema.Role": {
             "type": "object",
             "properties": {
-                "id": {
-                    "type": "integer",
+                "name": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.Role"
+                        }
+                    ],
                     "x-order": "1",
-                    "example": 1
+                    "example": "editor"
                 },
-                "name": {
-                    "type": "string",
-                    "example": "Tomato"
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Permission"
+                    },
+                    "x-order": "2"
                 }
             }
         },
-        "model.Recipe": {
+        "schema.RolesResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.Role"
+                    }
+                }
+            }
+        },
+        "schema.Session": {
             "type": "object",
             "properties": {
                 "id": {
-                    "type": "integer",
+                    "type": "string",
                     "x-order": "1",
-                    "example": 1
+                    "example": "3f9a0c5d1b7e4a2f8c6d0e1f2a3b4c5d"
                 },
-                "name": {
+                "userAgent": {
                     "type": "string",
-                    "x-order": "2"
+                    "x-order": "2",
+                    "example": "Mozilla/5.0"
                 },
-                "making": {
+                "ip": {
                     "type": "string",
-                    "x-order": "3"
+                    "x-order": "3",
+                    "example": "192.0.2.1"
                 },
-                "ingredients": {
+                "createdAt": {
+                    "type": "string",
+                    "x-order": "4"
+                },
+                "lastUsedAt": {
+                    "description": "last login or refresh",
+                    "type": "string",
+                    "x-order": "5"
+                },
+                "current": {
+                    "description": "session of the request",
+                    "type": "boolean",
+                    "x-order": "6"
+                }
+            }
+        },
+        "schema.SessionsResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "sessions": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/model.Ingredient"
+                        "$ref": "#/definitions/schema.Session"
                     }
                 }
             }
         },
-        "model.User": {
+        "schema.SimilarRecipe": {
             "type": "object",
             "properties": {
-                "username": {
-                    "type": "string",
+                "recipe": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.Recipe"
+                        }
+                    ],
                     "x-order": "1"
                 },
-                "admin": {
-                    "type": "boolean"
+                "score": {
+                    "type": "number",
+                    "x-order": "2",
+                    "example": 0.42
+                },
+                "sharedIngredients": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "x-order": "3"
+                },
+                "missingIngredients": {
+                    "description": "only in the requested recipe",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "x-order": "4"
+                },
+                "extraIngredients": {
+                    "description": "only in the similar recipe",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "x-order": "5"
+                },
+                "sharedTags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "x-order": "6"
                 }
             }
         },
-        "schema.Ingredient": {
+        "schema.SimilarRecipesResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "recipes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schema.SimilarRecipe"
+                    }
+                }
+            }
+        },
+        "schema.Substitute": {
             "type": "object",
+            "required": [
+                "name"
+            ],
             "properties": {
                 "name": {
-                    "type": "string"
+                    "type": "string",
+                    "x-order": "1",
+                    "example": "milk"
+                },
+                "ratio": {
+                    "description": "quantity per unit of the replaced ingredient",
+                    "type": "number",
+                    "default": 1,
+                    "minimum": 0,
+                    "x-order": "2",
+                    "example": 1
                 }
             }
         },
-        "schema.IngredientsResponse": {
+        "schema.Substitution": {
+            "type": "object",
+            "required": [
+                "ingredient"
+            ],
+            "properties": {
+                "ingredient": {
+                    "type": "string",
+                    "x-order": "1",
+                    "example": "buttermilk"
+                },
+                "substitutes": {
+                    "type": "array",
+                    "minItems": 1,
+                    "uniqueItems": true,
+                    "items": {
+                        "$ref": "#/definitions/schema.Substitute"
+                    },
+                    "x-order": "2"
+                },
+                "notes": {
+                    "type": "string",
+                    "x-order": "3",
+                    "example": "Let the mix rest for 10 minutes."
+                }
+            }
+        },
+        "schema.SubstitutionsResponse": {
             "type": "object",
             "properties": {
                 "count": {
                     "type": "integer"
                 },
-                "ingredients": {
+                "substitutions": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/model.Ingredient"
+                        "$ref": "#/definitions/model.Substitution"
                     }
                 }
             }
         },
-        "schema.Login": {
+        "schema.TOTPCode": {
             "type": "object",
+            "required": [
+                "code"
+            ],
             "properties": {
-                "username": {
+                "code": {
+                    "type": "string",
+                    "example": "123456"
+                }
+            }
+        },
+        "schema.TOTPEnrolment": {
+            "type": "object",
+            "properties": {
+                "secret": {
+                    "description": "base32",
                     "type": "string",
-                    "minLength": 3,
                     "x-order": "1",
-                    "example": "username"
+                    "example": "JBSWY3DPEHPK3PXP"
                 },
-                "password": {
+                "uri": {
                     "type": "string",
-                    "minLength": 4,
                     "x-order": "2",
-                    "example": "password"
+                    "example": "otpauth://totp/Welsh%20Academy:username?issuer=Welsh+Academy\u0026secret=JBSWY3DPEHPK3PXP"
+                },
+                "qrCode": {
+                    "description": "PNG data URI",
+                    "type": "string",
+                    "x-order": "3",
+                    "example": "data:image/png;base64,iVBORw0KGgo="
                 }
             }
         },
-        "schema.Password": {
+        "schema.Tag": {
             "type": "object",
+            "required": [
+                "name"
+            ],
             "properties": {
-                "password": {
+                "name": {
                     "type": "string",
-                    "minLength": 4
+                    "example": "soup"
                 }
             }
         },
-        "schema.Recipe": {
+        "schema.TemporaryPassword": {
             "type": "object",
             "properties": {
-                "name": {
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "schema.Token": {
+            "type": "object",
+            "properties": {
+                "accessToken": {
                     "type": "string",
                     "x-order": "1"
                 },
-                "making": {
+                "tokenType": {
+                    "type": "string",
+                    "x-order": "2",
+                    "example": "Bearer"
+                },
+                "expiresAt": {
+                    "type": "string",
+                    "x-order": "3"
+                },
+                "expiresIn": {
+                    "description": "in seconds",
+                    "type": "integer",
+                    "x-order": "4",
+                    "example": 900
+                },
+                "refreshToken": {
+                    "description": "can be used once",
+                    "type": "string",
+                    "x-order": "5"
+                },
+                "refreshExpiresAt": {
+                    "type": "string",
+                    "x-order": "6"
+                },
+                "mustChangePassword": {
+                    "description": "the access token only lets the user change its password,\nrefresh it once changed",
+                    "type": "boolean",
+                    "x-order": "7"
+                },
+                "mfaSetupRequired": {
+                    "description": "the access token only lets the user enable two-factor authentication,\nrefresh it once enabled",
+                    "type": "boolean",
+                    "x-order": "8"
+                }
+            }
+        },
+        "schema.TrashItem": {
+            "type": "object",
+            "properties": {
+                "type": {
                     "type": "string",
+                    "enum": [
+                        "ingredient",
+                        "recipe",
+                        "user"
+                    ],
+                    "x-order": "1"
+                },
+                "id": {
+                    "type": "integer",
                     "x-order": "2"
                 },
-                "ingredients": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/schema.Ingredient"
-                    },
+                "name": {
+                    "type": "string",
                     "x-order": "3"
+                },
+                "deletedAt": {
+                    "type": "string",
+                    "x-order": "4"
                 }
             }
         },
-        "schema.RecipesResponse": {
+        "schema.TrashResponse": {
             "type": "object",
             "properties": {
                 "count": {
                     "type": "integer"
                 },
-                "recipes": {
+                "items": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/model.Recipe"
+                        "$ref": "#/definitions/schema.TrashItem"
                     }
                 }
             }
         },
         "schema.User": {
             "type": "object",
+            "required": [
+                "password",
+                "username"
+            ],
             "properties": {
                 "username": {
                     "type": "string",
+                    "minLength": 3,
                     "x-order": "1"
                 },
                 "password": {
                     "type": "string",
                     "x-order": "2"
                 },
-                "admin": {
-                    "type": "boolean",
+                "roles": {
+                    "description": "reader by default",
+                    "type": "array",
+                    "uniqueItems": true,
+                    "items": {
+                        "enum": [
+                            "admin",
+                            "editor",
+                            "contributor",
+                            "reader"
+                        ],
+                        "$ref": "#/definitions/model.Role"
+                    },
+                    "x-order": "3"
+                }
+            }
+        },
+        "schema.UserRoles": {
+            "type": "object",
+            "properties": {
+                "roles": {
+                    "type": "array",
+                    "minItems": 1,
+                    "uniqueItems": true,
+                    "items": {
+                        "enum": [
+                            "admin",
+                            "editor",
+                            "contributor",
+                            "reader"
+                        ],
+                        "$ref": "#/definitions/model.Role"
+                    }
+                }
+            }
+        },
+        "schema.UserUpdate": {
+            "type": "object",
+            "properties": {
+                "roles": {
+                    "type": "array",
+                    "uniqueItems": true,
+                    "items": {
+                        "enum": [
+                            "admin",
+                            "editor",
+                            "contributor",
+                            "reader"
+                        ],
+                        "$ref": "#/definitions/model.Role"
+                    }
+                }
+            }
+        },
+        "schema.UsersResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "description": "users of the page",
+                    "type": "integer",
+                    "x-order": "1"
+                },
+                "total": {
+                    "description": "matching users",
+                    "type": "integer",
+                    "x-order": "2"
+                },
+                "page": {
+                    "type": "integer",
                     "x-order": "3"
+                },
+                "limit": {
+                    "type": "integer",
+                    "x-order": "4"
+                },
+                "users": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.User"
+                    },
+                    "x-order": "5"
                 }
             }
         }
     },
     "securityDefinitions": {
+        "Bearer": {
+            "description": "Access token given by POST /login?mode=token, as \"Bearer \u003ctoken\u003e\".",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        },
         "JWT": {
+            "description": "Access token set by POST /login.",
             "type": "apiKey",
             "name": "Auth",
             "in": "cookie"
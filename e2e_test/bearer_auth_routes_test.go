@@ -0,0 +1,74 @@
+package e2etest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/denisyao1/welsh-academy-api/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBearerAuth(t *testing.T) {
+	assert := assert.New(t)
+
+	credentials := []byte(`{"username":"admin", "password": "admin"}`)
+
+	// invalid mode
+	req := httptest.NewRequest(PostMethod, BaseUrl+"/login?mode=header", bytes.NewBuffer(credentials))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := App.Test(req, -1)
+	assert.Equal(BadRequest, resp.StatusCode, "unknown login mode, should return Bad Request")
+
+	// token mode
+	req = httptest.NewRequest(PostMethod, BaseUrl+"/login?mode=token", bytes.NewBuffer(credentials))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ = App.Test(req, -1)
+	if !assert.Equal(OK, resp.StatusCode, "token login, should return OK") {
+		t.FailNow()
+	}
+	assert.Empty(resp.Cookies(), "token login, should not set cookie")
+
+	var token schema.Token
+	data, _ := io.ReadAll(resp.Body)
+	json.Unmarshal(data, &token)
+	assert.NotEmpty(token.AccessToken)
+	assert.Equal("Bearer", token.TokenType)
+	assert.Equal(Config.ACCESS_TOKEN_MINUTES*60, token.ExpiresIn)
+
+	testCases := []struct {
+		authorization string
+		statusCode    int
+		description   string
+	}{
+		{
+			authorization: "Bearer " + token.AccessToken,
+			statusCode:    OK,
+			description:   "bearer token, should return OK",
+		},
+		{
+			authorization: "bearer " + token.AccessToken,
+			statusCode:    OK,
+			description:   "case insensitive scheme, should return OK",
+		},
+		{
+			authorization: "Basic " + token.AccessToken,
+			statusCode:    Unauthorized,
+			description:   "other scheme, should return Unauthorized",
+		},
+		{
+			authorization: "Bearer invalid",
+			statusCode:    Unauthorized,
+			description:   "invalid token, should return Unauthorized",
+		},
+	}
+
+	for _, tc := range testCases {
+		req := httptest.NewRequest(GetMethod, BaseUrl+"/users/my-infos", nil)
+		req.Header.Set("Authorization", tc.authorization)
+		resp, _ := App.Test(req, -1)
+		assert.Equal(tc.statusCode, resp.StatusCode, tc.description)
+	}
+}
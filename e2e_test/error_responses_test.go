@@ -0,0 +1,92 @@
+package e2etest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/denisyao1/welsh-academy-api/controller"
+	"github.com/denisyao1/welsh-academy-api/exception"
+	"github.com/denisyao1/welsh-academy-api/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorResponses(t *testing.T) {
+	assert := assert.New(t)
+
+	code, authCookie := login("admin", "admin")
+	if code != 200 {
+		t.Log("Auth failed")
+		t.FailNow()
+	}
+
+	testCases := []struct {
+		method         string
+		url            string
+		body           string
+		auth           bool
+		acceptLanguage string
+		statusCode     int
+		code           exception.Code
+		locale         string
+		message        string
+		description    string
+	}{
+		{PostMethod, "/login", `{"username":"admin","password":"wrong"}`, false, "",
+			Unauthorized, exception.CodeInvalidCredentials, "en", "Invalid credentials.",
+			"invalid credentials, should be in english by default"},
+		{PostMethod, "/login", `{"username":"admin","password":"wrong"}`, false, "cy-GB,en;q=0.8",
+			Unauthorized, exception.CodeInvalidCredentials, "cy", "Manylion mewngofnodi annilys.",
+			"invalid credentials, should be in welsh"},
+		{GetMethod, "/ingredients?lang=cy", "", false, "",
+			Unauthorized, exception.CodeMalformedToken, "cy", "Tocyn ar goll neu wedi'i ffurfio'n wael.",
+			"missing token, should be in welsh"},
+		{GetMethod, "/recipes/abc", "", true, "cy",
+			BadRequest, exception.CodeInvalidID, "cy", "Rhaid i'r id fod yn gyfanrif.",
+			"invalid id, should be in welsh"},
+		{GetMethod, "/recipes/100000", "", true, "fr",
+			NotFound, exception.CodeRecipeNotFound, "en", "Recipe not found.",
+			"unsupported language, should fall back to english"},
+		{PostMethod, "/ingredients", `{"name":""}`, true, "cy",
+			BadRequest, exception.CodeInvalidRequest, "cy", "Mae'r cais yn cynnwys meysydd annilys.",
+			"invalid fields, should be in welsh"},
+		{GetMethod, "/unknown", "", true, "",
+			NotFound, exception.CodeRouteNotFound, "en", "No route matches the request.",
+			"unknown route, should return not found"},
+	}
+
+	for _, tc := range testCases {
+		req := httptest.NewRequest(tc.method, BaseUrl+tc.url, bytes.NewBufferString(tc.body))
+		req.Header.Set("Content-Type", "application/json")
+		if tc.acceptLanguage != "" {
+			req.Header.Set("Accept-Language", tc.acceptLanguage)
+		}
+		if tc.auth {
+			req.AddCookie(authCookie)
+		}
+		resp, _ := App.Test(req, -1)
+		assert.Equal(tc.statusCode, resp.StatusCode, tc.description)
+		assert.Equal(tc.locale, resp.Header.Get("Content-Language"), tc.description)
+
+		assert.Equal(controller.MIMEApplicationProblemJSON, resp.Header.Get("Content-Type"), tc.description)
+
+		var problem schema.Problem
+		data, _ := io.ReadAll(resp.Body)
+		json.Unmarshal(data, &problem)
+		assert.Equal(tc.code, problem.Code, tc.description)
+		assert.Equal("urn:welsh-academy:problem:"+string(tc.code), problem.Type, tc.description)
+		assert.Equal(tc.statusCode, problem.Status, tc.description)
+		assert.Equal(tc.message, problem.Detail, tc.description)
+		assert.Equal(BaseUrl+tc.url, problem.Instance, tc.description)
+		assert.Empty(problem.CorrelationID, tc.description)
+
+		// field errors are localized too
+		if tc.code == exception.CodeInvalidRequest && assert.Len(problem.Errors, 1, tc.description) {
+			assert.Equal("name", problem.Errors[0].Field, tc.description)
+			assert.Equal(exception.CodeRequired, problem.Errors[0].Code, tc.description)
+			assert.Equal("Mae angen y maes hwn.", problem.Errors[0].Message, tc.description)
+		}
+	}
+}
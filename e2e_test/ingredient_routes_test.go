@@ -18,7 +18,7 @@ func TestIngredientCreate(t *testing.T) {
 	assert := assert.New(t)
 
 	// create admin user if not exist
-	userService.CreateIfNotExist(&model.User{Username: "admin", Password: "admin", IsAdmin: true})
+	userService.CreateIfNotExist(&model.User{Username: "admin", Password: "admin", Roles: []model.UserRole{{Role: model.RoleAdmin}}})
 
 	// login admin
 	code, authCookie := login("admin", "admin")
@@ -49,6 +49,17 @@ func TestIngredientCreate(t *testing.T) {
 			numberInDB:  1,
 			description: "alredy used name, should return conflict",
 		},
+		{
+			name:        " Ingredient1  ",
+			statusCode:  409,
+			numberInDB:  1,
+			description: "name only differing by case and spaces, should return conflict",
+		},
+		{
+			name:        "   ",
+			statusCode:  400,
+			description: "blank name, should be bad request",
+		},
 		{
 			name:        "ingredient2",
 			statusCode:  201,
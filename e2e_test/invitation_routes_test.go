@@ -0,0 +1,83 @@
+package e2etest
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/denisyao1/welsh-academy-api/model"
+	"github.com/denisyao1/welsh-academy-api/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+// register creates an account with an invitation code and returns the response status code.
+func register(code, username, password string) int {
+	body := fmt.Sprintf(`{"invitationCode":"%s", "username":"%s", "password":"%s"}`, code, username, password)
+	status, _ := bearerRequest(PostMethod, "/register", body, "")
+	return status
+}
+
+func TestInvitations(t *testing.T) {
+	assert := assert.New(t)
+
+	admin := loginForToken("admin", "admin", "invitations")
+	if admin.AccessToken == "" {
+		t.Log("Auth failed")
+		t.FailNow()
+	}
+
+	code, _ := bearerRequest(PostMethod, "/invitations", `{"role":"owner"}`, admin.AccessToken)
+	assert.Equal(BadRequest, code, "invitation with unknown role, should return Bad Request")
+
+	code, data := bearerRequest(PostMethod, "/invitations", `{"role":"contributor","maxUses":2}`, admin.AccessToken)
+	if !assert.Equal(Created, code, "create invitation, should return Created") {
+		t.FailNow()
+	}
+	var invitation model.Invitation
+	json.Unmarshal(data, &invitation)
+	assert.NotEmpty(invitation.Code, "create invitation, should return its code")
+
+	// registration
+	assert.Equal(BadRequest, register("unknown", "invitedUser1", "Invited-1"), "unknown code, should return Bad Request")
+	assert.Equal(BadRequest, register(invitation.Code, "invitedUser1", "short"), "weak password, should return Bad Request")
+	assert.Equal(Created, register(invitation.Code, "invitedUser1", "Invited-1"), "register, should return Created")
+	assert.Equal(Conflict, register(invitation.Code, "invitedUser1", "Invited-1"), "used username, should return Conflict")
+	assert.Equal(Created, register(invitation.Code, "invitedUser2", "Invited-2"), "register with second use, should return Created")
+	assert.Equal(BadRequest, register(invitation.Code, "invitedUser3", "Invited-3"), "used up code, should return Bad Request")
+
+	invited := loginForToken("invitedUser1", "Invited-1", "invitations")
+	code, data = bearerRequest(GetMethod, "/users/my-infos", "", invited.AccessToken)
+	assert.Equal(OK, code, "login of registered user, should return OK")
+	var user model.User
+	json.Unmarshal(data, &user)
+	assert.Equal([]model.Role{model.RoleContributor}, user.RoleNames(), "registered user, should get the role of the invitation")
+
+	// admins see who used the invitations
+	code, data = bearerRequest(GetMethod, "/invitations", "", admin.AccessToken)
+	assert.Equal(OK, code, "list invitations, should return OK")
+	var invitations schema.InvitationsResponse
+	json.Unmarshal(data, &invitations)
+	if assert.NotEmpty(invitations.Invitations) {
+		listed := invitations.Invitations[0]
+		assert.Empty(listed.Code, "list invitations, should not return the codes")
+		assert.Equal(2, listed.UseCount)
+		var usernames []string
+		for _, use := range listed.Uses {
+			usernames = append(usernames, use.Username)
+		}
+		assert.Equal([]string{"invitedUser1", "invitedUser2"}, usernames, "list invitations, should tell who used them")
+	}
+
+	// revocation
+	code, data = bearerRequest(PostMethod, "/invitations", `{}`, admin.AccessToken)
+	assert.Equal(Created, code, "create invitation with defaults, should return Created")
+	json.Unmarshal(data, &invitation)
+	code, _ = bearerRequest(DeleteMethod, fmt.Sprintf("/invitations/%d", invitation.ID), "", admin.AccessToken)
+	assert.Equal(OK, code, "revoke invitation, should return OK")
+	code, _ = bearerRequest(DeleteMethod, fmt.Sprintf("/invitations/%d", invitation.ID), "", admin.AccessToken)
+	assert.Equal(NotFound, code, "revoke revoked invitation, should return Not Found")
+	assert.Equal(BadRequest, register(invitation.Code, "invitedUser4", "Invited-4"), "revoked code, should return Bad Request")
+
+	code, _ = bearerRequest(GetMethod, "/invitations", "", invited.AccessToken)
+	assert.Equal(Forbidden, code, "list invitations without permission, should return Forbidden")
+}
@@ -0,0 +1,197 @@
+package e2etest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/denisyao1/welsh-academy-api/model"
+	"github.com/denisyao1/welsh-academy-api/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportJSONLDRecipes(t *testing.T) {
+	assert := assert.New(t)
+
+	// create admin user if not exist
+	userService.CreateIfNotExist(&model.User{Username: "admin", Password: "admin", Roles: []model.UserRole{{Role: model.RoleAdmin}}})
+	cheddar, _ := ingredientRepo.GetOrCreate("Cheddar")
+
+	code, authCookie := login("admin", "admin")
+	if code != 200 {
+		t.Log("Auth failed")
+		t.FailNow()
+	}
+
+	document := `{
+		"@context": "https://schema.org",
+		"@graph": [
+			{"@type": "WebPage", "name": "Our recipes"},
+			{
+				"@type": "Recipe",
+				"name": "Imported Rarebit",
+				"recipeYield": ["4", "4 servings"],
+				"prepTime": "PT10M",
+				"totalTime": "PT25M",
+				"recipeIngredient": ["200g mature Cheddar, grated", "2 tbsp ale", "4 slices of bread"],
+				"recipeInstructions": [
+					{"@type": "HowToStep", "text": "Melt the cheese in the ale."},
+					{"@type": "HowToStep", "text": "Pour over the toasted bread."}
+				]
+			},
+			{"@type": "Recipe", "name": "Imported Nothing", "recipeIngredient": ["salt"]}
+		]
+	}`
+	page := fmt.Sprintf(`<html><head><script type="application/ld+json">%s</script></head></html>`,
+		`{"@context": "https://schema.org", "@type": "Recipe", "name": "Imported Cawl",
+		  "recipeIngredient": ["1kg lamb", "2 leeks"], "recipeInstructions": "Simmer for hours."}`)
+
+	testCases := []struct {
+		body        string
+		contentType string
+		statusCode  int
+		count       int
+		errors      int
+		description string
+	}{
+		{
+			body:        document,
+			contentType: schema.MIMEApplicationLDJSON,
+			statusCode:  Created,
+			count:       1,
+			errors:      1,
+			description: "JSON-LD with a valid and an incomplete recipe, should create one recipe",
+		},
+		{
+			body:        document,
+			contentType: schema.MIMEApplicationLDJSON,
+			statusCode:  BadRequest,
+			count:       0,
+			errors:      2,
+			description: "already imported recipe, should return bad request",
+		},
+		{
+			body:        page,
+			contentType: "text/html",
+			statusCode:  Created,
+			count:       1,
+			description: "HTML embedding JSON-LD, should create one recipe",
+		},
+		{
+			body:        `<html><body>no recipe here</body></html>`,
+			contentType: "text/html",
+			statusCode:  BadRequest,
+			errors:      1,
+			description: "HTML without JSON-LD, should return bad request with the invalid document",
+		},
+	}
+
+	url := BaseUrl + "/recipes/import"
+	for _, tt := range testCases {
+		req := httptest.NewRequest(PostMethod, url, bytes.NewBufferString(tt.body))
+		req.Header.Set("Content-Type", tt.contentType)
+		req.AddCookie(authCookie)
+		resp, _ := App.Test(req, -1)
+		assert.Equal(tt.statusCode, resp.StatusCode, tt.description)
+		body, _ := io.ReadAll(resp.Body)
+		response := schema.RecipeImportResponse{}
+		json.Unmarshal(body, &response)
+		assert.Equal(tt.count, response.Count, tt.description)
+		assert.Equal(tt.errors, len(response.Errors), tt.description)
+	}
+
+	// the existing ingredient is matched and the missing ones are created
+	recipes, _ := recipeRepo.FindAllContainging([]string{"Cheddar"})
+	var rarebit model.Recipe
+	for _, r := range recipes {
+		if r.Name == "Imported Rarebit" {
+			rarebit = r
+		}
+	}
+	assert.NotEqual(0, rarebit.ID, "imported recipe should contain the existing ingredient")
+	assert.Equal(3, len(rarebit.Ingredients), "imported recipe should have 3 ingredients")
+	var names []string
+	for _, ingredient := range rarebit.Ingredients {
+		names = append(names, ingredient.Name)
+	}
+	assert.Equal([]string{cheddar.Name, "ale", "bread"}, names, "Cheddar should be matched")
+	assert.Equal("4 servings", rarebit.Yield)
+	assert.Equal(10, rarebit.PrepTime)
+	assert.Equal(15, rarebit.CookTime)
+	created, _ := ingredientRepo.FindNamed([]string{"ale", "bread", "lamb", "leeks"})
+	assert.Equal(4, len(created), "missing ingredients should be created")
+}
+
+func TestExportJSONLDRecipe(t *testing.T) {
+	assert := assert.New(t)
+
+	ingredient, _ := ingredientRepo.GetOrCreate("ingredientLD")
+	recipe := model.Recipe{
+		Name:        "recipeLD",
+		Making:      "step one\nstep two",
+		Ingredients: []model.Ingredient{ingredient},
+		Yield:       "2 servings",
+		PrepTime:    5,
+		CookTime:    70}
+	recipeRepo.GetOrCreate(&recipe)
+
+	user := model.User{Username: "test", Password: "test"}
+	userService.CreateIfNotExist(&user)
+	code, authCookie := login("test", "test")
+	if code != 200 {
+		t.Log("Auth failed")
+		t.FailNow()
+	}
+
+	testCases := []struct {
+		query       string
+		accept      string
+		contentType string
+		description string
+	}{
+		{
+			contentType: "application/json",
+			description: "no format requested, should return JSON",
+		},
+		{
+			query:       "?format=jsonld",
+			contentType: schema.MIMEApplicationLDJSON,
+			description: "jsonld format query param, should return JSON-LD",
+		},
+		{
+			accept:      schema.MIMEApplicationLDJSON,
+			contentType: schema.MIMEApplicationLDJSON,
+			description: "JSON-LD Accept header, should return JSON-LD",
+		},
+	}
+
+	url := fmt.Sprintf("%s/recipes/%v", BaseUrl, recipe.ID)
+	for _, tt := range testCases {
+		req := httptest.NewRequest(GetMethod, url+tt.query, nil)
+		if tt.accept != "" {
+			req.Header.Set("Accept", tt.accept)
+		}
+		req.AddCookie(authCookie)
+		resp, _ := App.Test(req, -1)
+		assert.Equal(OK, resp.StatusCode, tt.description)
+		assert.Equal(tt.contentType, resp.Header.Get("Content-Type"), tt.description)
+		if tt.contentType != schema.MIMEApplicationLDJSON {
+			continue
+		}
+		body, _ := io.ReadAll(resp.Body)
+		ld := schema.RecipeJSONLD{}
+		json.Unmarshal(body, &ld)
+		assert.Equal("Recipe", ld.Type, tt.description)
+		assert.Equal([]string{"ingredientLD"}, ld.RecipeIngredient, tt.description)
+		assert.Equal(2, len(ld.RecipeInstructions), tt.description)
+		assert.Equal("PT1H15M", ld.TotalTime, tt.description)
+	}
+
+	req := httptest.NewRequest(GetMethod, BaseUrl+"/recipes/0", nil)
+	req.AddCookie(authCookie)
+	resp, _ := App.Test(req, -1)
+	assert.Equal(NotFound, resp.StatusCode, "recipe 0 doesn't exist, should return not found")
+}
@@ -0,0 +1,113 @@
+package e2etest
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/denisyao1/welsh-academy-api/model"
+	"github.com/denisyao1/welsh-academy-api/schema"
+	"github.com/denisyao1/welsh-academy-api/service"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJWKS(t *testing.T) {
+	assert := assert.New(t)
+
+	req := httptest.NewRequest(GetMethod, "/.well-known/jwks.json", nil)
+	resp, _ := App.Test(req, -1)
+	if !assert.Equal(OK, resp.StatusCode, "get jwks, should return OK") {
+		t.FailNow()
+	}
+	assert.NotEmpty(resp.Header.Get("Cache-Control"), "get jwks, should be cacheable")
+
+	var jwks schema.JWKS
+	data, _ := io.ReadAll(resp.Body)
+	json.Unmarshal(data, &jwks)
+	if !assert.Len(jwks.Keys, 2, "get jwks, should publish the signing and previous keys") {
+		t.FailNow()
+	}
+	assert.Equal("OKP", jwks.Keys[0].Kty)
+	assert.Equal("Ed25519", jwks.Keys[0].Crv)
+	assert.Equal("EdDSA", jwks.Keys[0].Alg)
+	assert.Equal("RSA", jwks.Keys[1].Kty)
+	assert.Equal("RS256", jwks.Keys[1].Alg)
+	assert.NotEmpty(jwks.Keys[1].N)
+
+	user := model.User{Username: "jwksUser", Password: "jwks"}
+	if err := userService.CreateIfNotExist(&user); err != nil {
+		t.FailNow()
+	}
+
+	token := loginForToken("jwksUser", "jwks", "jwks")
+	if token.AccessToken == "" {
+		t.Log("Auth failed")
+		t.FailNow()
+	}
+
+	parsed, _, err := jwt.NewParser().ParseUnverified(token.AccessToken, jwt.MapClaims{})
+	if !assert.NoError(err) {
+		t.FailNow()
+	}
+	assert.Equal(jwks.Keys[0].Kid, parsed.Header["kid"], "login, should sign with the published key")
+	assert.Equal("EdDSA", parsed.Header["alg"])
+	claims := parsed.Claims.(jwt.MapClaims)
+
+	previousKeys, _ := service.NewKeyService(PreviousKey)
+	previousToken, _ := previousKeys.Sign(claims)
+
+	_, otherKey, _ := ed25519.GenerateKey(rand.Reader)
+	otherKeys, _ := service.NewKeyService(otherKey)
+	otherToken, _ := otherKeys.Sign(claims)
+
+	// HMAC token using the public key as secret
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	forged.Header["kid"] = jwks.Keys[0].Kid
+	forgedToken, _ := forged.SignedString([]byte(SigningKey.Public().(ed25519.PublicKey)))
+
+	neverExpiring := jwt.MapClaims{"ID": claims["ID"], "role": claims["role"], "jti": "never", "sid": claims["sid"]}
+	noExpiry := jwt.NewWithClaims(jwt.SigningMethodEdDSA, neverExpiring)
+	noExpiry.Header["kid"] = jwks.Keys[0].Kid
+	neverExpiringToken, _ := noExpiry.SignedString(SigningKey)
+
+	testCases := []struct {
+		accessToken string
+		statusCode  int
+		description string
+	}{
+		{
+			accessToken: token.AccessToken,
+			statusCode:  OK,
+			description: "token of the signing key, should return OK",
+		},
+		{
+			accessToken: previousToken,
+			statusCode:  OK,
+			description: "token of the previous key, should return OK",
+		},
+		{
+			accessToken: otherToken,
+			statusCode:  Unauthorized,
+			description: "token of an unknown key, should return Unauthorized",
+		},
+		{
+			accessToken: forgedToken,
+			statusCode:  Unauthorized,
+			description: "token with another algorithm than the key, should return Unauthorized",
+		},
+		{
+			accessToken: neverExpiringToken,
+			statusCode:  Unauthorized,
+			description: "token without expiry, should return Unauthorized",
+		},
+	}
+
+	for _, tc := range testCases {
+		code, _ := bearerRequest(GetMethod, "/users/my-infos", "", tc.accessToken)
+		assert.Equal(tc.statusCode, code, tc.description)
+	}
+}
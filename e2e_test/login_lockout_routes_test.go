@@ -0,0 +1,135 @@
+package e2etest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/denisyao1/welsh-academy-api/database"
+	"github.com/denisyao1/welsh-academy-api/exception"
+	"github.com/denisyao1/welsh-academy-api/model"
+	"github.com/denisyao1/welsh-academy-api/repository"
+	"github.com/denisyao1/welsh-academy-api/service"
+	"github.com/stretchr/testify/assert"
+)
+
+// loginWithRetry logs in and returns the response status code and Retry-After header.
+func loginWithRetry(username, password string) (int, string) {
+	inputs := fmt.Sprintf(`{"username":"%s", "password": "%s"}`, username, password)
+	req := httptest.NewRequest(PostMethod, BaseUrl+"/login", bytes.NewBufferString(inputs))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := App.Test(req, -1)
+	return resp.StatusCode, resp.Header.Get("Retry-After")
+}
+
+func TestLoginLockout(t *testing.T) {
+	assert := assert.New(t)
+
+	user := model.User{Username: "lockoutUser", Password: "Lock-out1"}
+	if err := userService.CreateIfNotExist(&user); err != nil {
+		t.FailNow()
+	}
+
+	for _, username := range []string{"lockoutUser", "lockoutGhost"} {
+		for i := 0; i < Config.LOGIN_MAX_FAILURES; i++ {
+			code, _ := loginWithRetry(username, "wrong")
+			assert.Equal(Unauthorized, code, "failed login, should return Unauthorized")
+		}
+
+		code, retryAfter := loginWithRetry(username, "Lock-out1")
+		assert.Equal(TooManyRequests, code, "login after too many failures, should return Too Many Requests")
+		seconds, _ := strconv.Atoi(retryAfter)
+		assert.InDelta(Config.LOGIN_LOCKOUT_MINUTES*60, seconds, 5, "locked out login, should tell when to retry")
+	}
+
+	admin := loginForToken("admin", "admin", "lockout")
+	code, _ := bearerRequest(PostMethod, fmt.Sprintf("/users/%d/unlock", user.ID), "", admin.AccessToken)
+	assert.Equal(OK, code, "unlock user, should return OK")
+	code, _ = bearerRequest(PostMethod, "/users/100000/unlock", "", admin.AccessToken)
+	assert.Equal(NotFound, code, "unlock unknown user, should return Not Found")
+
+	code, _ = loginWithRetry("lockoutUser", "Lock-out1")
+	assert.Equal(OK, code, "login after unlock, should return OK")
+}
+
+func TestLoginThrottle(t *testing.T) {
+	assert := assert.New(t)
+
+	db, _ := database.NewInMemoryDB(false)
+	db.Migrate(model.LoginFailure{})
+	backoff, lockout := 50*time.Millisecond, time.Minute
+	throttle := service.NewLoginThrottle(repository.NewGormLoginFailureRepository(db.GetDB()), 3, 4, backoff, lockout)
+
+	retryAfter := func(username, ip string) time.Duration {
+		var errCode *exception.Error
+		if err := throttle.Check(username, ip); errors.As(err, &errCode) {
+			return errCode.RetryAfter
+		}
+		return 0
+	}
+
+	assert.Zero(retryAfter("user", "10.0.0.1"), "no failure, should not wait")
+
+	// backoff doubles with each failure
+	throttle.Fail("user", "10.0.0.1")
+	assert.InDelta(backoff, retryAfter("User ", "10.0.0.2"), float64(backoff/2), "first failure, should wait the backoff")
+	time.Sleep(backoff)
+	assert.Zero(retryAfter("user", "10.0.0.2"), "after the backoff, should not wait")
+	throttle.Fail("user", "10.0.0.1")
+	assert.InDelta(2*backoff, retryAfter("user", "10.0.0.2"), float64(backoff/2), "second failure, should wait twice as long")
+
+	// lockout
+	throttle.Fail("user", "10.0.0.1")
+	assert.InDelta(lockout, retryAfter("user", "10.0.0.2"), float64(time.Second), "too many failures, should lock out the username")
+	throttle.Unlock("user")
+	assert.Zero(retryAfter("user", "10.0.0.2"), "unlocked username, should not wait")
+
+	// failures of several usernames from an IP address add up
+	throttle.Fail("other", "10.0.0.1")
+	assert.InDelta(lockout, retryAfter("another", "10.0.0.1"), float64(time.Second), "too many failures from an IP address, should lock it out")
+	throttle.Succeed("other", "10.0.0.1")
+	assert.Zero(retryAfter("other", "10.0.0.2"), "successful login, should forget the failures of the username")
+}
+
+func TestLoginThrottleConcurrentAttempts(t *testing.T) {
+	assert := assert.New(t)
+
+	db, _ := database.NewInMemoryDB(false)
+	db.Migrate(model.LoginFailure{})
+	throttle := service.NewLoginThrottle(repository.NewGormLoginFailureRepository(db.GetDB()), 3, 4, 0, time.Minute)
+
+	// attempts checked together, before any of them fails
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var ips []string
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(ip string) {
+			defer wg.Done()
+			if throttle.Check("user", ip) == nil {
+				mu.Lock()
+				ips = append(ips, ip)
+				mu.Unlock()
+			}
+		}(fmt.Sprintf("10.0.0.%d", i))
+	}
+	wg.Wait()
+	assert.Len(ips, 3, "concurrent attempts, should not exceed the failures locking out the username")
+
+	// released attempts can be made again
+	throttle.Release("user", ips[0])
+	assert.NoError(throttle.Check("user", ips[0]), "released attempt, should not wait")
+
+	for _, ip := range ips {
+		throttle.Fail("user", ip)
+	}
+	var errCode *exception.Error
+	if assert.ErrorAs(throttle.Check("user", "10.0.0.100"), &errCode, "failed attempts, should lock out the username") {
+		assert.InDelta(time.Minute, errCode.RetryAfter, float64(time.Second), "failed attempts, should lock out the username")
+	}
+}
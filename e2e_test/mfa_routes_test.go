@@ -0,0 +1,165 @@
+package e2etest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/denisyao1/welsh-academy-api/database"
+	"github.com/denisyao1/welsh-academy-api/exception"
+	"github.com/denisyao1/welsh-academy-api/model"
+	"github.com/denisyao1/welsh-academy-api/repository"
+	"github.com/denisyao1/welsh-academy-api/schema"
+	"github.com/denisyao1/welsh-academy-api/service"
+	"github.com/pquerna/otp/totp"
+	"github.com/stretchr/testify/assert"
+)
+
+// loginForChallenge logs in a user with two-factor authentication and returns its challenge.
+func loginForChallenge(username, password string) (int, schema.MFAChallenge) {
+	body := fmt.Sprintf(`{"username":"%s", "password": "%s"}`, username, password)
+	code, data := bearerRequest(PostMethod, "/login?mode=token", body, "")
+	var challenge schema.MFAChallenge
+	json.Unmarshal(data, &challenge)
+	return code, challenge
+}
+
+// loginMFA completes a challenge with a code and returns the response status code and tokens.
+func loginMFA(challengeToken, otp string) (int, schema.Token) {
+	body := fmt.Sprintf(`{"challengeToken":"%s", "code": "%s"}`, challengeToken, otp)
+	code, data := bearerRequest(PostMethod, "/login/mfa?mode=token", body, "")
+	var token schema.Token
+	json.Unmarshal(data, &token)
+	return code, token
+}
+
+func TestTOTP(t *testing.T) {
+	assert := assert.New(t)
+
+	user := model.User{Username: "totpUser", Password: "Totp-user1"}
+	if err := userService.CreateIfNotExist(&user); err != nil {
+		t.FailNow()
+	}
+	token := loginForToken("totpUser", "Totp-user1", "totp")
+	if token.AccessToken == "" {
+		t.Log("Auth failed")
+		t.FailNow()
+	}
+
+	code, _ := bearerRequest(PostMethod, "/users/me/mfa/totp/verify", `{"code":"123456"}`, token.AccessToken)
+	assert.Equal(Conflict, code, "verify before enrolment, should return Conflict")
+
+	// enrolment
+	code, _ = bearerRequest(PostMethod, "/users/me/mfa/totp", "", token.AccessToken)
+	assert.Equal(BadRequest, code, "enrol without password, should return Bad Request")
+	code, _ = bearerRequest(PostMethod, "/users/me/mfa/totp", `{"currentPassword":"wrong"}`, token.AccessToken)
+	assert.Equal(BadRequest, code, "enrol with wrong password, should return Bad Request")
+
+	code, data := bearerRequest(PostMethod, "/users/me/mfa/totp", `{"currentPassword":"Totp-user1"}`, token.AccessToken)
+	if !assert.Equal(OK, code, "enrol, should return OK") {
+		t.FailNow()
+	}
+	var enrolment schema.TOTPEnrolment
+	json.Unmarshal(data, &enrolment)
+	assert.True(strings.HasPrefix(enrolment.URI, "otpauth://totp/"), "enrol, should return an otpauth URI")
+	assert.True(strings.HasPrefix(enrolment.QRCode, "data:image/png;base64,"), "enrol, should return a QR code")
+
+	code, _ = bearerRequest(PostMethod, "/users/me/mfa/totp/verify", `{"code":"abcdef"}`, token.AccessToken)
+	assert.Equal(BadRequest, code, "verify wrong code, should return Bad Request")
+
+	otp, _ := totp.GenerateCode(enrolment.Secret, time.Now())
+	code, data = bearerRequest(PostMethod, "/users/me/mfa/totp/verify", fmt.Sprintf(`{"code":"%s"}`, otp), token.AccessToken)
+	if !assert.Equal(OK, code, "verify code, should return OK") {
+		t.FailNow()
+	}
+	var recovery schema.RecoveryCodes
+	json.Unmarshal(data, &recovery)
+	assert.Len(recovery.Codes, 10, "verify code, should return recovery codes")
+
+	code, _ = bearerRequest(PostMethod, "/users/me/mfa/totp", `{"currentPassword":"Totp-user1"}`, token.AccessToken)
+	assert.Equal(Conflict, code, "enrol when enabled, should return Conflict")
+
+	// two-step login
+	code, challenge := loginForChallenge("totpUser", "Totp-user1")
+	if !assert.Equal(Accepted, code, "login with two-factor authentication, should return Accepted") {
+		t.FailNow()
+	}
+	assert.NotEmpty(challenge.ChallengeToken, "login with two-factor authentication, should return a challenge")
+
+	code, _ = loginMFA("unknown", otp)
+	assert.Equal(Unauthorized, code, "unknown challenge, should return Unauthorized")
+	code, _ = loginMFA(challenge.ChallengeToken, otp)
+	assert.Equal(Unauthorized, code, "code already used, should return Unauthorized")
+
+	// the code of the next time step is accepted
+	otp, _ = totp.GenerateCode(enrolment.Secret, time.Now().Add(30*time.Second))
+	code, mfaToken := loginMFA(challenge.ChallengeToken, otp)
+	assert.Equal(OK, code, "login with code, should return OK")
+	code, _ = bearerRequest(GetMethod, "/users/my-infos", "", mfaToken.AccessToken)
+	assert.Equal(OK, code, "request after two-factor login, should return OK")
+	code, _ = loginMFA(challenge.ChallengeToken, recovery.Codes[0])
+	assert.Equal(Unauthorized, code, "completed challenge, should return Unauthorized")
+
+	// recovery codes work once
+	_, challenge = loginForChallenge("totpUser", "Totp-user1")
+	code, _ = loginMFA(challenge.ChallengeToken, recovery.Codes[0])
+	assert.Equal(OK, code, "login with recovery code, should return OK")
+	_, challenge = loginForChallenge("totpUser", "Totp-user1")
+	code, _ = loginMFA(challenge.ChallengeToken, recovery.Codes[0])
+	assert.Equal(Unauthorized, code, "login with used recovery code, should return Unauthorized")
+
+	// disabling
+	code, _ = bearerRequest(DeleteMethod, "/users/me/mfa/totp", `{"code":"abcdef"}`, mfaToken.AccessToken)
+	assert.Equal(BadRequest, code, "disable with wrong code, should return Bad Request")
+	body := fmt.Sprintf(`{"code":"%s"}`, recovery.Codes[1])
+	code, _ = bearerRequest(DeleteMethod, "/users/me/mfa/totp", body, mfaToken.AccessToken)
+	assert.Equal(OK, code, "disable with recovery code, should return OK")
+	code, _ = login("totpUser", "Totp-user1")
+	assert.Equal(OK, code, "login after disabling, should return OK")
+}
+
+func TestTOTPRequiredForAdmins(t *testing.T) {
+	assert := assert.New(t)
+
+	db, _ := database.NewInMemoryDB(false)
+	db.MigrateAll()
+	repo := repository.NewUserRepository(db.GetDB())
+	keys, _ := service.NewKeyService(SigningKey)
+	passwords, _ := service.NewPasswordPolicy(Config.PASSWORD_MIN_LENGTH, Config.PASSWORD_MIN_CHAR_CLASSES, Config.BCRYPT_COST)
+	throttle := service.NewLoginThrottle(repository.NewGormLoginFailureRepository(db.GetDB()), 5, 5, 0, time.Minute)
+	revocations := service.NewRevocationService(repository.NewGormRevocationRepository(db.GetDB()))
+	mfaService := service.NewUserService(repo, repository.NewGormSessionRepository(db.GetDB()),
+		repository.NewGormRefreshTokenRepository(db.GetDB()), revocations, service.NewUserStatusService(repo), keys, passwords, throttle,
+		repository.NewGormMFARepository(db.GetDB()), true, time.Minute, time.Hour)
+
+	admin := model.User{Username: "mfaAdmin", Password: "Mfa-admin1", Roles: []model.UserRole{{Role: model.RoleAdmin}}}
+	if err := mfaService.CreateIfNotExist(&admin); err != nil {
+		t.FailNow()
+	}
+
+	token, _, err := mfaService.CreateTokens(schema.Login{Username: "mfaAdmin", Password: "Mfa-admin1"}, "", "")
+	if !assert.NoError(err) {
+		t.FailNow()
+	}
+	assert.True(token.MFASetupRequired, "admin login, should require two-factor authentication")
+	code, _ := bearerRequest(GetMethod, "/users/my-infos", "", token.AccessToken)
+	assert.Equal(Forbidden, code, "request before enabling two-factor authentication, should return Forbidden")
+
+	enrolment, _ := mfaService.EnrolTOTP(admin.ID, schema.PasswordConfirmation{CurrentPassword: "Mfa-admin1"})
+	otp, _ := totp.GenerateCode(enrolment.Secret, time.Now())
+	_, err = mfaService.EnableTOTP(admin.ID, schema.TOTPCode{Code: otp})
+	assert.NoError(err, "enable two-factor authentication, should succeed")
+
+	token, _ = mfaService.RefreshTokens(token.RefreshToken, "")
+	assert.False(token.MFASetupRequired, "refresh after enabling two-factor authentication, should lift the restriction")
+
+	var errCode *exception.Error
+	otp, _ = totp.GenerateCode(enrolment.Secret, time.Now().Add(30*time.Second))
+	err = mfaService.DisableTOTP(admin.ID, schema.TOTPCode{Code: otp})
+	if assert.True(errors.As(err, &errCode)) {
+		assert.Equal(exception.CodeMFAMandatory, errCode.Code, "admin disabling mandatory two-factor authentication, should fail")
+	}
+}
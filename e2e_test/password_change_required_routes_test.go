@@ -0,0 +1,129 @@
+package e2etest
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/denisyao1/welsh-academy-api/database"
+	"github.com/denisyao1/welsh-academy-api/model"
+	"github.com/denisyao1/welsh-academy-api/repository"
+	"github.com/denisyao1/welsh-academy-api/schema"
+	"github.com/denisyao1/welsh-academy-api/service"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestPasswordChangeRequired(t *testing.T) {
+	assert := assert.New(t)
+
+	user := model.User{Username: "mustChange", Password: "mustChange"}
+	if err := userService.CreateIfNotExist(&user); err != nil {
+		t.FailNow()
+	}
+
+	admin := loginForToken("admin", "admin", "password change required")
+	code, data := bearerRequest(PostMethod, fmt.Sprintf("/users/%d/reset-password", user.ID), "", admin.AccessToken)
+	if !assert.Equal(OK, code, "reset password, should return OK") {
+		t.FailNow()
+	}
+	var temporary schema.TemporaryPassword
+	json.Unmarshal(data, &temporary)
+
+	token := loginForToken("mustChange", temporary.Password, "password change required")
+	if token.AccessToken == "" {
+		t.Log("Auth failed")
+		t.FailNow()
+	}
+	assert.True(token.MustChangePassword, "login with temporary password, should require password change")
+
+	code, _ = bearerRequest(GetMethod, "/users/my-infos", "", token.AccessToken)
+	assert.Equal(Forbidden, code, "request before password change, should return Forbidden")
+
+	body := fmt.Sprintf(`{"currentPassword":"%s","password":"Changed-it"}`, temporary.Password)
+	code, data = bearerRequest(PatchMethod, "/users/password-change?mode=token", body, token.AccessToken)
+	if !assert.Equal(OK, code, "password change, should return OK") {
+		t.FailNow()
+	}
+	var renewed schema.Token
+	json.Unmarshal(data, &renewed)
+	assert.False(renewed.MustChangePassword, "password change, should return tokens without restriction")
+
+	code, _ = bearerRequest(GetMethod, "/users/my-infos", "", renewed.AccessToken)
+	assert.Equal(OK, code, "request after password change, should return OK")
+
+	code, _ = refresh(token.RefreshToken, false)
+	assert.Equal(Unauthorized, code, "refresh token replaced on password change, should be rejected")
+	code, _ = refresh(renewed.RefreshToken, false)
+	assert.Equal(OK, code, "new refresh token, should return OK")
+}
+
+func TestBootstrap(t *testing.T) {
+	assert := assert.New(t)
+
+	newUserService := func() (service.UserService, repository.UserRepository) {
+		db, _ := database.NewInMemoryDB(false)
+		db.Migrate(model.User{}, model.UserRole{})
+		repo := repository.NewUserRepository(db.GetDB())
+		keys, _ := service.NewKeyService(SigningKey)
+		passwords, _ := service.NewPasswordPolicy(Config.PASSWORD_MIN_LENGTH, Config.PASSWORD_MIN_CHAR_CLASSES, Config.BCRYPT_COST)
+		return service.NewUserService(repo, nil, nil, nil, service.NewUserStatusService(repo), keys, passwords, nil, nil, false, 0, 0), repo
+	}
+
+	// generated password
+	bootstrapService, repo := newUserService()
+	if !assert.NoError(bootstrapService.Bootstrap("root", "")) {
+		t.FailNow()
+	}
+	root := model.User{Username: "root"}
+	if !assert.NoError(repo.GetByUsername(&root), "bootstrap, should create the admin") {
+		t.FailNow()
+	}
+	assert.True(root.HasRole(model.RoleAdmin), "bootstrap, should create an admin")
+	assert.True(root.MustChangePassword, "bootstrap, should require password change")
+
+	// the admin is only created once
+	assert.NoError(bootstrapService.Bootstrap("other", "other"))
+	count, _ := repo.CountWithRole(model.RoleAdmin)
+	assert.Equal(int64(1), count, "bootstrap with an admin, should not create another one")
+
+	// configured password
+	bootstrapService, repo = newUserService()
+	assert.NoError(bootstrapService.Bootstrap("admin", "configured"))
+	configured := model.User{Username: "admin"}
+	repo.GetByUsername(&configured)
+	assert.NoError(bcrypt.CompareHashAndPassword([]byte(configured.Password), []byte("configured")),
+		"bootstrap with password, should use it")
+
+	// former default admin
+	bootstrapService, repo = newUserService()
+	legacy := model.User{Username: "admin", Password: "admin", Roles: []model.UserRole{{Role: model.RoleAdmin}}}
+	bootstrapService.CreateIfNotExist(&legacy)
+	assert.NoError(bootstrapService.Bootstrap("admin", ""))
+	legacy = model.User{Username: "admin"}
+	repo.GetByUsername(&legacy)
+	assert.True(legacy.MustChangePassword, "admin with default password, should require password change")
+
+	// username already used by a user who is not an admin
+	bootstrapService, _ = newUserService()
+	bootstrapService.CreateIfNotExist(&model.User{Username: "admin", Password: "reader"})
+	assert.Error(bootstrapService.Bootstrap("admin", ""), "bootstrap with used username, should fail")
+
+	// username held by a user in the trash
+	bootstrapService, repo = newUserService()
+	trashed := model.User{Username: "Admin", Password: "reader"}
+	bootstrapService.CreateIfNotExist(&trashed)
+	repo.Delete(trashed.ID)
+	if !assert.NoError(bootstrapService.Bootstrap("admin", "configured"), "bootstrap with trashed username, should succeed") {
+		t.FailNow()
+	}
+	restored := model.User{Username: "admin"}
+	if !assert.NoError(repo.GetByUsername(&restored), "bootstrap, should restore the trashed user") {
+		t.FailNow()
+	}
+	assert.Equal(trashed.ID, restored.ID, "bootstrap, should restore the trashed user")
+	assert.True(restored.HasRole(model.RoleAdmin), "bootstrap, should make the restored user an admin")
+	assert.True(restored.MustChangePassword, "bootstrap, should require password change")
+	assert.NoError(bcrypt.CompareHashAndPassword([]byte(restored.Password), []byte("configured")),
+		"bootstrap with password, should set it to the restored user")
+}
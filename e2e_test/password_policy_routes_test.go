@@ -0,0 +1,57 @@
+package e2etest
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/denisyao1/welsh-academy-api/exception"
+	"github.com/denisyao1/welsh-academy-api/model"
+	"github.com/denisyao1/welsh-academy-api/schema"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestPasswordPolicy(t *testing.T) {
+	assert := assert.New(t)
+
+	admin := loginForToken("admin", "admin", "password policy")
+	if admin.AccessToken == "" {
+		t.Log("Auth failed")
+		t.FailNow()
+	}
+
+	// each broken rule is reported
+	code, data := bearerRequest(PostMethod, "/users", `{"username":"letmein","password":"letmein"}`, admin.AccessToken)
+	assert.Equal(BadRequest, code, "weak password, should return Bad Request")
+	var problem schema.Problem
+	json.Unmarshal(data, &problem)
+	var codes []exception.Code
+	for _, err := range problem.Errors {
+		assert.Equal("password", err.Field)
+		codes = append(codes, err.Code)
+	}
+	assert.Equal([]exception.Code{exception.CodeTooShort, exception.CodePasswordCharClasses,
+		exception.CodePasswordIsUsername, exception.CodePasswordBreached}, codes, "weak password, should report each rule")
+}
+
+func TestPasswordRehash(t *testing.T) {
+	assert := assert.New(t)
+
+	// hash of a former cost
+	hash, _ := bcrypt.GenerateFromPassword([]byte("Re-hashed"), Config.BCRYPT_COST+1)
+	user := model.User{Username: "rehashUser", Password: string(hash), Roles: []model.UserRole{{Role: model.RoleReader}}}
+	if err := userRepo.Create(&user); err != nil {
+		t.FailNow()
+	}
+
+	code, _ := login("rehashUser", "Re-hashed")
+	assert.Equal(OK, code, "login with hash of a former cost, should return OK")
+
+	user = model.User{Username: "rehashUser"}
+	userRepo.GetByUsername(&user)
+	cost, _ := bcrypt.Cost([]byte(user.Password))
+	assert.Equal(Config.BCRYPT_COST, cost, "login, should rehash the password with the configured cost")
+
+	code, _ = login("rehashUser", "Re-hashed")
+	assert.Equal(OK, code, "login after rehash, should return OK")
+}
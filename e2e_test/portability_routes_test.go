@@ -0,0 +1,244 @@
+package e2etest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/denisyao1/welsh-academy-api/database"
+	"github.com/denisyao1/welsh-academy-api/model"
+	"github.com/denisyao1/welsh-academy-api/repository"
+	"github.com/denisyao1/welsh-academy-api/schema"
+	"github.com/denisyao1/welsh-academy-api/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportCatalogue(t *testing.T) {
+	assert := assert.New(t)
+
+	// create admin user if not exist
+	userService.CreateIfNotExist(&model.User{Username: "admin", Password: "admin", Roles: []model.UserRole{{Role: model.RoleAdmin}}})
+
+	code, authCookie := login("admin", "admin")
+	if code != 200 {
+		t.Log("Auth failed")
+		t.FailNow()
+	}
+
+	invalidCSV := "type,name,making,ingredients,yield,prepTime,cookTime,allergens\n" +
+		"ingredient,catalogueLeek,,,,,,\n" +
+		"ingredient,,,,,,,\n" +
+		"recipe,catalogueCawl,Simmer,catalogueLeek|catalogueLamb,4,ten,,\n" +
+		"cake,catalogueCake,,,,,,\n"
+
+	validNDJSON := `{"type":"ingredient","name":"catalogueLeek"}` + "\n" +
+		`{"type":"ingredient","name":"catalogueLamb","allergens":["sulphites"]}` + "\n" +
+		"\n" +
+		`{"type":"recipe","name":"catalogueCawl","making":"Simmer","ingredients":["catalogueLeek","catalogueLamb"],"cookTime":120,"tags":["catalogueSoup"]}` + "\n"
+
+	testCases := []struct {
+		query       string
+		body        string
+		contentType string
+		statusCode  int
+		applied     bool
+		errors      int
+		description string
+	}{
+		{
+			query:       "?dryRun=true",
+			body:        invalidCSV,
+			contentType: schema.MIMETextCSV,
+			statusCode:  OK,
+			errors:      3,
+			description: "dry run of invalid CSV, should report 3 invalid rows",
+		},
+		{
+			body:        invalidCSV,
+			contentType: schema.MIMETextCSV,
+			statusCode:  BadRequest,
+			errors:      3,
+			description: "invalid CSV, should not be applied",
+		},
+		{
+			query:       "?dryRun=true&format=ndjson",
+			body:        validNDJSON,
+			contentType: "text/plain",
+			statusCode:  OK,
+			description: "dry run of valid NDJSON, should report no error",
+		},
+		{
+			body:        validNDJSON,
+			contentType: schema.MIMEApplicationNDJSON,
+			statusCode:  Created,
+			applied:     true,
+			description: "valid NDJSON, should be applied",
+		},
+		{
+			body:        validNDJSON,
+			contentType: schema.MIMEApplicationNDJSON,
+			statusCode:  BadRequest,
+			errors:      3,
+			description: "already imported NDJSON, should report duplicates",
+		},
+		{
+			body:        validNDJSON,
+			contentType: "text/plain",
+			statusCode:  BadRequest,
+			errors:      1,
+			description: "unknown format, should return bad request with the invalid format",
+		},
+	}
+
+	url := BaseUrl + "/admin/import"
+	for _, tt := range testCases {
+		before, _ := ingredientRepo.FindAll()
+		req := httptest.NewRequest(PostMethod, url+tt.query, bytes.NewBufferString(tt.body))
+		req.Header.Set("Content-Type", tt.contentType)
+		req.AddCookie(authCookie)
+		resp, _ := App.Test(req, -1)
+		assert.Equal(tt.statusCode, resp.StatusCode, tt.description)
+		body, _ := io.ReadAll(resp.Body)
+		response := schema.ImportResponse{}
+		json.Unmarshal(body, &response)
+		assert.Equal(tt.applied, response.Applied, tt.description)
+		assert.Equal(tt.errors, len(response.Errors), tt.description)
+
+		// nothing is created unless the catalogue is applied
+		after, _ := ingredientRepo.FindAll()
+		if !tt.applied {
+			assert.Equal(len(before), len(after), tt.description)
+		}
+	}
+
+	recipes, _ := recipeRepo.FindAllContainging([]string{"catalogueLamb"})
+	assert.Equal(1, len(recipes), "imported recipe should be in the DB")
+	if len(recipes) == 1 {
+		assert.Equal(2, len(recipes[0].Ingredients), "imported recipe should have 2 ingredients")
+		assert.Equal(120, recipes[0].CookTime, "imported recipe cook time should be 120")
+		if assert.Equal(1, len(recipes[0].Tags), "imported recipe should have its tag") {
+			assert.Equal("cataloguesoup", recipes[0].Tags[0].Name)
+		}
+	}
+
+}
+
+// newCatalogueService returns a catalogue service on new in-memory DB.
+func newCatalogueService() service.CatalogueService {
+	db, _ := database.NewInMemoryDB(false)
+	db.MigrateAll()
+	ingredients := repository.NewGormIngredientRepository(db.GetDB())
+	recipes := repository.NewGormRecipeRepository(db.GetDB())
+	return service.NewCatalogueService(repository.NewGormCatalogueRepository(db.GetDB()), ingredients, recipes)
+}
+
+func TestCatalogueRoundTrip(t *testing.T) {
+	assert := assert.New(t)
+
+	catalogue := `{"type":"ingredient","name":"leek"}` + "\n" +
+		`{"type":"recipe","name":"cawl","making":"Simmer","ingredients":["leek"],"tags":["soup","winter"]}` + "\n"
+	source := newCatalogueService()
+	if _, err := source.Import([]byte(catalogue), schema.CatalogueNDJSON, false); !assert.NoError(err) {
+		t.FailNow()
+	}
+
+	for _, format := range []string{schema.CatalogueNDJSON, schema.CatalogueCSV} {
+		var export bytes.Buffer
+		if err := source.Export(&export, format); !assert.NoError(err, format) {
+			continue
+		}
+
+		target := newCatalogueService()
+		_, err := target.Import(export.Bytes(), format, false)
+		assert.NoError(err, "%s import of an export, should be applied", format)
+
+		var reexport bytes.Buffer
+		target.Export(&reexport, format)
+		assert.Equal(export.String(), reexport.String(), "%s import of an export, should keep the catalogue", format)
+		if format == schema.CatalogueCSV {
+			assert.Contains(export.String(), "recipe,cawl,Simmer,leek,,,,,soup|winter\n", "csv export, should join the tags")
+		}
+	}
+}
+
+func TestExportCatalogue(t *testing.T) {
+	assert := assert.New(t)
+
+	// create admin user if not exist
+	userService.CreateIfNotExist(&model.User{Username: "admin", Password: "admin", Roles: []model.UserRole{{Role: model.RoleAdmin}}})
+	ingredient, _ := ingredientRepo.GetOrCreate("exportIngredient")
+	recipe := model.Recipe{
+		Name:        "exportRecipe",
+		Making:      "Mix, then bake\nServe",
+		Ingredients: []model.Ingredient{ingredient},
+		Tags:        []model.Tag{{Name: "exporttag"}}}
+	recipeRepo.GetOrCreate(&recipe)
+
+	code, authCookie := login("admin", "admin")
+	if code != 200 {
+		t.Log("Auth failed")
+		t.FailNow()
+	}
+
+	testCases := []struct {
+		format      string
+		contentType string
+		statusCode  int
+		description string
+	}{
+		{
+			contentType: schema.MIMEApplicationNDJSON,
+			statusCode:  OK,
+			description: "default format, should export NDJSON",
+		},
+		{
+			format:      "csv",
+			contentType: schema.MIMETextCSV,
+			statusCode:  OK,
+			description: "csv format, should export CSV",
+		},
+		{
+			format:      "xml",
+			statusCode:  BadRequest,
+			description: "unknown format, should return bad request",
+		},
+	}
+
+	url := BaseUrl + "/admin/export"
+	for _, tt := range testCases {
+		req := httptest.NewRequest(GetMethod, url+"?format="+tt.format, nil)
+		if tt.format == "" {
+			req = httptest.NewRequest(GetMethod, url, nil)
+		}
+		req.AddCookie(authCookie)
+		resp, _ := App.Test(req, -1)
+		assert.Equal(tt.statusCode, resp.StatusCode, tt.description)
+		if resp.StatusCode != OK {
+			continue
+		}
+		assert.Equal(tt.contentType, resp.Header.Get("Content-Type"), tt.description)
+		body, _ := io.ReadAll(resp.Body)
+		export := string(body)
+
+		if tt.format == "csv" {
+			assert.True(strings.HasPrefix(export, "type,name,making,ingredients,yield,prepTime,cookTime,allergens,tags\n"), tt.description)
+			assert.Contains(export, "recipe,exportRecipe,\"Mix, then bake\nServe\",exportIngredient,,,,,exporttag\n", tt.description)
+			continue
+		}
+
+		var found bool
+		for _, line := range strings.Split(strings.TrimSpace(export), "\n") {
+			row := schema.CatalogueRow{}
+			assert.NoError(json.Unmarshal([]byte(line), &row), tt.description)
+			if row.Type == schema.CatalogueRecipe && row.Name == "exportRecipe" {
+				found = true
+				assert.Equal([]string{"exportIngredient"}, row.Ingredients, tt.description)
+				assert.Equal([]string{"exporttag"}, row.Tags, tt.description)
+			}
+		}
+		assert.True(found, "exported catalogue should contain exportRecipe")
+	}
+}
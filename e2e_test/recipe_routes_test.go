@@ -19,7 +19,7 @@ func TestCreateRecipe(t *testing.T) {
 	assert := assert.New(t)
 
 	// create an admin user if not exists
-	userService.CreateIfNotExist(&model.User{Username: "admin", Password: "admin", IsAdmin: true})
+	userService.CreateIfNotExist(&model.User{Username: "admin", Password: "admin", Roles: []model.UserRole{{Role: model.RoleAdmin}}})
 
 	// create some ingredients if not exist
 	ingredientRepo.GetOrCreate("ingredient01")
@@ -145,7 +145,7 @@ func TestListAllPossibleRecipes(t *testing.T) {
 	}
 
 	// create admin user if not exist
-	user := model.User{Username: "test", Password: "test", IsAdmin: false}
+	user := model.User{Username: "test", Password: "test"}
 	userService.CreateIfNotExist(&user)
 
 	// login
@@ -229,7 +229,7 @@ func TestFlagUnflagRecipes(t *testing.T) {
 	recipeRepo.GetOrCreate(&recipe2)
 
 	// get or create user and login
-	user := model.User{Username: "test", Password: "test", IsAdmin: false}
+	user := model.User{Username: "test", Password: "test"}
 	userService.CreateIfNotExist(&user)
 
 	code, authCookie := login("test", "test")
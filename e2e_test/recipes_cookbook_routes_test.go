@@ -0,0 +1,136 @@
+package e2etest
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/denisyao1/welsh-academy-api/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCookbooks(t *testing.T) {
+	assert := assert.New(t)
+
+	// create recipes, one of them in user favorites
+	cheese := model.Ingredient{Name: "cookbookCheese", Allergens: model.AllergenMilk}
+	ingredientRepo.Create(&cheese)
+	bread, _ := ingredientRepo.GetOrCreate("cookbookBread")
+	rarebit := model.Recipe{
+		Name:        "Cookbook Rarebit",
+		Making:      "Melt the cheese.\nPour on toasted bread.",
+		Yield:       "2",
+		Ingredients: []model.Ingredient{cheese, bread}}
+	toast := model.Recipe{
+		Name:        "Cookbook Toast",
+		Making:      "Toast the bread.",
+		Ingredients: []model.Ingredient{bread}}
+	recipeRepo.GetOrCreate(&rarebit)
+	recipeRepo.GetOrCreate(&toast)
+
+	user := model.User{Username: "cookbookUser", Password: "cookbook"}
+	userService.CreateIfNotExist(&user)
+	code, authCookie := login("cookbookUser", "cookbook")
+	if code != 200 {
+		t.Log("Auth failed")
+		t.FailNow()
+	}
+
+	// no favorite yet
+	req := httptest.NewRequest(GetMethod, BaseUrl+"/recipes/favorites/export", nil)
+	req.AddCookie(authCookie)
+	resp, _ := App.Test(req, -1)
+	assert.Equal(BadRequest, resp.StatusCode, "no favorite, should return bad request")
+
+	recipeRepo.AddToFavorites(user.ID, rarebit.ID)
+
+	favoritesCases := []struct {
+		format      string
+		statusCode  int
+		contentType string
+		contains    string
+		description string
+	}{
+		{
+			statusCode:  OK,
+			contentType: "text/html; charset=utf-8",
+			contains:    "<strong>Allergens:</strong> milk",
+			description: "default format, should return HTML",
+		},
+		{
+			format:      "markdown",
+			statusCode:  OK,
+			contentType: "text/markdown; charset=utf-8",
+			contains:    "1. [Cookbook Rarebit](#recipe-",
+			description: "markdown format, should return markdown",
+		},
+		{
+			format:      "pdf",
+			statusCode:  OK,
+			contentType: "application/pdf",
+			contains:    "%PDF",
+			description: "pdf format, should return PDF",
+		},
+		{
+			format:      "docx",
+			statusCode:  BadRequest,
+			description: "unknown format, should return bad request",
+		},
+	}
+
+	for _, tt := range favoritesCases {
+		req := httptest.NewRequest(GetMethod, BaseUrl+"/recipes/favorites/export?format="+tt.format, nil)
+		req.AddCookie(authCookie)
+		resp, _ := App.Test(req, -1)
+		assert.Equal(tt.statusCode, resp.StatusCode, tt.description)
+		if resp.StatusCode != OK {
+			continue
+		}
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(tt.contentType, resp.Header.Get("Content-Type"), tt.description)
+		assert.Contains(string(body), tt.contains, tt.description)
+		assert.NotContains(string(body), "Cookbook Toast", tt.description)
+	}
+
+	cookbookCases := []struct {
+		body        string
+		statusCode  int
+		description string
+	}{
+		{
+			body:        `{"title": "Toasts", "recipeIds": []}`,
+			statusCode:  BadRequest,
+			description: "no recipe, should return bad request",
+		},
+		{
+			body:        fmt.Sprintf(`{"title": "Toasts", "recipeIds": [%d, 0]}`, toast.ID),
+			statusCode:  BadRequest,
+			description: "unknown recipe, should return bad request",
+		},
+		{
+			body:        fmt.Sprintf(`{"title": "Toasts", "recipeIds": [%d, %d]}`, toast.ID, rarebit.ID),
+			statusCode:  OK,
+			description: "existing recipes, should return the cookbook",
+		},
+	}
+
+	for _, tt := range cookbookCases {
+		req := httptest.NewRequest(PostMethod, BaseUrl+"/cookbooks?format=markdown", bytes.NewBufferString(tt.body))
+		req.Header.Set("Content-Type", "application/json")
+		req.AddCookie(authCookie)
+		resp, _ := App.Test(req, -1)
+		assert.Equal(tt.statusCode, resp.StatusCode, tt.description)
+		if resp.StatusCode != OK {
+			continue
+		}
+		body, _ := io.ReadAll(resp.Body)
+		cookbook := string(body)
+		assert.True(strings.HasPrefix(cookbook, "# Toasts\n"), tt.description)
+		toastIndex := strings.Index(cookbook, "## <a id=\"recipe-"+fmt.Sprint(toast.ID))
+		rarebitIndex := strings.Index(cookbook, "## <a id=\"recipe-"+fmt.Sprint(rarebit.ID))
+		assert.True(toastIndex != -1 && toastIndex < rarebitIndex, "recipes should be in the requested order")
+	}
+}
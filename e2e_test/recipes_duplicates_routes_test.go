@@ -0,0 +1,88 @@
+package e2etest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/denisyao1/welsh-academy-api/exception"
+	"github.com/denisyao1/welsh-academy-api/model"
+	"github.com/denisyao1/welsh-academy-api/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDuplicateRecipes(t *testing.T) {
+	assert := assert.New(t)
+
+	cheese, _ := ingredientRepo.GetOrCreate("duplicateCheese")
+	bread, _ := ingredientRepo.GetOrCreate("duplicateBread")
+	ingredientRepo.GetOrCreate("duplicateLamb")
+	rarebit := model.Recipe{Name: "Duplicate Rarebit", Making: "Melt.", Ingredients: []model.Ingredient{cheese, bread}}
+	recipeRepo.GetOrCreate(&rarebit)
+
+	code, authCookie := login("admin", "admin")
+	if code != 200 {
+		t.Log("Auth failed")
+		t.FailNow()
+	}
+
+	testCases := []struct {
+		name        string
+		ingredients string
+		query       string
+		statusCode  int
+		candidates  int
+		description string
+	}{
+		{"Duplicate rarebit (classic)", `[{"name":"duplicateCheese"},{"name":"duplicateBread"}]`, "",
+			Conflict, 1, "same normalized name should return conflict"},
+		{"Duplicate Cheesy Rarebit", `[{"name":"duplicateCheese"},{"name":"duplicateBread"}]`, "",
+			Conflict, 1, "close name and same ingredients should return conflict"},
+		{"Duplicate Cheesy Rarebit", `[{"name":"duplicateCheese"},{"name":"duplicateBread"}]`, "?force=true",
+			Created, 0, "force should create the recipe"},
+		{"Duplicate Cawl", `[{"name":"duplicateLamb"}]`, "",
+			Created, 0, "different recipe should be created"},
+	}
+
+	for _, tc := range testCases {
+		body := `{"name":"` + tc.name + `","making":"Cook.","ingredients":` + tc.ingredients + `}`
+		req := httptest.NewRequest(PostMethod, BaseUrl+"/recipes"+tc.query, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.AddCookie(authCookie)
+		resp, _ := App.Test(req, -1)
+		assert.Equal(tc.statusCode, resp.StatusCode, tc.description)
+
+		if resp.StatusCode == Conflict {
+			var duplicates schema.DuplicateResponse
+			data, _ := io.ReadAll(resp.Body)
+			json.Unmarshal(data, &duplicates)
+			assert.Equal(exception.CodeSimilarRecipes, duplicates.Code, tc.description)
+			if assert.Len(duplicates.Candidates, tc.candidates, tc.description) {
+				assert.Equal(rarebit.Name, duplicates.Candidates[0].Recipe.Name, tc.description)
+			}
+		}
+	}
+
+	// report of duplicate clusters
+	req := httptest.NewRequest(GetMethod, BaseUrl+"/admin/recipes/duplicates", nil)
+	req.AddCookie(authCookie)
+	resp, _ := App.Test(req, -1)
+	assert.Equal(OK, resp.StatusCode, "should return OK")
+
+	var report schema.DuplicateClustersResponse
+	data, _ := io.ReadAll(resp.Body)
+	json.Unmarshal(data, &report)
+	var names []string
+	for _, cluster := range report.Clusters {
+		for _, recipe := range cluster.Recipes {
+			if recipe.Name == rarebit.Name {
+				for _, recipe := range cluster.Recipes {
+					names = append(names, recipe.Name)
+				}
+			}
+		}
+	}
+	assert.ElementsMatch([]string{"Duplicate Rarebit", "Duplicate Cheesy Rarebit"}, names, "duplicates should be grouped")
+}
@@ -0,0 +1,84 @@
+package e2etest
+
+import (
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/denisyao1/welsh-academy-api/model"
+	"github.com/denisyao1/welsh-academy-api/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecommendedRecipes(t *testing.T) {
+	assert := assert.New(t)
+
+	// cawl and lobscouse share ingredients, bara brith is liked by cawl fans
+	lamb, _ := ingredientRepo.GetOrCreate("recommendLamb")
+	leek, _ := ingredientRepo.GetOrCreate("recommendLeek")
+	flour, _ := ingredientRepo.GetOrCreate("recommendFlour")
+	cawl := model.Recipe{Name: "Recommend Cawl", Making: "Stew.", Ingredients: []model.Ingredient{lamb, leek}}
+	lobscouse := model.Recipe{Name: "Recommend Lobscouse", Making: "Stew.", Ingredients: []model.Ingredient{lamb, leek}}
+	baraBrith := model.Recipe{Name: "Recommend Bara Brith", Making: "Bake.", Ingredients: []model.Ingredient{flour}}
+	recipeRepo.GetOrCreate(&cawl)
+	recipeRepo.GetOrCreate(&lobscouse)
+	recipeRepo.GetOrCreate(&baraBrith)
+
+	fan := model.User{Username: "recommendFan", Password: "recommend"}
+	userService.CreateIfNotExist(&fan)
+	recipeRepo.AddToFavorites(fan.ID, cawl.ID)
+	recipeRepo.AddToFavorites(fan.ID, baraBrith.ID)
+
+	user := model.User{Username: "recommendUser", Password: "recommend"}
+	userService.CreateIfNotExist(&user)
+	code, authCookie := login("recommendUser", "recommend")
+	if code != 200 {
+		t.Log("Auth failed")
+		t.FailNow()
+	}
+
+	recommend := func(query string) (int, schema.RecommendationsResponse) {
+		req := httptest.NewRequest(GetMethod, BaseUrl+"/recipes/recommended"+query, nil)
+		req.AddCookie(authCookie)
+		resp, _ := App.Test(req, -1)
+		var body schema.RecommendationsResponse
+		data, _ := io.ReadAll(resp.Body)
+		json.Unmarshal(data, &body)
+		return resp.StatusCode, body
+	}
+
+	// without favorites, most favorited recipes are suggested
+	recommendationService.Refresh()
+	code, body := recommend("")
+	assert.Equal(OK, code, "should return OK")
+	assert.NotZero(body.Count, "should suggest popular recipes")
+	assert.Equal(body.Count, len(body.Recommendations))
+
+	// with favorites, similar recipes are suggested and favorites left out
+	recipeRepo.AddToFavorites(user.ID, cawl.ID)
+	recommendationService.Refresh()
+	code, body = recommend("?limit=5")
+	assert.Equal(OK, code, "should return OK")
+	var names []string
+	for _, recommendation := range body.Recommendations {
+		names = append(names, recommendation.Recipe.Name)
+	}
+	assert.Contains(names, lobscouse.Name, "recipe sharing ingredients should be suggested")
+	assert.Contains(names, baraBrith.Name, "recipe liked by other fans should be suggested")
+	assert.NotContains(names, cawl.Name, "favorites should not be suggested")
+	assert.LessOrEqual(body.Count, 5, "limit should be applied")
+	if body.Count > 0 {
+		assert.Equal(lobscouse.Name, body.Recommendations[0].Recipe.Name, "most similar recipe should come first")
+		assert.Equal([]string{cawl.Name}, body.Recommendations[0].Because)
+	}
+
+	// invalid limit
+	code, _ = recommend("?limit=100")
+	assert.Equal(BadRequest, code, "limit above 50 should return bad request")
+
+	// authentication required
+	req := httptest.NewRequest(GetMethod, BaseUrl+"/recipes/recommended", nil)
+	resp, _ := App.Test(req, -1)
+	assert.Equal(Unauthorized, resp.StatusCode, "should return unauthorized")
+}
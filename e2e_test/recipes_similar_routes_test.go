@@ -0,0 +1,105 @@
+package e2etest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/denisyao1/welsh-academy-api/model"
+	"github.com/denisyao1/welsh-academy-api/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimilarRecipes(t *testing.T) {
+	assert := assert.New(t)
+
+	salt, _ := ingredientRepo.GetOrCreate("similarSalt")
+	cheese, _ := ingredientRepo.GetOrCreate("similarCheese")
+	bread, _ := ingredientRepo.GetOrCreate("similarBread")
+	leek, _ := ingredientRepo.GetOrCreate("similarLeek")
+
+	code, authCookie := login("admin", "admin")
+	if code != 200 {
+		t.Log("Auth failed")
+		t.FailNow()
+	}
+
+	// create recipes with tags
+	createCases := []struct {
+		name        string
+		ingredients string
+		tags        string
+		statusCode  int
+		description string
+	}{
+		{"Similar Rarebit", `[{"name":"similarCheese"},{"name":"similarBread"},{"name":"similarSalt"}]`,
+			`[{"name":"Welsh"},{"name":"snack"}]`, Created, "should create recipe with tags"},
+		{"Similar Toast", `[{"name":"similarBread"},{"name":"similarSalt"}]`,
+			`[{"name":"snack"}]`, Created, "should reuse existing tags"},
+		{"Similar Cawl", `[{"name":"similarLeek"},{"name":"similarSalt"}]`,
+			`[{"name":"welsh"}]`, Created, "tags should be case insensitive"},
+		{"Similar Duplicate", `[{"name":"similarSalt"}]`,
+			`[{"name":"soup"},{"name":" Soup"}]`, BadRequest, "duplicate tags should return bad request"},
+		{"Similar Empty", `[{"name":"similarSalt"}]`,
+			`[{"name":""}]`, BadRequest, "empty tag should return bad request"},
+	}
+
+	recipes := make(map[string]model.Recipe)
+	for _, tc := range createCases {
+		body := fmt.Sprintf(`{"name":"%s","making":"Cook.","ingredients":%s,"tags":%s}`, tc.name, tc.ingredients, tc.tags)
+		req := httptest.NewRequest(PostMethod, BaseUrl+"/recipes", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.AddCookie(authCookie)
+		resp, _ := App.Test(req, -1)
+		assert.Equal(tc.statusCode, resp.StatusCode, tc.description)
+
+		var recipe model.Recipe
+		data, _ := io.ReadAll(resp.Body)
+		json.Unmarshal(data, &recipe)
+		recipes[tc.name] = recipe
+	}
+	rarebit := recipes["Similar Rarebit"]
+	assert.Equal([]model.Tag{{Name: "welsh"}, {Name: "snack"}}, rarebit.Tags, "tags should be normalized")
+
+	similar := func(recipeID int, query string) (int, schema.SimilarRecipesResponse) {
+		url := fmt.Sprintf("%s/recipes/%d/similar%s", BaseUrl, recipeID, query)
+		req := httptest.NewRequest(GetMethod, url, nil)
+		req.AddCookie(authCookie)
+		resp, _ := App.Test(req, -1)
+		var body schema.SimilarRecipesResponse
+		data, _ := io.ReadAll(resp.Body)
+		json.Unmarshal(data, &body)
+		return resp.StatusCode, body
+	}
+
+	code, body := similar(rarebit.ID, "")
+	assert.Equal(OK, code, "should return OK")
+	if assert.Equal(2, body.Count, "recipes sharing an ingredient or a tag should be listed") {
+		toast, cawl := body.Recipes[0], body.Recipes[1]
+		assert.Equal("Similar Toast", toast.Recipe.Name, "most similar recipe should come first")
+		assert.ElementsMatch([]string{bread.Name, salt.Name}, toast.SharedIngredients)
+		assert.Equal([]string{cheese.Name}, toast.MissingIngredients)
+		assert.Empty(toast.ExtraIngredients)
+		assert.Equal([]string{"snack"}, toast.SharedTags)
+
+		assert.Equal("Similar Cawl", cawl.Recipe.Name)
+		assert.Equal([]string{salt.Name}, cawl.SharedIngredients)
+		assert.ElementsMatch([]string{cheese.Name, bread.Name}, cawl.MissingIngredients)
+		assert.Equal([]string{leek.Name}, cawl.ExtraIngredients)
+		assert.Equal([]string{"welsh"}, cawl.SharedTags)
+		assert.Greater(toast.Score, cawl.Score)
+	}
+
+	code, body = similar(rarebit.ID, "?limit=1")
+	assert.Equal(OK, code, "should return OK")
+	assert.Equal(1, body.Count, "limit should be applied")
+
+	code, _ = similar(rarebit.ID, "?limit=0")
+	assert.Equal(BadRequest, code, "invalid limit should return bad request")
+
+	code, _ = similar(100000, "")
+	assert.Equal(NotFound, code, "unknown recipe should return not found")
+}
@@ -0,0 +1,81 @@
+package e2etest
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/denisyao1/welsh-academy-api/model"
+	"github.com/denisyao1/welsh-academy-api/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoles(t *testing.T) {
+	assert := assert.New(t)
+
+	userService.CreateIfNotExist(&model.User{Username: "admin", Password: "admin", Roles: []model.UserRole{{Role: model.RoleAdmin}}})
+	user := model.User{Username: "roleUser", Password: "role"}
+	if err := userService.CreateIfNotExist(&user); err != nil {
+		t.FailNow()
+	}
+
+	admin := loginForToken("admin", "admin", "roles")
+	reader := loginForToken("roleUser", "role", "roles")
+	if admin.AccessToken == "" || reader.AccessToken == "" {
+		t.Log("Auth failed")
+		t.FailNow()
+	}
+
+	// roles and their permissions
+	code, data := bearerRequest(GetMethod, "/roles", "", admin.AccessToken)
+	assert.Equal(OK, code, "list roles, should return OK")
+	var rolesResponse schema.RolesResponse
+	json.Unmarshal(data, &rolesResponse)
+	if assert.Equal(4, rolesResponse.Count, "list roles, should return all roles") {
+		assert.Equal(model.RoleEditor, rolesResponse.Roles[1].Name)
+		assert.NotContains(rolesResponse.Roles[1].Permissions, model.PermUserManage, "editors should not manage users")
+	}
+
+	code, _ = bearerRequest(GetMethod, "/roles", "", reader.AccessToken)
+	assert.Equal(Forbidden, code, "list roles without user:manage permission, should return Forbidden")
+
+	// readers only browse
+	ingredient := `{"name":"Role test laverbread"}`
+	code, _ = bearerRequest(PostMethod, "/ingredients", ingredient, reader.AccessToken)
+	assert.Equal(Forbidden, code, "create ingredient as reader, should return Forbidden")
+	code, _ = bearerRequest(GetMethod, "/ingredients", "", reader.AccessToken)
+	assert.Equal(OK, code, "list ingredients as reader, should return OK")
+
+	rolesURL := fmt.Sprintf("/users/%d/roles", user.ID)
+	testCases := []struct {
+		url         string
+		body        string
+		statusCode  int
+		description string
+	}{
+		{rolesURL, `{"roles":[]}`, BadRequest, "no role, should return Bad Request"},
+		{rolesURL, `{"roles":["chef"]}`, BadRequest, "unknown role, should return Bad Request"},
+		{rolesURL, `{"roles":["editor","editor"]}`, BadRequest, "repeated role, should return Bad Request"},
+		{"/users/100000/roles", `{"roles":["editor"]}`, NotFound, "unknown user, should return Not Found"},
+		{rolesURL, `{"roles":["editor"]}`, OK, "editor role, should return OK"},
+	}
+
+	for _, tc := range testCases {
+		code, data = bearerRequest(PutMethod, tc.url, tc.body, admin.AccessToken)
+		assert.Equal(tc.statusCode, code, tc.description)
+	}
+
+	var updated model.User
+	json.Unmarshal(data, &updated)
+	assert.Equal([]model.Role{model.RoleEditor}, updated.RoleNames(), "assigned roles, should be returned")
+
+	code, _ = bearerRequest(PutMethod, rolesURL, `{"roles":["admin"]}`, reader.AccessToken)
+	assert.Equal(Forbidden, code, "assign roles as reader, should return Forbidden")
+
+	// the new roles are in the next tokens
+	editor := loginForToken("roleUser", "role", "roles")
+	code, _ = bearerRequest(PostMethod, "/ingredients", ingredient, editor.AccessToken)
+	assert.Equal(Created, code, "create ingredient as editor, should return Created")
+	code, _ = bearerRequest(PostMethod, "/users", `{"username":"roleUser2","password":"role2"}`, editor.AccessToken)
+	assert.Equal(Forbidden, code, "create user as editor, should return Forbidden")
+}
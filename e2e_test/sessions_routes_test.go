@@ -0,0 +1,108 @@
+package e2etest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/denisyao1/welsh-academy-api/model"
+	"github.com/denisyao1/welsh-academy-api/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+// loginForToken logs in from a device and returns the tokens.
+func loginForToken(username, password, userAgent string) schema.Token {
+	inputs := fmt.Sprintf(`{"username":"%s", "password": "%s"}`, username, password)
+	req := httptest.NewRequest(PostMethod, BaseUrl+"/login?mode=token", bytes.NewBufferString(inputs))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	resp, _ := App.Test(req, -1)
+
+	var token schema.Token
+	data, _ := io.ReadAll(resp.Body)
+	json.Unmarshal(data, &token)
+	return token
+}
+
+// bearerRequest sends a request with an access token and returns the response status code and body.
+func bearerRequest(method, url, body, accessToken string) (int, []byte) {
+	req := httptest.NewRequest(method, BaseUrl+url, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	resp, _ := App.Test(req, -1)
+	data, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, data
+}
+
+func TestSessions(t *testing.T) {
+	assert := assert.New(t)
+
+	user := model.User{Username: "sessionUser", Password: "session"}
+	if err := userService.CreateIfNotExist(&user); err != nil {
+		t.FailNow()
+	}
+
+	phone := loginForToken("sessionUser", "session", "phone")
+	laptop := loginForToken("sessionUser", "session", "laptop")
+	if phone.AccessToken == "" || laptop.AccessToken == "" {
+		t.Log("Auth failed")
+		t.FailNow()
+	}
+
+	code, data := bearerRequest(GetMethod, "/users/me/sessions", "", phone.AccessToken)
+	assert.Equal(OK, code, "list sessions, should return OK")
+	var response schema.SessionsResponse
+	json.Unmarshal(data, &response)
+	if !assert.Equal(2, response.Count, "list sessions, should return both logins") {
+		t.FailNow()
+	}
+	sessions := make(map[string]schema.Session)
+	for _, session := range response.Sessions {
+		sessions[session.UserAgent] = session
+	}
+	assert.True(sessions["phone"].Current, "session of the request, should be current")
+	assert.False(sessions["laptop"].Current, "other session, should not be current")
+	assert.NotEmpty(sessions["laptop"].IP)
+
+	// per-session revoke
+	code, _ = bearerRequest(DeleteMethod, "/users/me/sessions/unknown", "", phone.AccessToken)
+	assert.Equal(NotFound, code, "unknown session, should return Not Found")
+	code, _ = bearerRequest(DeleteMethod, "/users/me/sessions/"+sessions["laptop"].ID, "", phone.AccessToken)
+	assert.Equal(OK, code, "revoke session, should return OK")
+	code, _ = bearerRequest(GetMethod, "/users/my-infos", "", laptop.AccessToken)
+	assert.Equal(Unauthorized, code, "access token of a revoked session, should be rejected")
+	code, _ = refresh(laptop.RefreshToken, false)
+	assert.Equal(Unauthorized, code, "refresh token of a revoked session, should be rejected")
+	code, _ = bearerRequest(GetMethod, "/users/my-infos", "", phone.AccessToken)
+	assert.Equal(OK, code, "other sessions, should not be affected")
+
+	// logout
+	code, _ = bearerRequest(GetMethod, "/logout", "", phone.AccessToken)
+	assert.Equal(OK, code, "logout, should return OK")
+	code, _ = bearerRequest(GetMethod, "/users/my-infos", "", phone.AccessToken)
+	assert.Equal(Unauthorized, code, "access token after logout, should be rejected")
+
+	// password change revokes the other sessions
+	current := loginForToken("sessionUser", "session", "phone")
+	other := loginForToken("sessionUser", "session", "laptop")
+	code, _ = bearerRequest(PatchMethod, "/users/password-change", `{"currentPassword":"session","password":"newSession"}`, current.AccessToken)
+	assert.Equal(OK, code, "password change, should return OK")
+	code, _ = bearerRequest(GetMethod, "/users/my-infos", "", other.AccessToken)
+	assert.Equal(Unauthorized, code, "other session after password change, should be rejected")
+	code, _ = bearerRequest(GetMethod, "/users/my-infos", "", current.AccessToken)
+	assert.Equal(OK, code, "session changing the password, should be kept")
+
+	// admin revokes all sessions
+	admin := loginForToken("admin", "admin", "admin")
+	code, _ = bearerRequest(DeleteMethod, "/users/100000/sessions", "", admin.AccessToken)
+	assert.Equal(NotFound, code, "sessions of unknown user, should return Not Found")
+	code, _ = bearerRequest(DeleteMethod, fmt.Sprintf("/users/%d/sessions", user.ID), "", current.AccessToken)
+	assert.Equal(Forbidden, code, "revoke all sessions without user:manage permission, should return Forbidden")
+	code, _ = bearerRequest(DeleteMethod, fmt.Sprintf("/users/%d/sessions", user.ID), "", admin.AccessToken)
+	assert.Equal(OK, code, "revoke all sessions, should return OK")
+	code, _ = bearerRequest(GetMethod, "/users/my-infos", "", current.AccessToken)
+	assert.Equal(Unauthorized, code, "access token after revoking all sessions, should be rejected")
+}
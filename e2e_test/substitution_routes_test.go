@@ -0,0 +1,136 @@
+package e2etest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/denisyao1/welsh-academy-api/model"
+	"github.com/denisyao1/welsh-academy-api/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubstitutions(t *testing.T) {
+	assert := assert.New(t)
+
+	cheddar, _ := ingredientRepo.GetOrCreate("subCheddar")
+	ingredientRepo.GetOrCreate("subCaerphilly")
+	buttermilk, _ := ingredientRepo.GetOrCreate("subButtermilk")
+	ingredientRepo.GetOrCreate("subMilk")
+	ingredientRepo.GetOrCreate("subLemonJuice")
+
+	code, authCookie := login("admin", "admin")
+	if code != 200 {
+		t.Log("Auth failed")
+		t.FailNow()
+	}
+
+	testCases := []struct {
+		body        string
+		statusCode  int
+		description string
+	}{
+		{`{"ingredient":"subCheddar","substitutes":[{"name":"subCaerphilly","ratio":1}],"notes":"Crumblier."}`,
+			Created, "should create substitution"},
+		{`{"ingredient":"subButtermilk","substitutes":[{"name":"subMilk","ratio":1},{"name":"subLemonJuice","ratio":0.06}]}`,
+			Created, "should create substitution with several substitutes"},
+		{`{"ingredient":"subCheddar","substitutes":[{"name":"subCaerphilly"}]}`,
+			Conflict, "same substitution should return conflict"},
+		{`{"ingredient":"subCheddar","substitutes":[{"name":"subCheddar"}]}`,
+			BadRequest, "ingredient substituting itself should return bad request"},
+		{`{"ingredient":"subCheddar","substitutes":[{"name":"subUnknown"}]}`,
+			BadRequest, "unknown substitute should return bad request"},
+		{`{"ingredient":"subCheddar","substitutes":[]}`,
+			BadRequest, "no substitute should return bad request"},
+		{`{"ingredient":"subCheddar","substitutes":[{"name":"subMilk","ratio":-1}]}`,
+			BadRequest, "negative ratio should return bad request"},
+	}
+
+	var created []model.Substitution
+	for _, tc := range testCases {
+		req := httptest.NewRequest(PostMethod, BaseUrl+"/substitutions", bytes.NewBufferString(tc.body))
+		req.Header.Set("Content-Type", "application/json")
+		req.AddCookie(authCookie)
+		resp, _ := App.Test(req, -1)
+		assert.Equal(tc.statusCode, resp.StatusCode, tc.description)
+
+		if resp.StatusCode == Created {
+			var substitution model.Substitution
+			data, _ := io.ReadAll(resp.Body)
+			json.Unmarshal(data, &substitution)
+			created = append(created, substitution)
+		}
+	}
+	if len(created) != 2 {
+		t.FailNow()
+	}
+
+	// list substitutes of an ingredient
+	url := fmt.Sprintf("%s/ingredients/%d/substitutes", BaseUrl, cheddar.ID)
+	req := httptest.NewRequest(GetMethod, url, nil)
+	req.AddCookie(authCookie)
+	resp, _ := App.Test(req, -1)
+	assert.Equal(OK, resp.StatusCode, "should return OK")
+	var substitutes schema.SubstitutionsResponse
+	data, _ := io.ReadAll(resp.Body)
+	json.Unmarshal(data, &substitutes)
+	if assert.Equal(1, substitutes.Count) {
+		substitution := substitutes.Substitutions[0]
+		assert.Equal("Crumblier.", substitution.Notes)
+		assert.Equal("subCaerphilly", substitution.Substitutes[0].Ingredient.Name)
+		assert.Equal(1.0, substitution.Substitutes[0].Ratio)
+	}
+
+	req = httptest.NewRequest(GetMethod, BaseUrl+"/ingredients/100000/substitutes", nil)
+	req.AddCookie(authCookie)
+	resp, _ = App.Test(req, -1)
+	assert.Equal(NotFound, resp.StatusCode, "unknown ingredient should return not found")
+
+	// recipes become cookable with substitutes
+	scones := model.Recipe{Name: "Substitute Scones", Making: "Bake.", Ingredients: []model.Ingredient{buttermilk}}
+	recipeRepo.GetOrCreate(&scones)
+
+	queryCases := []struct {
+		query         string
+		recipes       int
+		substitutions int
+		description   string
+	}{
+		{"?ingredients=subMilk&ingredients=subLemonJuice", 0, 0, "should not use substitutes by default"},
+		{"?ingredients=subMilk&ingredients=subLemonJuice&substitutes=true", 1, 1, "should use substitutes"},
+		{"?ingredients=subMilk&substitutes=true", 0, 0, "all substitutes should be available"},
+	}
+	for _, tc := range queryCases {
+		req := httptest.NewRequest(GetMethod, BaseUrl+"/recipes"+tc.query, nil)
+		req.AddCookie(authCookie)
+		resp, _ := App.Test(req, -1)
+		assert.Equal(OK, resp.StatusCode, tc.description)
+
+		var recipes schema.RecipesResponse
+		data, _ := io.ReadAll(resp.Body)
+		json.Unmarshal(data, &recipes)
+		assert.Equal(tc.recipes, recipes.Count, tc.description)
+		assert.Equal(tc.substitutions, len(recipes.Substitutions), tc.description)
+	}
+
+	// delete substitution
+	url = fmt.Sprintf("%s/substitutions/%d", BaseUrl, created[0].ID)
+	for _, statusCode := range []int{OK, NotFound} {
+		req := httptest.NewRequest(DeleteMethod, url, nil)
+		req.AddCookie(authCookie)
+		resp, _ := App.Test(req, -1)
+		assert.Equal(statusCode, resp.StatusCode)
+	}
+
+	// ingredient:manage permission required
+	userService.CreateIfNotExist(&model.User{Username: "substitutionUser", Password: "substitution"})
+	_, userCookie := login("substitutionUser", "substitution")
+	req = httptest.NewRequest(PostMethod, BaseUrl+"/substitutions", bytes.NewBufferString(testCases[0].body))
+	req.Header.Set("Content-Type", "application/json")
+	req.AddCookie(userCookie)
+	resp, _ = App.Test(req, -1)
+	assert.Equal(Forbidden, resp.StatusCode, "should return forbidden")
+}
@@ -0,0 +1,89 @@
+package e2etest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/denisyao1/welsh-academy-api/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+// refresh posts a refresh token, in the body or in a cookie, and returns the
+// status code with the new tokens.
+func refresh(refreshToken string, inCookie bool) (int, schema.Token) {
+	body := fmt.Sprintf(`{"refreshToken":"%s"}`, refreshToken)
+	if inCookie {
+		body = ""
+	}
+	req := httptest.NewRequest(PostMethod, BaseUrl+"/token/refresh?mode=token", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	if inCookie {
+		req.AddCookie(&http.Cookie{Name: "Refresh", Value: refreshToken})
+	}
+	resp, _ := App.Test(req, -1)
+
+	var token schema.Token
+	data, _ := io.ReadAll(resp.Body)
+	json.Unmarshal(data, &token)
+	return resp.StatusCode, token
+}
+
+func TestRefreshToken(t *testing.T) {
+	assert := assert.New(t)
+
+	credentials := []byte(`{"username":"admin", "password": "admin"}`)
+	req := httptest.NewRequest(PostMethod, BaseUrl+"/login?mode=token", bytes.NewBuffer(credentials))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := App.Test(req, -1)
+	if !assert.Equal(OK, resp.StatusCode, "token login, should return OK") {
+		t.FailNow()
+	}
+	var login schema.Token
+	data, _ := io.ReadAll(resp.Body)
+	json.Unmarshal(data, &login)
+	assert.NotEmpty(login.RefreshToken)
+	assert.True(login.RefreshExpiresAt.After(login.ExpiresAt))
+
+	code, _ := refresh("unknown", false)
+	assert.Equal(Unauthorized, code, "unknown refresh token, should return Unauthorized")
+
+	code, first := refresh(login.RefreshToken, false)
+	assert.Equal(OK, code, "refresh token, should return OK")
+	assert.NotEmpty(first.AccessToken)
+	assert.NotEqual(login.RefreshToken, first.RefreshToken, "refresh token, should be rotated")
+
+	req = httptest.NewRequest(GetMethod, BaseUrl+"/users/my-infos", nil)
+	req.Header.Set("Authorization", "Bearer "+first.AccessToken)
+	resp, _ = App.Test(req, -1)
+	assert.Equal(OK, resp.StatusCode, "refreshed access token, should be accepted")
+
+	code, second := refresh(first.RefreshToken, true)
+	assert.Equal(OK, code, "refresh token in cookie, should return OK")
+
+	// reusing a replaced token revokes the family
+	code, _ = refresh(login.RefreshToken, false)
+	assert.Equal(Unauthorized, code, "reused refresh token, should return Unauthorized")
+	code, _ = refresh(second.RefreshToken, false)
+	assert.Equal(Unauthorized, code, "latest refresh token of a reused family, should be revoked")
+
+	// other logins are not affected
+	req = httptest.NewRequest(PostMethod, BaseUrl+"/login", bytes.NewBuffer(credentials))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ = App.Test(req, -1)
+	var refreshCookie *http.Cookie
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name == "Refresh" {
+			refreshCookie = cookie
+		}
+	}
+	if assert.NotNil(refreshCookie, "cookie login, should set refresh cookie") {
+		assert.True(refreshCookie.HttpOnly)
+		code, _ = refresh(refreshCookie.Value, true)
+		assert.Equal(OK, code, "refresh token of another login, should return OK")
+	}
+}
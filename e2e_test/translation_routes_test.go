@@ -0,0 +1,178 @@
+package e2etest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/denisyao1/welsh-academy-api/model"
+	"github.com/denisyao1/welsh-academy-api/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTranslations(t *testing.T) {
+	assert := assert.New(t)
+
+	leek, _ := ingredientRepo.GetOrCreate("translationLeek")
+	lamb, _ := ingredientRepo.GetOrCreate("translationLamb")
+	cawl := model.Recipe{Name: "Translation Cawl", Making: "Simmer.", Ingredients: []model.Ingredient{leek, lamb}}
+	recipeRepo.GetOrCreate(&cawl)
+
+	code, authCookie := login("admin", "admin")
+	if code != 200 {
+		t.Log("Auth failed")
+		t.FailNow()
+	}
+
+	testCases := []struct {
+		url         string
+		body        string
+		statusCode  int
+		description string
+	}{
+		{fmt.Sprintf("/ingredients/%d/translations/cy", leek.ID), `{"name":"translationCennin"}`,
+			OK, "should translate ingredient"},
+		{fmt.Sprintf("/ingredients/%d/translations/cy", leek.ID), `{"name":" translationCennin "}`,
+			OK, "should replace ingredient translation"},
+		{fmt.Sprintf("/ingredients/%d/translations/cy", lamb.ID), `{"name":"TRANSLATIONCENNIN"}`,
+			Conflict, "same name in a locale should return conflict"},
+		{fmt.Sprintf("/ingredients/%d/translations/en", lamb.ID), `{"name":"translationLamb"}`,
+			BadRequest, "default locale should return bad request"},
+		{fmt.Sprintf("/ingredients/%d/translations/fr", lamb.ID), `{"name":"translationAgneau"}`,
+			BadRequest, "unsupported locale should return bad request"},
+		{fmt.Sprintf("/ingredients/%d/translations/cy", lamb.ID), `{"name":"  "}`,
+			BadRequest, "blank name should return bad request"},
+		{"/ingredients/100000/translations/cy", `{"name":"translationUnknown"}`,
+			NotFound, "unknown ingredient should return not found"},
+		{fmt.Sprintf("/recipes/%d/translations/cy", cawl.ID), `{"name":"Cawl Cymreig","making":"Mudferwi."}`,
+			OK, "should translate recipe"},
+		{fmt.Sprintf("/recipes/%d/translations/cy", cawl.ID), `{"name":"Cawl Cymreig"}`,
+			BadRequest, "missing making should return bad request"},
+		{"/recipes/100000/translations/cy", `{"name":"Dim","making":"Dim."}`,
+			NotFound, "unknown recipe should return not found"},
+	}
+
+	for _, tc := range testCases {
+		req := httptest.NewRequest(PutMethod, BaseUrl+tc.url, bytes.NewBufferString(tc.body))
+		req.Header.Set("Content-Type", "application/json")
+		req.AddCookie(authCookie)
+		resp, _ := App.Test(req, -1)
+		assert.Equal(tc.statusCode, resp.StatusCode, tc.description)
+	}
+
+	// list translations
+	req := httptest.NewRequest(GetMethod, fmt.Sprintf("%s/ingredients/%d/translations", BaseUrl, leek.ID), nil)
+	req.AddCookie(authCookie)
+	resp, _ := App.Test(req, -1)
+	assert.Equal(OK, resp.StatusCode, "should list ingredient translations")
+	var ingredientTranslations schema.IngredientTranslationsResponse
+	data, _ := io.ReadAll(resp.Body)
+	json.Unmarshal(data, &ingredientTranslations)
+	if assert.Equal(1, ingredientTranslations.Count) {
+		assert.Equal("translationCennin", ingredientTranslations.Translations[0].Name)
+	}
+
+	// content negotiation
+	localeCases := []struct {
+		query          string
+		acceptLanguage string
+		locale         string
+		recipeName     string
+		description    string
+	}{
+		{"", "", "en", "Translation Cawl", "should default to english"},
+		{"?lang=cy", "", "cy", "Cawl Cymreig", "lang query param should select welsh"},
+		{"", "cy-GB,en;q=0.5", "cy", "Cawl Cymreig", "Accept-Language should select welsh"},
+		{"?lang=en", "cy", "en", "Translation Cawl", "lang query param should win over Accept-Language"},
+		{"?lang=fr", "", "en", "Translation Cawl", "unsupported locale should fall back to english"},
+	}
+	for _, tc := range localeCases {
+		req := httptest.NewRequest(GetMethod, fmt.Sprintf("%s/recipes/%d%s", BaseUrl, cawl.ID, tc.query), nil)
+		if tc.acceptLanguage != "" {
+			req.Header.Set("Accept-Language", tc.acceptLanguage)
+		}
+		req.AddCookie(authCookie)
+		resp, _ := App.Test(req, -1)
+		assert.Equal(OK, resp.StatusCode, tc.description)
+		assert.Equal(tc.locale, resp.Header.Get("Content-Language"), tc.description)
+
+		var recipe model.Recipe
+		data, _ := io.ReadAll(resp.Body)
+		json.Unmarshal(data, &recipe)
+		assert.Equal(tc.recipeName, recipe.Name, tc.description)
+	}
+
+	// untranslated ingredients keep their name
+	req = httptest.NewRequest(GetMethod, BaseUrl+"/recipes?lang=cy&ingredients=translationCennin", nil)
+	req.AddCookie(authCookie)
+	resp, _ = App.Test(req, -1)
+	assert.Equal(OK, resp.StatusCode, "should filter recipes by translated ingredient")
+	var recipes schema.RecipesResponse
+	data, _ = io.ReadAll(resp.Body)
+	json.Unmarshal(data, &recipes)
+	if assert.Equal(1, recipes.Count, "should filter recipes by translated ingredient") {
+		recipe := recipes.Recipes[0]
+		assert.Equal("Cawl Cymreig", recipe.Name)
+		assert.Equal("Mudferwi.", recipe.Making)
+		var names []string
+		for _, ingredient := range recipe.Ingredients {
+			names = append(names, ingredient.Name)
+		}
+		assert.ElementsMatch([]string{"translationCennin", "translationLamb"}, names)
+	}
+
+	req = httptest.NewRequest(GetMethod, BaseUrl+"/ingredients?lang=cy", nil)
+	req.AddCookie(authCookie)
+	resp, _ = App.Test(req, -1)
+	assert.Equal(OK, resp.StatusCode, "should list localized ingredients")
+	var ingredients schema.IngredientsResponse
+	data, _ = io.ReadAll(resp.Body)
+	json.Unmarshal(data, &ingredients)
+	for _, ingredient := range ingredients.Ingredients {
+		if ingredient.ID == leek.ID {
+			assert.Equal("translationCennin", ingredient.Name, "should list localized ingredients")
+		}
+	}
+
+	// cookbooks are rendered in the asked language
+	body := fmt.Sprintf(`{"recipeIds":[%d]}`, cawl.ID)
+	req = httptest.NewRequest(PostMethod, BaseUrl+"/cookbooks?format=html&lang=cy", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.AddCookie(authCookie)
+	resp, _ = App.Test(req, -1)
+	assert.Equal(OK, resp.StatusCode, "should create localized cookbook")
+	data, _ = io.ReadAll(resp.Body)
+	cookbook := string(data)
+	assert.Contains(cookbook, `<html lang="cy">`, "cookbook should be in welsh")
+	assert.Contains(cookbook, "Cawl Cymreig", "cookbook should have the translated recipe")
+	assert.Contains(cookbook, "Mudferwi.", "cookbook should have the translated making")
+	assert.Contains(cookbook, "translationCennin", "cookbook should have the translated ingredients")
+
+	// delete translations
+	deleteCases := []struct {
+		url         string
+		statusCode  int
+		description string
+	}{
+		{fmt.Sprintf("/recipes/%d/translations/cy", cawl.ID), OK, "should delete recipe translation"},
+		{fmt.Sprintf("/recipes/%d/translations/cy", cawl.ID), NotFound, "deleted translation should return not found"},
+		{fmt.Sprintf("/ingredients/%d/translations/cy", leek.ID), OK, "should delete ingredient translation"},
+	}
+	for _, tc := range deleteCases {
+		req := httptest.NewRequest(DeleteMethod, BaseUrl+tc.url, nil)
+		req.AddCookie(authCookie)
+		resp, _ := App.Test(req, -1)
+		assert.Equal(tc.statusCode, resp.StatusCode, tc.description)
+	}
+
+	req = httptest.NewRequest(GetMethod, fmt.Sprintf("%s/recipes/%d?lang=cy", BaseUrl, cawl.ID), nil)
+	req.AddCookie(authCookie)
+	resp, _ = App.Test(req, -1)
+	var recipe model.Recipe
+	data, _ = io.ReadAll(resp.Body)
+	json.Unmarshal(data, &recipe)
+	assert.Equal("Translation Cawl", recipe.Name, "deleted translation should fall back to english")
+}
@@ -0,0 +1,154 @@
+package e2etest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/denisyao1/welsh-academy-api/model"
+	"github.com/denisyao1/welsh-academy-api/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeleteRestoreAndPurge(t *testing.T) {
+	assert := assert.New(t)
+
+	// create admin user if not exist
+	userService.CreateIfNotExist(&model.User{Username: "admin", Password: "admin", Roles: []model.UserRole{{Role: model.RoleAdmin}}})
+
+	// create the items to delete
+	ingredient, _ := ingredientRepo.GetOrCreate("trashIngredient")
+	recipe := model.Recipe{
+		Name:        "trashRecipe",
+		Making:      "dummy",
+		Ingredients: []model.Ingredient{ingredient}}
+	recipeRepo.GetOrCreate(&recipe)
+	user := model.User{Username: "trashUser", Password: "trash"}
+	userService.CreateIfNotExist(&user)
+
+	// rows of the user to purge with it
+	db := InMemoryDB.GetDB()
+	if loginForToken("trashUser", "trash", "trash").RefreshToken == "" {
+		t.Log("Auth failed")
+		t.FailNow()
+	}
+	db.Create(&model.MFAChallenge{ID: "trashChallenge", UserID: user.ID, ExpiresAt: time.Now().Add(time.Minute)})
+
+	code, authCookie := login("admin", "admin")
+	if code != 200 {
+		t.Log("Auth failed")
+		t.FailNow()
+	}
+
+	send := func(method, url string) int {
+		req := httptest.NewRequest(method, BaseUrl+url, nil)
+		req.AddCookie(authCookie)
+		resp, _ := App.Test(req, -1)
+		return resp.StatusCode
+	}
+
+	// delete items
+	deleteCases := []struct {
+		url         string
+		statusCode  int
+		description string
+	}{
+		{
+			url:         fmt.Sprintf("/recipes/%v", recipe.ID),
+			statusCode:  OK,
+			description: "existing recipe, should be moved to trash",
+		},
+		{
+			url:         fmt.Sprintf("/recipes/%v", recipe.ID),
+			statusCode:  NotFound,
+			description: "recipe already in trash, should return not found",
+		},
+		{
+			url:         fmt.Sprintf("/ingredients/%v", ingredient.ID),
+			statusCode:  OK,
+			description: "existing ingredient, should be moved to trash",
+		},
+		{
+			url:         fmt.Sprintf("/users/%v", user.ID),
+			statusCode:  OK,
+			description: "existing user, should be moved to trash",
+		},
+		{
+			url:         "/users/0",
+			statusCode:  NotFound,
+			description: "user 0 doesn't exist, should return not found",
+		},
+	}
+	for _, tt := range deleteCases {
+		assert.Equal(tt.statusCode, send(DeleteMethod, tt.url), tt.description)
+	}
+
+	// deleted items are hidden
+	_, err := recipeRepo.GetByID(recipe.ID)
+	assert.Error(err, "deleted recipe should not be found")
+	named, _ := ingredientRepo.FindNamed([]string{"trashIngredient"})
+	assert.Equal(0, len(named), "deleted ingredient should not be found")
+	code, _ = login("trashUser", "trash")
+	assert.Equal(Unauthorized, code, "deleted user should not be able to login")
+
+	// deleted items are in the trash
+	req := httptest.NewRequest(GetMethod, BaseUrl+"/admin/trash", nil)
+	req.AddCookie(authCookie)
+	resp, _ := App.Test(req, -1)
+	assert.Equal(OK, resp.StatusCode, "trash listing should be OK")
+	body, _ := io.ReadAll(resp.Body)
+	trash := schema.TrashResponse{}
+	json.Unmarshal(body, &trash)
+	assert.Equal(3, trash.Count, "trash should contain 3 items but got %v", trash.Count)
+
+	// restore items
+	restoreCases := []struct {
+		url         string
+		statusCode  int
+		description string
+	}{
+		{
+			url:         fmt.Sprintf("/admin/trash/recipe/%v/restore", recipe.ID),
+			statusCode:  OK,
+			description: "recipe in trash, should be restored",
+		},
+		{
+			url:         fmt.Sprintf("/admin/trash/recipe/%v/restore", recipe.ID),
+			statusCode:  NotFound,
+			description: "recipe not in trash, should return not found",
+		},
+		{
+			url:         fmt.Sprintf("/admin/trash/cake/%v/restore", recipe.ID),
+			statusCode:  BadRequest,
+			description: "unknown item type, should return bad request",
+		},
+	}
+	for _, tt := range restoreCases {
+		assert.Equal(tt.statusCode, send(PostMethod, tt.url), tt.description)
+	}
+
+	_, err = recipeRepo.GetByID(recipe.ID)
+	assert.NoError(err, "restored recipe should be found")
+
+	// purge the remaining items, test retention is 0
+	req = httptest.NewRequest(DeleteMethod, BaseUrl+"/admin/trash", nil)
+	req.AddCookie(authCookie)
+	resp, _ = App.Test(req, -1)
+	assert.Equal(OK, resp.StatusCode, "trash purge should be OK")
+	body, _ = io.ReadAll(resp.Body)
+	purged := schema.PurgeResponse{}
+	json.Unmarshal(body, &purged)
+	assert.Equal(int64(1), purged.Ingredients, "one ingredient should be purged")
+	assert.Equal(int64(1), purged.Users, "one user should be purged")
+	for _, table := range []string{"sessions", "refresh_tokens", "mfa_challenges"} {
+		var rows int64
+		db.Table(table).Where("user_id = ?", user.ID).Count(&rows)
+		assert.Zero(rows, "%s of purged user, should be removed", table)
+	}
+
+	restoreUrl := fmt.Sprintf("/admin/trash/user/%v/restore", user.ID)
+	assert.Equal(NotFound, send(PostMethod, restoreUrl), "purged user should not be restorable")
+}
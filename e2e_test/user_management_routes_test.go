@@ -0,0 +1,201 @@
+package e2etest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/denisyao1/welsh-academy-api/database"
+	"github.com/denisyao1/welsh-academy-api/exception"
+	"github.com/denisyao1/welsh-academy-api/model"
+	"github.com/denisyao1/welsh-academy-api/repository"
+	"github.com/denisyao1/welsh-academy-api/schema"
+	"github.com/denisyao1/welsh-academy-api/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserManagement(t *testing.T) {
+	assert := assert.New(t)
+
+	userService.CreateIfNotExist(&model.User{Username: "admin", Password: "admin", Roles: []model.UserRole{{Role: model.RoleAdmin}}})
+	first := model.User{Username: "mgmtFirst", Password: "first"}
+	second := model.User{Username: "mgmtSecond", Password: "second"}
+	if userService.CreateIfNotExist(&first) != nil || userService.CreateIfNotExist(&second) != nil {
+		t.FailNow()
+	}
+
+	admin := loginForToken("admin", "admin", "management")
+	if admin.AccessToken == "" {
+		t.Log("Auth failed")
+		t.FailNow()
+	}
+
+	// list and search
+	listTestCases := []struct {
+		query       string
+		statusCode  int
+		count       int
+		username    string
+		description string
+	}{
+		{"?search=%20MGMT", OK, 2, "mgmtFirst", "search ignoring case and spaces, should return both users"},
+		{"?search=mgmt&limit=1&page=2", OK, 1, "mgmtSecond", "second page, should return the second user"},
+		{"?search=mgmt&page=3&limit=1", OK, 0, "", "page after the last one, should be empty"},
+		{"?search=m%25", OK, 0, "", "wildcard in search, should be matched literally"},
+		{"?page=0", BadRequest, 0, "", "page 0, should return Bad Request"},
+		{"?limit=100", BadRequest, 0, "", "limit above 50, should return Bad Request"},
+	}
+
+	for _, tc := range listTestCases {
+		code, data := bearerRequest(GetMethod, "/users"+tc.query, "", admin.AccessToken)
+		assert.Equal(tc.statusCode, code, tc.description)
+		if code != OK {
+			continue
+		}
+		var response schema.UsersResponse
+		json.Unmarshal(data, &response)
+		assert.Equal(tc.count, response.Count, tc.description)
+		if tc.username != "" && assert.NotEmpty(response.Users, tc.description) {
+			assert.Equal(tc.username, response.Users[0].Username, tc.description)
+			assert.Equal(int64(2), response.Total, tc.description)
+		}
+	}
+
+	// get and update
+	firstURL := fmt.Sprintf("/users/%d", first.ID)
+	code, data := bearerRequest(GetMethod, firstURL, "", admin.AccessToken)
+	assert.Equal(OK, code, "get user, should return OK")
+	var user model.User
+	json.Unmarshal(data, &user)
+	assert.Equal(first.ID, user.ID)
+	assert.Equal([]model.Role{model.RoleReader}, user.RoleNames())
+
+	code, _ = bearerRequest(GetMethod, "/users/100000", "", admin.AccessToken)
+	assert.Equal(NotFound, code, "get unknown user, should return Not Found")
+
+	code, data = bearerRequest(PatchMethod, firstURL, `{"roles":["contributor"]}`, admin.AccessToken)
+	assert.Equal(OK, code, "update roles, should return OK")
+	user = model.User{}
+	json.Unmarshal(data, &user)
+	assert.Equal([]model.Role{model.RoleContributor}, user.RoleNames(), "update roles, should return the new roles")
+
+	code, data = bearerRequest(PatchMethod, firstURL, `{}`, admin.AccessToken)
+	assert.Equal(OK, code, "update nothing, should return OK")
+	user = model.User{}
+	json.Unmarshal(data, &user)
+	assert.Equal([]model.Role{model.RoleContributor}, user.RoleNames(), "update nothing, should keep the roles")
+
+	code, _ = bearerRequest(PatchMethod, firstURL, `{"roles":["chef"]}`, admin.AccessToken)
+	assert.Equal(BadRequest, code, "update with unknown role, should return Bad Request")
+
+	// disable and enable
+	token := loginForToken("mgmtFirst", "first", "management")
+	code, _ = bearerRequest(PostMethod, firstURL+"/disable", "", admin.AccessToken)
+	assert.Equal(OK, code, "disable user, should return OK")
+	code, _ = bearerRequest(GetMethod, "/users/my-infos", "", token.AccessToken)
+	assert.Equal(Unauthorized, code, "token of disabled user, should be rejected")
+	code, _ = refresh(token.RefreshToken, false)
+	assert.Equal(Unauthorized, code, "refresh token of disabled user, should be rejected")
+	code, _ = login("mgmtFirst", "first")
+	assert.Equal(Forbidden, code, "login of disabled user, should return Forbidden")
+
+	code, data = bearerRequest(GetMethod, firstURL, "", admin.AccessToken)
+	user = model.User{}
+	json.Unmarshal(data, &user)
+	assert.NotNil(user.DisabledAt, "disabled user, should show when it was disabled")
+
+	code, _ = bearerRequest(PostMethod, firstURL+"/enable", "", admin.AccessToken)
+	assert.Equal(OK, code, "enable user, should return OK")
+	code, _ = login("mgmtFirst", "first")
+	assert.Equal(OK, code, "login of enabled user, should return OK")
+
+	adminUser := model.User{Username: "admin"}
+	userRepo.GetByUsername(&adminUser)
+	code, _ = bearerRequest(PostMethod, fmt.Sprintf("/users/%d/disable", adminUser.ID), "", admin.AccessToken)
+	assert.Equal(BadRequest, code, "disable own account, should return Bad Request")
+	code, _ = bearerRequest(PostMethod, "/users/100000/disable", "", admin.AccessToken)
+	assert.Equal(NotFound, code, "disable unknown user, should return Not Found")
+
+	// temporary password
+	code, data = bearerRequest(PostMethod, firstURL+"/reset-password", "", admin.AccessToken)
+	assert.Equal(OK, code, "reset password, should return OK")
+	var temporary schema.TemporaryPassword
+	json.Unmarshal(data, &temporary)
+	assert.Len(temporary.Password, 16)
+	code, _ = login("mgmtFirst", "first")
+	assert.Equal(Unauthorized, code, "login with former password, should return Unauthorized")
+	code, _ = login("mgmtFirst", temporary.Password)
+	assert.Equal(OK, code, "login with temporary password, should return OK")
+
+	// favorites are removed with the user
+	db := InMemoryDB.GetDB()
+	db.Exec("INSERT INTO user_favorites (user_id, recipe_id) VALUES (?, ?)", second.ID, 100000)
+	code, _ = bearerRequest(DeleteMethod, fmt.Sprintf("/users/%d", second.ID), "", admin.AccessToken)
+	assert.Equal(OK, code, "delete user, should return OK")
+	var favorites int64
+	db.Table("user_favorites").Where("user_id = ?", second.ID).Count(&favorites)
+	assert.Zero(favorites, "favorites of deleted user, should be removed")
+}
+
+func TestTokenOfDisabledUser(t *testing.T) {
+	assert := assert.New(t)
+
+	disabled := model.User{Username: "statusDisabled", Password: "disabled"}
+	deleted := model.User{Username: "statusDeleted", Password: "deleted"}
+	if userService.CreateIfNotExist(&disabled) != nil || userService.CreateIfNotExist(&deleted) != nil {
+		t.FailNow()
+	}
+	disabledToken := loginForToken("statusDisabled", "disabled", "status")
+	deletedToken := loginForToken("statusDeleted", "deleted", "status")
+	if disabledToken.AccessToken == "" || deletedToken.AccessToken == "" {
+		t.Log("Auth failed")
+		t.FailNow()
+	}
+
+	// as done by another instance of the API, whose revocations are not known yet
+	now := time.Now()
+	userRepo.SetDisabledAt(disabled.ID, &now)
+	userRepo.Delete(deleted.ID)
+
+	code, _ := bearerRequest(GetMethod, "/users/my-infos", "", disabledToken.AccessToken)
+	assert.Equal(Unauthorized, code, "valid token of disabled user, should be rejected")
+	code, _ = bearerRequest(GetMethod, "/users/my-infos", "", deletedToken.AccessToken)
+	assert.Equal(Unauthorized, code, "valid token of deleted user, should be rejected")
+}
+
+func TestLastAdmin(t *testing.T) {
+	assert := assert.New(t)
+
+	db, _ := database.NewInMemoryDB(false)
+	db.MigrateAll()
+	repo := repository.NewUserRepository(db.GetDB())
+	keys, _ := service.NewKeyService(SigningKey)
+	passwords, _ := service.NewPasswordPolicy(Config.PASSWORD_MIN_LENGTH, Config.PASSWORD_MIN_CHAR_CLASSES, Config.BCRYPT_COST)
+	revocations := service.NewRevocationService(repository.NewGormRevocationRepository(db.GetDB()))
+	adminService := service.NewUserService(repo, repository.NewGormSessionRepository(db.GetDB()),
+		repository.NewGormRefreshTokenRepository(db.GetDB()), revocations, service.NewUserStatusService(repo), keys, passwords,
+		nil, repository.NewGormMFARepository(db.GetDB()), false, time.Minute, time.Hour)
+
+	first := model.User{Username: "firstAdmin", Password: "First-admin1", Roles: []model.UserRole{{Role: model.RoleAdmin}}}
+	second := model.User{Username: "secondAdmin", Password: "Second-admin1", Roles: []model.UserRole{{Role: model.RoleAdmin}}}
+	if adminService.CreateIfNotExist(&first) != nil || adminService.CreateIfNotExist(&second) != nil {
+		t.FailNow()
+	}
+
+	assertLastAdmin := func(err error, description string) {
+		var errCode *exception.Error
+		if assert.True(errors.As(err, &errCode), description) {
+			assert.Equal(exception.CodeLastAdmin, errCode.Code, description)
+		}
+	}
+
+	assert.NoError(adminService.Disable(first.ID), "disable an admin with another one, should succeed")
+	assertLastAdmin(adminService.Disable(second.ID), "disable the last enabled admin, should fail")
+	assertLastAdmin(adminService.Delete(second.ID), "delete the last enabled admin, should fail")
+	_, err := adminService.SetRoles(second.ID, schema.UserRoles{Roles: []model.Role{model.RoleReader}})
+	assertLastAdmin(err, "remove the admin role of the last enabled admin, should fail")
+
+	assert.NoError(adminService.Delete(first.ID), "delete a disabled admin, should succeed")
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/denisyao1/welsh-academy-api/model"
@@ -16,7 +17,7 @@ import (
 func TestLogin(t *testing.T) {
 	t.Parallel()
 	// create admin user if not exist
-	userService.CreateIfNotExist(&model.User{Username: "admin", Password: "admin", IsAdmin: true})
+	userService.CreateIfNotExist(&model.User{Username: "admin", Password: "admin", Roles: []model.UserRole{{Role: model.RoleAdmin}}})
 
 	assert := assert.New(t)
 	testCases := []struct {
@@ -29,6 +30,11 @@ func TestLogin(t *testing.T) {
 			statusCode:  200,
 			description: "login with correct credentials",
 		},
+		{
+			inputs:      []byte(`{"username":" Admin", "password": "admin"}`),
+			statusCode:  200,
+			description: "login with username differing by case and spaces",
+		},
 		{
 			inputs:      []byte(`{"username":"adm", "password": "admin"}`),
 			statusCode:  401,
@@ -58,7 +64,8 @@ func TestLogin(t *testing.T) {
 		assert.Equal(tt.statusCode, resp.StatusCode, tt.description)
 		cookie := resp.Cookies()
 		if resp.StatusCode == 200 {
-			assert.Equal(1, len(cookie), tt.description)
+			// access and refresh tokens
+			assert.Equal(2, len(cookie), tt.description)
 		} else {
 			assert.Equal(0, len(cookie), tt.description)
 		}
@@ -70,7 +77,7 @@ func TestLogout(t *testing.T) {
 	assert := assert.New(t)
 
 	// create admin user if not exist
-	userService.CreateIfNotExist(&model.User{Username: "admin", Password: "admin", IsAdmin: true})
+	userService.CreateIfNotExist(&model.User{Username: "admin", Password: "admin", Roles: []model.UserRole{{Role: model.RoleAdmin}}})
 
 	// login first
 	code, _ := login("admin", "admin")
@@ -120,7 +127,7 @@ func TestCreateUser(t *testing.T) {
 	assert := assert.New(t)
 
 	// create admin user if not exist
-	userService.CreateIfNotExist(&model.User{Username: "admin", Password: "admin", IsAdmin: true})
+	userService.CreateIfNotExist(&model.User{Username: "admin", Password: "admin", Roles: []model.UserRole{{Role: model.RoleAdmin}}})
 
 	// login admin user first
 	code, authCookie := login("admin", "admin")
@@ -144,31 +151,61 @@ func TestCreateUser(t *testing.T) {
 		},
 		{
 			username:    "test",
-			password:    "tes",
+			password:    "Te5t",
 			statusCode:  400,
 			description: "short paswword, status should be bad request",
 		},
+		{
+			username:    "test",
+			password:    "testtest",
+			statusCode:  400,
+			description: "password of a single character class, status should be bad request",
+		},
+		{
+			username:    "test",
+			password:    "Password1",
+			statusCode:  400,
+			description: "breached password, status should be bad request",
+		},
+		{
+			username:    "testUser1",
+			password:    "TESTUSER1",
+			statusCode:  400,
+			description: "password equal to username, status should be bad request",
+		},
+		{
+			username:    "test",
+			password:    strings.Repeat("Té5t", 15),
+			statusCode:  400,
+			description: "password over 72 bytes, status should be bad request",
+		},
 		{
 			username:    "admin",
-			password:    "test",
+			password:    "Test-1234",
 			statusCode:  409,
 			description: "existing username, status should be conflict",
 		},
 		{
 			username:    "testUser",
-			password:    "testUser",
+			password:    "Test-user",
 			statusCode:  201,
 			description: "correct inputs, status shoud be Created",
 		},
 		{
 			username:    "testUser",
-			password:    "testUser",
+			password:    "Test-user",
 			statusCode:  409,
 			description: "existing username, status should be conflict",
 		},
+		{
+			username:    "TESTUSER",
+			password:    "Test-user",
+			statusCode:  409,
+			description: "username differing by case, status should be conflict",
+		},
 		{
 			username:    "testAdmin",
-			password:    "testAdmin",
+			password:    "Test-admin",
 			statusCode:  201,
 			description: "good username and password, status should be Created",
 			isAdmin:     true,
@@ -177,23 +214,27 @@ func TestCreateUser(t *testing.T) {
 
 	url := "/api/v1/users"
 	for _, tt := range testCases {
-		json_inputs := fmt.Sprintf(`{"username":"%s", "password":"%s","isAdmin":"%v"}`,
-			tt.username, tt.password, tt.isAdmin)
+		roles := `[]`
+		if tt.isAdmin {
+			roles = `["admin"]`
+		}
+		json_inputs := fmt.Sprintf(`{"username":"%s", "password":"%s","roles":%s}`,
+			tt.username, tt.password, roles)
 		inputs := []byte(json_inputs)
 		req := httptest.NewRequest(PostMethod, url, bytes.NewBuffer(inputs))
 		req.Header.Set("Content-Type", "application/json")
 		req.AddCookie(authCookie)
 		resp, _ := App.Test(req, -1)
 		assert.Equal(tt.statusCode, resp.StatusCode, tt.description)
-		if resp.StatusCode == 200 {
+		if resp.StatusCode == 201 {
 			userRepo := repository.NewUserRepository(InMemoryDB.GetDB())
-			user := model.User{Username: "test"}
+			user := model.User{Username: tt.username}
 			userRepo.GetByUsername(&user)
 			assert.NotEqual(0, user.ID, "test user should be in the DB")
 			if tt.isAdmin {
-				assert.True(user.IsAdmin, "Admin user should have isAdmin set to true in DB")
+				assert.Equal([]model.Role{model.RoleAdmin}, user.RoleNames(), "Admin user should have the admin role in DB")
 			} else {
-				assert.False(user.IsAdmin, "Non admin user should have isAdmin set to false in DB")
+				assert.Equal([]model.Role{model.RoleReader}, user.RoleNames(), "Other users should have the reader role in DB")
 			}
 		}
 	}
@@ -205,7 +246,7 @@ func TestUpdatePassword(t *testing.T) {
 	assert := assert.New(t)
 
 	// if not exist create user test
-	userService.CreateIfNotExist(&model.User{Username: "testPass", Password: "test", IsAdmin: false})
+	userService.CreateIfNotExist(&model.User{Username: "testPass", Password: "test"})
 
 	// login
 	code, authCookie := login("testPass", "test")
@@ -216,35 +257,63 @@ func TestUpdatePassword(t *testing.T) {
 
 	// run test case using Auth cookie
 	testCases := []struct {
-		password    string
-		statusCode  int
-		description string
+		currentPassword string
+		password        string
+		statusCode      int
+		description     string
 	}{
 		{
-			password:    "te",
+			currentPassword: "test",
+			password:        "te",
+			statusCode:      400,
+			description:     "short password, should return Bad Request",
+		},
+		{
+			password:    "Pass-word",
 			statusCode:  400,
-			description: "short password, should return Bad Request",
+			description: "missing current password, should return Bad Request",
 		},
 		{
-			password:    "password",
-			statusCode:  200,
-			description: "good, should return OK",
+			currentPassword: "wrong",
+			password:        "Pass-word",
+			statusCode:      400,
+			description:     "wrong current password, should return Bad Request",
 		},
 		{
-			password:    "password",
-			statusCode:  400,
-			description: "same password has precedent, should return Bad Request",
+			currentPassword: "test",
+			password:        "passw0rd",
+			statusCode:      400,
+			description:     "breached password, should return Bad Request",
 		},
 		{
-			password:    "test",
-			statusCode:  200,
-			description: "new password, should return OK",
+			currentPassword: "test",
+			password:        "TestPass",
+			statusCode:      400,
+			description:     "password equal to username, should return Bad Request",
+		},
+		{
+			currentPassword: "test",
+			password:        "Pass-word",
+			statusCode:      200,
+			description:     "good, should return OK",
+		},
+		{
+			currentPassword: "Pass-word",
+			password:        "Pass-word",
+			statusCode:      400,
+			description:     "same password has precedent, should return Bad Request",
+		},
+		{
+			currentPassword: "Pass-word",
+			password:        "Test-pass",
+			statusCode:      200,
+			description:     "new password, should return OK",
 		},
 	}
 	url := "/api/v1/users/password-change"
 
 	for _, tt := range testCases {
-		json := fmt.Sprintf(`{"password":"%s"}`, tt.password)
+		json := fmt.Sprintf(`{"currentPassword":"%s","password":"%s"}`, tt.currentPassword, tt.password)
 		inputs := []byte(json)
 		req := httptest.NewRequest(PatchMethod, url, bytes.NewBuffer(inputs))
 		req.Header.Set("Content-Type", "application/json")
@@ -252,6 +321,7 @@ func TestUpdatePassword(t *testing.T) {
 		resp, _ := App.Test(req, -1)
 		assert.Equal(tt.statusCode, resp.StatusCode, tt.description)
 		if resp.StatusCode == 200 {
+			assert.Len(resp.Cookies(), 2, "password update, should set new tokens in cookies")
 			code, _ := login("testPass", tt.password)
 			assert.Equal(200, code, "Login  should not failed after password update")
 		}
@@ -266,7 +336,7 @@ func TestGetUserInfos(t *testing.T) {
 	assert := assert.New(t)
 
 	// if not exist create user test
-	userService.CreateIfNotExist(&model.User{Username: "test", Password: "test", IsAdmin: false})
+	userService.CreateIfNotExist(&model.User{Username: "test", Password: "test"})
 
 	testCases := []struct {
 		username    string
@@ -311,7 +381,7 @@ func TestGetUserInfos(t *testing.T) {
 		if resp.StatusCode == 200 {
 			u := model.User{}
 			json.Unmarshal(msg, &u)
-			v := u.IsAdmin
+			v := u.HasRole(model.RoleAdmin)
 			assert.Equal(tt.admin, v, "expected admin=%v but got value=%v", tt.admin, v)
 		}
 
@@ -2,43 +2,60 @@ package e2etest
 
 import (
 	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
 	"fmt"
 	"log"
 	"net/http"
 	"net/http/httptest"
+	"time"
 
 	"github.com/denisyao1/welsh-academy-api/common"
 	"github.com/denisyao1/welsh-academy-api/controller"
 	"github.com/denisyao1/welsh-academy-api/database"
+	"github.com/denisyao1/welsh-academy-api/model"
 	"github.com/denisyao1/welsh-academy-api/repository"
 	"github.com/denisyao1/welsh-academy-api/router"
 	"github.com/denisyao1/welsh-academy-api/service"
 	"github.com/gofiber/fiber/v2"
+	"golang.org/x/crypto/bcrypt"
 )
 
 var (
-	GetMethod   = "GET"
-	PostMethod  = "POST"
-	PatchMethod = "PATCH"
-	BaseUrl     = "/api/v1"
+	GetMethod    = "GET"
+	PostMethod   = "POST"
+	PutMethod    = "PUT"
+	PatchMethod  = "PATCH"
+	DeleteMethod = "DELETE"
+	BaseUrl      = "/api/v1"
 )
 
 var (
-	BadRequest   = 400
-	OK           = 200
-	Created      = 201
-	Unauthorized = 401
-	Conflict     = 409
+	BadRequest      = 400
+	OK              = 200
+	Created         = 201
+	Accepted        = 202
+	Unauthorized    = 401
+	Forbidden       = 403
+	NotFound        = 404
+	Conflict        = 409
+	TooManyRequests = 429
 )
 
 var (
-	InMemoryDB     database.GormDB
-	Config         = common.Configuration{JWT_SECRET: "test"}
-	userRepo       repository.UserRepository
-	userService    service.UserService
-	ingredientRepo repository.IngredientRepository
-	recipeRepo     repository.RecipeRepository
-	App            = CreateTestApp()
+	InMemoryDB database.GormDB
+	Config     = common.Configuration{JWT_SECRET: "test", ACCESS_TOKEN_MINUTES: 15, REFRESH_TOKEN_DAYS: 30,
+		PASSWORD_MIN_LENGTH: 8, PASSWORD_MIN_CHAR_CLASSES: 2, BCRYPT_COST: bcrypt.MinCost,
+		LOGIN_MAX_FAILURES: 3, LOGIN_MAX_FAILURES_PER_IP: 1000, LOGIN_LOCKOUT_MINUTES: 15}
+	userRepo              repository.UserRepository
+	userService           service.UserService
+	ingredientRepo        repository.IngredientRepository
+	recipeRepo            repository.RecipeRepository
+	recommendationService service.RecommendationService
+	SigningKey            ed25519.PrivateKey
+	PreviousKey           *rsa.PrivateKey // rotated key, still verifying tokens
+	App                   = CreateTestApp()
 )
 
 func CreateTestApp() *fiber.App {
@@ -52,25 +69,76 @@ func CreateTestApp() *fiber.App {
 	InMemoryDB.MigrateAll()
 
 	ingredientRepo = repository.NewGormIngredientRepository(InMemoryDB.GetDB())
+	recipeRepo = repository.NewGormRecipeRepository(InMemoryDB.GetDB())
+
+	translationRepo := repository.NewGormTranslationRepository(InMemoryDB.GetDB())
+	translationService := service.NewTranslationService(translationRepo, ingredientRepo, recipeRepo)
+	translationController := controller.NewTranslationController(translationService)
+
 	ingredientService := service.NewIngredientService(ingredientRepo)
-	ingredienController := controller.NewIngredientController(ingredientService)
+	ingredienController := controller.NewIngredientController(ingredientService, translationService)
 
-	recipeRepo = repository.NewGormRecipeRepository(InMemoryDB.GetDB())
 	recipeService := service.NewRecipeService(recipeRepo, ingredientRepo)
-	recipeController := controller.NewRecipeController(recipeService)
+	substitutionRepo := repository.NewGormSubstitutionRepository(InMemoryDB.GetDB())
+	substitutionService := service.NewSubstitutionService(substitutionRepo, ingredientRepo)
+	substitutionController := controller.NewSubstitutionController(substitutionService, translationService)
 
-	userRepo = repository.NewUserRepository(InMemoryDB.GetDB())
+	recipeController := controller.NewRecipeController(recipeService, substitutionService, translationService)
 
-	userService = service.NewUserService(userRepo, Config.JWT_SECRET)
+	userRepo = repository.NewUserRepository(InMemoryDB.GetDB())
 
-	// create default admin user
-	userService.CreateDefaultAdmin()
+	sessionRepo := repository.NewGormSessionRepository(InMemoryDB.GetDB())
+	tokenRepo := repository.NewGormRefreshTokenRepository(InMemoryDB.GetDB())
+	revocationService := service.NewRevocationService(repository.NewGormRevocationRepository(InMemoryDB.GetDB()))
+	_, SigningKey, _ = ed25519.GenerateKey(rand.Reader)
+	PreviousKey, _ = rsa.GenerateKey(rand.Reader, 2048)
+	keyService, err := service.NewKeyService(SigningKey, &PreviousKey.PublicKey)
+	if err != nil {
+		log.Fatalln("Unable to create signing keys")
+	}
+	keyController := controller.NewKeyController(keyService)
+	passwordPolicy, err := service.NewPasswordPolicy(Config.PASSWORD_MIN_LENGTH, Config.PASSWORD_MIN_CHAR_CLASSES, Config.BCRYPT_COST)
+	if err != nil {
+		log.Fatalln("Unable to create password policy")
+	}
+	loginThrottle := service.NewLoginThrottle(repository.NewGormLoginFailureRepository(InMemoryDB.GetDB()),
+		Config.LOGIN_MAX_FAILURES, Config.LOGIN_MAX_FAILURES_PER_IP,
+		time.Duration(Config.LOGIN_BACKOFF_SECONDS)*time.Second, time.Duration(Config.LOGIN_LOCKOUT_MINUTES)*time.Minute)
+	mfaRepo := repository.NewGormMFARepository(InMemoryDB.GetDB())
+	userStatusService := service.NewUserStatusService(userRepo)
+	userService = service.NewUserService(userRepo, sessionRepo, tokenRepo, revocationService, userStatusService, keyService, passwordPolicy, loginThrottle,
+		mfaRepo, Config.MFA_REQUIRED_FOR_ADMINS, time.Duration(Config.ACCESS_TOKEN_MINUTES)*time.Minute, time.Duration(Config.REFRESH_TOKEN_DAYS)*24*time.Hour)
+
+	// create admin user, without password to change
+	admin := model.User{Username: "admin", Password: "admin", Roles: []model.UserRole{{Role: model.RoleAdmin}}}
+	if err = userService.CreateIfNotExist(&admin); err != nil {
+		log.Fatalln("Unable to create admin user")
+	}
 
 	userController := controller.NewUserController(userService)
 
-	router := router.New(ingredienController, recipeController, userController, Config.JWT_SECRET)
+	invitationService := service.NewInvitationService(repository.NewGormInvitationRepository(InMemoryDB.GetDB()), userService)
+	invitationController := controller.NewInvitationController(invitationService)
+
+	retention := time.Duration(Config.TRASH_RETENTION_DAYS) * 24 * time.Hour
+	trashService := service.NewTrashService(ingredientRepo, recipeRepo, userRepo, retention)
+	trashController := controller.NewTrashController(trashService)
+
+	catalogueRepo := repository.NewGormCatalogueRepository(InMemoryDB.GetDB())
+	catalogueService := service.NewCatalogueService(catalogueRepo, ingredientRepo, recipeRepo)
+	catalogueController := controller.NewCatalogueController(catalogueService)
+
+	cookbookService := service.NewCookbookService(recipeRepo, translationService)
+	cookbookController := controller.NewCookbookController(cookbookService)
+
+	recommendationService = service.NewRecommendationService(recipeRepo)
+	recommendationController := controller.NewRecommendationController(recommendationService, translationService)
+
+	router := router.New(ingredienController, recipeController, userController, trashController,
+		catalogueController, cookbookController, recommendationController, substitutionController,
+		translationController, keyController, invitationController, keyService, revocationService, userStatusService)
 
-	app := fiber.New()
+	app := fiber.New(fiber.Config{ErrorHandler: controller.ErrorHandler})
 
 	router.InitRoutes(app)
 
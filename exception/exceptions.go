@@ -1,28 +1,93 @@
 package exception
 
 import (
-	"errors"
 	"fmt"
+	"strings"
+	"time"
+
+	"github.com/denisyao1/welsh-academy-api/model"
 )
 
 var (
-	ErrDuplicateKey       = errors.New("object with the same name already exists")
-	ErrInvalidCredentials = errors.New("invalid credentials")
-	ErrInvalidPassword    = errors.New("password is required and must be at leat 4 characters long")
-	ErrRecordNotFound     = errors.New("not found")
-	ErrPasswordSame       = errors.New("password isn't new")
-	ErrMalFormedJWT       = errors.New("missed or malformed token")
+	ErrDuplicateKey        = New(CodeDuplicateKey)
+	ErrInvalidCredentials  = New(CodeInvalidCredentials)
+	ErrRecordNotFound      = New(CodeNotFound)
+	ErrPasswordSame        = New(CodePasswordSame)
+	ErrMalFormedJWT        = New(CodeMalformedToken)
+	ErrInvalidRefreshToken = New(CodeInvalidRefreshToken)
 )
 
+// Error is an error whose message comes from the message catalogue,
+// so that it can be shown to users in their language.
+type Error struct {
+	Code Code
+	Args []any
+
+	// time after which the request can be retried, sent in the Retry-After header
+	RetryAfter time.Duration
+}
+
+// New returns new Error with the code and the arguments of its message.
+func New(code Code, args ...any) *Error {
+	return &Error{Code: code, Args: args}
+}
+
+// NewRetryLater returns new Error of a request which can be retried after a delay.
+func NewRetryLater(retryAfter time.Duration, code Code, args ...any) *Error {
+	return &Error{Code: code, Args: args, RetryAfter: retryAfter}
+}
+
+func (e *Error) Error() string {
+	return Message(model.DefaultLocale, e.Code, e.Args...)
+}
+
+// Localize returns the message of the error in a locale.
+func (e *Error) Localize(locale string) string {
+	return Message(locale, e.Code, e.Args...)
+}
+
 type ErrValidation struct {
-	Field       string `json:"field"`
-	Description string `json:"description"`
+	Field   string `json:"field"`
+	Code    Code   `json:"code"`
+	Message string `json:"message"`
+	args    []any
 }
 
 func (v ErrValidation) Error() string {
-	return fmt.Sprintf("ValidationErr: {field:%s, description:%s}", v.Field, v.Description)
+	return fmt.Sprintf("ValidationErr: {field:%s, code:%s, message:%s}", v.Field, v.Code, v.Message)
+}
+
+// Localize returns a copy of the error with its message in a locale.
+func (v ErrValidation) Localize(locale string) ErrValidation {
+	v.Message = Message(locale, v.Code, v.args...)
+	return v
+}
+
+func NewErrValidation(field string, code Code, args ...any) ErrValidation {
+	return ErrValidation{Field: field, Code: code, Message: Message(model.DefaultLocale, code, args...), args: args}
+}
+
+// ErrValidations lists the validation errors of a request, reported at once.
+type ErrValidations []ErrValidation
+
+func (v ErrValidations) Error() string {
+	messages := make([]string, 0, len(v))
+	for _, errValidation := range v {
+		messages = append(messages, errValidation.Error())
+	}
+	return strings.Join(messages, ", ")
 }
 
-func NewErrValidation(field string, description string) ErrValidation {
-	return ErrValidation{Field: field, Description: description}
+// NewErrValidations returns the errors as ErrValidations. Errors which aren't
+// ErrValidation are unexpected: the first of them is returned as is.
+func NewErrValidations(errs ...error) error {
+	var validationErrs ErrValidations
+	for _, err := range errs {
+		errValidation, ok := err.(ErrValidation)
+		if !ok {
+			return err
+		}
+		validationErrs = append(validationErrs, errValidation)
+	}
+	return validationErrs
 }
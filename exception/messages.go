@@ -0,0 +1,274 @@
+package exception
+
+import (
+	"fmt"
+
+	"github.com/denisyao1/welsh-academy-api/model"
+)
+
+// Code identifies an error. Codes are stable, clients can rely on them
+// instead of the messages which depend on the language.
+type Code string
+
+// codes of the errors returned in responses
+const (
+	CodeInternal                    Code = "internal_error"
+	CodeInvalidBody                 Code = "invalid_body"
+	CodeInvalidQuery                Code = "invalid_query"
+	CodeInvalidFile                 Code = "invalid_file"
+	CodeInvalidID                   Code = "invalid_id"
+	CodeInvalidRequest              Code = "invalid_request"
+	CodeBadRequest                  Code = "bad_request"
+	CodeMalformedToken              Code = "malformed_token"
+	CodeInvalidToken                Code = "invalid_token"
+	CodeInvalidRefreshToken         Code = "invalid_refresh_token"
+	CodeInvalidCredentials          Code = "invalid_credentials"
+	CodePasswordSame                Code = "password_same"
+	CodeDeleteOwnAccount            Code = "delete_own_account"
+	CodeDisableOwnAccount           Code = "disable_own_account"
+	CodeForbidden                   Code = "forbidden"
+	CodeUserDisabled                Code = "user_disabled"
+	CodePasswordChangeRequired      Code = "password_change_required"
+	CodeLastAdmin                   Code = "last_admin"
+	CodeTooManyLoginAttempts        Code = "too_many_login_attempts"
+	CodeMFASetupRequired            Code = "mfa_setup_required"
+	CodeMFAMandatory                Code = "mfa_mandatory"
+	CodeInvalidMFAChallenge         Code = "invalid_mfa_challenge"
+	CodeInvalidOTP                  Code = "invalid_otp"
+	CodeMFAAlreadyEnabled           Code = "mfa_already_enabled"
+	CodeMFANotEnrolled              Code = "mfa_not_enrolled"
+	CodeNotFound                    Code = "not_found"
+	CodeRouteNotFound               Code = "route_not_found"
+	CodeUserNotFound                Code = "user_not_found"
+	CodeIngredientNotFound          Code = "ingredient_not_found"
+	CodeRecipeNotFound              Code = "recipe_not_found"
+	CodeSubstitutionNotFound        Code = "substitution_not_found"
+	CodeTranslationNotFound         Code = "translation_not_found"
+	CodeTrashItemNotFound           Code = "trash_item_not_found"
+	CodeSessionNotFound             Code = "session_not_found"
+	CodeInvitationNotFound          Code = "invitation_not_found"
+	CodeDuplicateKey                Code = "duplicate_key"
+	CodeUsernameExists              Code = "username_exists"
+	CodeIngredientExists            Code = "ingredient_exists"
+	CodeRecipeExists                Code = "recipe_exists"
+	CodeSubstitutionExists          Code = "substitution_exists"
+	CodeIngredientTranslationExists Code = "ingredient_translation_exists"
+	CodeSimilarRecipes              Code = "similar_recipes"
+)
+
+// codes of the validation errors of request fields
+const (
+	CodeRequired            Code = "required"
+	CodeTooShort            Code = "too_short"
+	CodeEmptyList           Code = "empty_list"
+	CodeDuplicateItems      Code = "duplicate_items"
+	CodeTooLong             Code = "too_long"
+	CodeTooFewItems         Code = "too_few_items"
+	CodeTooManyItems        Code = "too_many_items"
+	CodeTooSmall            Code = "too_small"
+	CodeTooLarge            Code = "too_large"
+	CodeNotOneOf            Code = "not_one_of"
+	CodeInvalidLimit        Code = "invalid_limit"
+	CodeInvalidFormat       Code = "invalid_format"
+	CodeInvalidItemType     Code = "invalid_item_type"
+	CodeInvalidRowType      Code = "invalid_row_type"
+	CodeInvalidDuration     Code = "invalid_duration"
+	CodeInvalidMinutes      Code = "invalid_minutes"
+	CodeInvalidDocument     Code = "invalid_document"
+	CodeInvalidJSONLD       Code = "invalid_jsonld"
+	CodeInvalidJSONLine     Code = "invalid_json_line"
+	CodeInvalidHeader       Code = "invalid_header"
+	CodeFieldCount          Code = "field_count"
+	CodeNoRecipeInDocument  Code = "no_recipe_in_document"
+	CodeNoFavorites         Code = "no_favorites"
+	CodeUnknownIngredient   Code = "unknown_ingredient"
+	CodeUnknownRecipe       Code = "unknown_recipe"
+	CodeSelfSubstitute      Code = "self_substitute"
+	CodeDefaultLocale       Code = "default_locale"
+	CodeUnsupportedLocale   Code = "unsupported_locale"
+	CodePasswordTooLong     Code = "password_too_long"
+	CodePasswordCharClasses Code = "password_char_classes"
+	CodePasswordIsUsername  Code = "password_is_username"
+	CodePasswordBreached    Code = "password_breached"
+	CodeWrongPassword       Code = "wrong_password"
+	CodeInvalidInvitation   Code = "invalid_invitation"
+)
+
+// messages holds the message of each code by locale. Messages are fmt formats
+// whose arguments are given with the error.
+var messages = map[string]map[Code]string{
+	model.LocaleEnglish: {
+		CodeInternal:                    "An unexpected error occurred.",
+		CodeInvalidBody:                 "Failed to read request body.",
+		CodeInvalidQuery:                "Failed to read request query string.",
+		CodeInvalidFile:                 "Failed to read uploaded file.",
+		CodeInvalidID:                   "The id must be an integer.",
+		CodeInvalidRequest:              "The request contains invalid fields.",
+		CodeBadRequest:                  "The request can't be processed.",
+		CodeMalformedToken:              "Missing or malformed token.",
+		CodeInvalidToken:                "Invalid or expired token.",
+		CodeInvalidRefreshToken:         "Invalid, expired or revoked refresh token.",
+		CodeInvalidCredentials:          "Invalid credentials.",
+		CodePasswordSame:                "Password isn't new.",
+		CodeDeleteOwnAccount:            "You can't delete your own account.",
+		CodeDisableOwnAccount:           "You can't disable your own account.",
+		CodeForbidden:                   "You don't have the permission to do this.",
+		CodeUserDisabled:                "This account is disabled.",
+		CodeTooManyLoginAttempts:        "Too many failed logins, retry in %d seconds.",
+		CodeMFASetupRequired:            "You must enable two-factor authentication before doing this.",
+		CodeMFAMandatory:                "Two-factor authentication is mandatory for admins.",
+		CodeInvalidMFAChallenge:         "The two-factor authentication challenge is invalid or expired, log in again.",
+		CodeInvalidOTP:                  "The code is invalid or expired.",
+		CodeMFAAlreadyEnabled:           "Two-factor authentication is already enabled.",
+		CodeMFANotEnrolled:              "Two-factor authentication is not being set up.",
+		CodePasswordChangeRequired:      "You must change your password before doing this.",
+		CodeLastAdmin:                   "The last admin can't lose the admin role, be disabled or deleted.",
+		CodeNotFound:                    "Not found.",
+		CodeRouteNotFound:               "No route matches the request.",
+		CodeUserNotFound:                "User not found.",
+		CodeIngredientNotFound:          "Ingredient not found.",
+		CodeRecipeNotFound:              "Recipe not found.",
+		CodeSubstitutionNotFound:        "Substitution not found.",
+		CodeTranslationNotFound:         "Translation not found.",
+		CodeTrashItemNotFound:           "Item not found in trash.",
+		CodeSessionNotFound:             "Session not found.",
+		CodeInvitationNotFound:          "Invitation not found.",
+		CodeDuplicateKey:                "An object with the same name already exists.",
+		CodeUsernameExists:              "Username '%s' already exists.",
+		CodeIngredientExists:            "An ingredient named '%s' already exists.",
+		CodeRecipeExists:                "A recipe named '%s' already exists.",
+		CodeSubstitutionExists:          "This substitution already exists.",
+		CodeIngredientTranslationExists: "An ingredient is already named '%s' in this locale.",
+		CodeSimilarRecipes:              "Similar recipes already exist.",
+
+		CodeRequired:            "This field is required.",
+		CodeTooShort:            "Must be at least %d characters long.",
+		CodeEmptyList:           "Must contain at least one item.",
+		CodeDuplicateItems:      "Must not contain duplicates.",
+		CodeTooLong:             "Must be at most %d characters long.",
+		CodeTooFewItems:         "Must contain at least %d items.",
+		CodeTooManyItems:        "Must contain at most %d items.",
+		CodeTooSmall:            "Must be at least %v.",
+		CodeTooLarge:            "Must be at most %v.",
+		CodeNotOneOf:            "Must be one of %s.",
+		CodeInvalidLimit:        "Must be between 1 and %d.",
+		CodeInvalidFormat:       "'%s' is not a valid format, use %s.",
+		CodeInvalidItemType:     "'%s' is not a valid item type.",
+		CodeInvalidRowType:      "'%s' is not a valid row type.",
+		CodeInvalidDuration:     "'%s' is not a valid ISO 8601 duration.",
+		CodeInvalidMinutes:      "'%s' is not a valid number of minutes.",
+		CodeInvalidDocument:     "The document is invalid: %s.",
+		CodeInvalidJSONLD:       "The document contains invalid JSON-LD.",
+		CodeInvalidJSONLine:     "The line is not a valid JSON object.",
+		CodeInvalidHeader:       "The first line must be the header %s.",
+		CodeFieldCount:          "Expected %d fields but got %d.",
+		CodeNoRecipeInDocument:  "The document contains no schema.org Recipe.",
+		CodeNoFavorites:         "You have no favorite recipe.",
+		CodeUnknownIngredient:   "'%s' is not a valid ingredient.",
+		CodeUnknownRecipe:       "Recipe %d not found.",
+		CodeSelfSubstitute:      "An ingredient can't substitute itself.",
+		CodeDefaultLocale:       "'%s' is the locale of the recipes and ingredients themselves.",
+		CodeUnsupportedLocale:   "'%s' is not a supported locale (%s).",
+		CodePasswordTooLong:     "Must be at most %d bytes long.",
+		CodePasswordCharClasses: "Must contain at least %d of lowercase letters, uppercase letters, digits and symbols.",
+		CodePasswordIsUsername:  "Must differ from the username.",
+		CodePasswordBreached:    "This password is too common, it appears in lists of breached passwords.",
+		CodeWrongPassword:       "The password is incorrect.",
+		CodeInvalidInvitation:   "The invitation code is invalid, expired or used up.",
+	},
+	model.LocaleWelsh: {
+		CodeInternal:                    "Digwyddodd gwall annisgwyl.",
+		CodeInvalidBody:                 "Methwyd â darllen corff y cais.",
+		CodeInvalidQuery:                "Methwyd â darllen llinyn ymholiad y cais.",
+		CodeInvalidFile:                 "Methwyd â darllen y ffeil a lanlwythwyd.",
+		CodeInvalidID:                   "Rhaid i'r id fod yn gyfanrif.",
+		CodeInvalidRequest:              "Mae'r cais yn cynnwys meysydd annilys.",
+		CodeBadRequest:                  "Ni ellir prosesu'r cais.",
+		CodeMalformedToken:              "Tocyn ar goll neu wedi'i ffurfio'n wael.",
+		CodeInvalidToken:                "Tocyn annilys neu wedi dod i ben.",
+		CodeInvalidRefreshToken:         "Tocyn adnewyddu annilys, wedi dod i ben neu wedi'i ddirymu.",
+		CodeInvalidCredentials:          "Manylion mewngofnodi annilys.",
+		CodePasswordSame:                "Nid yw'r cyfrinair yn newydd.",
+		CodeDeleteOwnAccount:            "Ni allwch ddileu eich cyfrif eich hun.",
+		CodeDisableOwnAccount:           "Ni allwch analluogi eich cyfrif eich hun.",
+		CodeForbidden:                   "Nid oes gennych ganiatâd i wneud hyn.",
+		CodeUserDisabled:                "Mae'r cyfrif hwn wedi'i analluogi.",
+		CodeTooManyLoginAttempts:        "Gormod o fewngofnodion wedi methu, rhowch gynnig arall ymhen %d eiliad.",
+		CodeMFASetupRequired:            "Rhaid i chi alluogi dilysu dau ffactor cyn gwneud hyn.",
+		CodeMFAMandatory:                "Mae dilysu dau ffactor yn orfodol i weinyddwyr.",
+		CodeInvalidMFAChallenge:         "Mae'r her dilysu dau ffactor yn annilys neu wedi dod i ben, mewngofnodwch eto.",
+		CodeInvalidOTP:                  "Mae'r cod yn annilys neu wedi dod i ben.",
+		CodeMFAAlreadyEnabled:           "Mae dilysu dau ffactor wedi'i alluogi eisoes.",
+		CodeMFANotEnrolled:              "Nid yw dilysu dau ffactor yn cael ei osod.",
+		CodePasswordChangeRequired:      "Rhaid i chi newid eich cyfrinair cyn gwneud hyn.",
+		CodeLastAdmin:                   "Ni all y gweinyddwr olaf golli rôl y gweinyddwr, cael ei analluogi na'i ddileu.",
+		CodeNotFound:                    "Heb ei ganfod.",
+		CodeRouteNotFound:               "Nid oes llwybr yn cyfateb i'r cais.",
+		CodeUserNotFound:                "Defnyddiwr heb ei ganfod.",
+		CodeIngredientNotFound:          "Cynhwysyn heb ei ganfod.",
+		CodeRecipeNotFound:              "Rysáit heb ei chanfod.",
+		CodeSubstitutionNotFound:        "Amnewidiad heb ei ganfod.",
+		CodeTranslationNotFound:         "Cyfieithiad heb ei ganfod.",
+		CodeTrashItemNotFound:           "Eitem heb ei chanfod yn y sbwriel.",
+		CodeSessionNotFound:             "Sesiwn heb ei chanfod.",
+		CodeInvitationNotFound:          "Gwahoddiad heb ei ganfod.",
+		CodeDuplicateKey:                "Mae gwrthrych gyda'r un enw yn bodoli eisoes.",
+		CodeUsernameExists:              "Mae'r enw defnyddiwr '%s' yn bodoli eisoes.",
+		CodeIngredientExists:            "Mae cynhwysyn o'r enw '%s' yn bodoli eisoes.",
+		CodeRecipeExists:                "Mae rysáit o'r enw '%s' yn bodoli eisoes.",
+		CodeSubstitutionExists:          "Mae'r amnewidiad hwn yn bodoli eisoes.",
+		CodeIngredientTranslationExists: "Mae cynhwysyn o'r enw '%s' yn yr iaith hon eisoes.",
+		CodeSimilarRecipes:              "Mae ryseitiau tebyg yn bodoli eisoes.",
+
+		CodeRequired:            "Mae angen y maes hwn.",
+		CodeTooShort:            "Rhaid iddo fod o leiaf %d nod o hyd.",
+		CodeEmptyList:           "Rhaid iddo gynnwys o leiaf un eitem.",
+		CodeDuplicateItems:      "Ni ddylai gynnwys dyblygiadau.",
+		CodeTooLong:             "Rhaid iddo fod hyd at %d nod o hyd.",
+		CodeTooFewItems:         "Rhaid iddo gynnwys o leiaf %d eitem.",
+		CodeTooManyItems:        "Rhaid iddo gynnwys hyd at %d eitem.",
+		CodeTooSmall:            "Rhaid iddo fod o leiaf %v.",
+		CodeTooLarge:            "Rhaid iddo fod hyd at %v.",
+		CodeNotOneOf:            "Rhaid iddo fod yn un o %s.",
+		CodeInvalidLimit:        "Rhaid iddo fod rhwng 1 a %d.",
+		CodeInvalidFormat:       "Nid yw '%s' yn fformat dilys, defnyddiwch %s.",
+		CodeInvalidItemType:     "Nid yw '%s' yn fath dilys o eitem.",
+		CodeInvalidRowType:      "Nid yw '%s' yn fath dilys o res.",
+		CodeInvalidDuration:     "Nid yw '%s' yn hyd ISO 8601 dilys.",
+		CodeInvalidMinutes:      "Nid yw '%s' yn nifer dilys o funudau.",
+		CodeInvalidDocument:     "Mae'r ddogfen yn annilys: %s.",
+		CodeInvalidJSONLD:       "Mae'r ddogfen yn cynnwys JSON-LD annilys.",
+		CodeInvalidJSONLine:     "Nid yw'r llinell yn wrthrych JSON dilys.",
+		CodeInvalidHeader:       "Rhaid i'r llinell gyntaf fod y pennawd %s.",
+		CodeFieldCount:          "Disgwyliwyd %d maes ond cafwyd %d.",
+		CodeNoRecipeInDocument:  "Nid yw'r ddogfen yn cynnwys Rysáit schema.org.",
+		CodeNoFavorites:         "Nid oes gennych unrhyw hoff rysáit.",
+		CodeUnknownIngredient:   "Nid yw '%s' yn gynhwysyn dilys.",
+		CodeUnknownRecipe:       "Rysáit %d heb ei chanfod.",
+		CodeSelfSubstitute:      "Ni all cynhwysyn gymryd lle ei hun.",
+		CodeDefaultLocale:       "'%s' yw iaith y ryseitiau a'r cynhwysion eu hunain.",
+		CodeUnsupportedLocale:   "Nid yw '%s' yn iaith a gefnogir (%s).",
+		CodePasswordTooLong:     "Rhaid iddo fod hyd at %d beit o hyd.",
+		CodePasswordCharClasses: "Rhaid iddo gynnwys o leiaf %d o lythrennau bach, priflythrennau, digidau a symbolau.",
+		CodePasswordIsUsername:  "Rhaid iddo fod yn wahanol i'r enw defnyddiwr.",
+		CodePasswordBreached:    "Mae'r cyfrinair hwn yn rhy gyffredin, mae'n ymddangos mewn rhestrau o gyfrineiriau a ddatgelwyd.",
+		CodeWrongPassword:       "Mae'r cyfrinair yn anghywir.",
+		CodeInvalidInvitation:   "Mae'r cod gwahoddiad yn annilys, wedi dod i ben neu wedi'i ddefnyddio'n llawn.",
+	},
+}
+
+// Message returns the message of a code in a locale, formatted with args.
+// It falls back to the default locale when the message isn't translated.
+func Message(locale string, code Code, args ...any) string {
+	format, ok := messages[locale][code]
+	if !ok {
+		format, ok = messages[model.DefaultLocale][code]
+	}
+	if !ok {
+		return string(code)
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
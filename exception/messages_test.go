@@ -0,0 +1,36 @@
+package exception
+
+import (
+	"testing"
+
+	"github.com/denisyao1/welsh-academy-api/model"
+)
+
+func TestMessagesAreTranslated(t *testing.T) {
+	for _, locale := range model.Locales {
+		for code := range messages[model.DefaultLocale] {
+			if _, ok := messages[locale][code]; !ok {
+				t.Errorf("code %s has no message in locale %s", code, locale)
+			}
+		}
+	}
+}
+
+func TestMessage(t *testing.T) {
+	tests := []struct {
+		locale string
+		code   Code
+		args   []any
+		want   string
+	}{
+		{model.LocaleEnglish, CodeTooShort, []any{4}, "Must be at least 4 characters long."},
+		{model.LocaleWelsh, CodeTooShort, []any{4}, "Rhaid iddo fod o leiaf 4 nod o hyd."},
+		{"fr", CodeRecipeNotFound, nil, "Recipe not found."},
+		{model.LocaleWelsh, Code("unknown_code"), nil, "unknown_code"},
+	}
+	for _, tt := range tests {
+		if got := Message(tt.locale, tt.code, tt.args...); got != tt.want {
+			t.Errorf("Message(%s, %s) = %q, want %q", tt.locale, tt.code, got, tt.want)
+		}
+	}
+}
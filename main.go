@@ -1,6 +1,9 @@
 package main
 
 import (
+	"log"
+	"time"
+
 	"github.com/denisyao1/welsh-academy-api/common"
 	"github.com/denisyao1/welsh-academy-api/controller"
 	"github.com/denisyao1/welsh-academy-api/database"
@@ -29,6 +32,12 @@ import (
 // @securityDefinitions.apiKey JWT
 // @in cookie
 // @name Auth
+// @description Access token set by POST /login.
+
+// @securityDefinitions.apiKey Bearer
+// @in header
+// @name Authorization
+// @description Access token given by POST /login?mode=token, as "Bearer <token>".
 func main() {
 
 	config := common.LoadConfig()
@@ -39,25 +48,74 @@ func main() {
 	gormDB.MigrateAll()
 
 	ingredientRepo := repository.NewGormIngredientRepository(gormDB.GetDB())
+	recipeRepo := repository.NewGormRecipeRepository(gormDB.GetDB())
+
+	translationRepo := repository.NewGormTranslationRepository(gormDB.GetDB())
+	translationService := service.NewTranslationService(translationRepo, ingredientRepo, recipeRepo)
+	translationController := controller.NewTranslationController(translationService)
+
 	ingredientService := service.NewIngredientService(ingredientRepo)
-	ingredienController := controller.NewIngredientController(ingredientService)
+	ingredienController := controller.NewIngredientController(ingredientService, translationService)
 
-	recipeRepo := repository.NewGormRecipeRepository(gormDB.GetDB())
 	recipeService := service.NewRecipeService(recipeRepo, ingredientRepo)
-	recipeController := controller.NewRecipeController(recipeService)
+	substitutionRepo := repository.NewGormSubstitutionRepository(gormDB.GetDB())
+	substitutionService := service.NewSubstitutionService(substitutionRepo, ingredientRepo)
+	substitutionController := controller.NewSubstitutionController(substitutionService, translationService)
 
-	userRepo := repository.NewUserRepository(gormDB.GetDB())
+	recipeController := controller.NewRecipeController(recipeService, substitutionService, translationService)
 
-	userService := service.NewUserService(userRepo, config.JWT_SECRET)
+	userRepo := repository.NewUserRepository(gormDB.GetDB())
 
-	// create default admin user
-	userService.CreateDefaultAdmin()
+	sessionRepo := repository.NewGormSessionRepository(gormDB.GetDB())
+	tokenRepo := repository.NewGormRefreshTokenRepository(gormDB.GetDB())
+	revocationService := service.NewRevocationService(repository.NewGormRevocationRepository(gormDB.GetDB()))
+	keyService, err := service.LoadKeyService(config.JWT_SIGNING_KEY_FILE, config.JWT_VERIFICATION_KEY_FILES, config.JWT_SECRET)
+	if err != nil {
+		log.Fatalf("Failed to load JWT keys: %v", err)
+	}
+	keyController := controller.NewKeyController(keyService)
+	passwordPolicy, err := service.NewPasswordPolicy(config.PASSWORD_MIN_LENGTH, config.PASSWORD_MIN_CHAR_CLASSES, config.BCRYPT_COST)
+	if err != nil {
+		log.Fatalf("Failed to load password policy: %v", err)
+	}
+	loginThrottle := service.NewLoginThrottle(repository.NewGormLoginFailureRepository(gormDB.GetDB()),
+		config.LOGIN_MAX_FAILURES, config.LOGIN_MAX_FAILURES_PER_IP,
+		time.Duration(config.LOGIN_BACKOFF_SECONDS)*time.Second, time.Duration(config.LOGIN_LOCKOUT_MINUTES)*time.Minute)
+	mfaRepo := repository.NewGormMFARepository(gormDB.GetDB())
+	userStatusService := service.NewUserStatusService(userRepo)
+	userService := service.NewUserService(userRepo, sessionRepo, tokenRepo, revocationService, userStatusService, keyService, passwordPolicy, loginThrottle,
+		mfaRepo, config.MFA_REQUIRED_FOR_ADMINS, time.Duration(config.ACCESS_TOKEN_MINUTES)*time.Minute, time.Duration(config.REFRESH_TOKEN_DAYS)*24*time.Hour)
+
+	// create the initial admin user
+	if err = userService.Bootstrap(config.ADMIN_USERNAME, config.ADMIN_PASSWORD); err != nil {
+		log.Fatalf("Failed to create the initial admin: %v", err)
+	}
 
 	userController := controller.NewUserController(userService)
 
-	router := router.New(ingredienController, recipeController, userController, config.JWT_SECRET)
+	invitationService := service.NewInvitationService(repository.NewGormInvitationRepository(gormDB.GetDB()), userService)
+	invitationController := controller.NewInvitationController(invitationService)
+
+	retention := time.Duration(config.TRASH_RETENTION_DAYS) * 24 * time.Hour
+	trashService := service.NewTrashService(ingredientRepo, recipeRepo, userRepo, retention)
+	trashController := controller.NewTrashController(trashService)
+
+	catalogueRepo := repository.NewGormCatalogueRepository(gormDB.GetDB())
+	catalogueService := service.NewCatalogueService(catalogueRepo, ingredientRepo, recipeRepo)
+	catalogueController := controller.NewCatalogueController(catalogueService)
+
+	cookbookService := service.NewCookbookService(recipeRepo, translationService)
+	cookbookController := controller.NewCookbookController(cookbookService)
+
+	recommendationService := service.NewRecommendationService(recipeRepo)
+	recommendationController := controller.NewRecommendationController(recommendationService, translationService)
+	go recommendationService.RefreshEvery(time.Duration(config.RECOMMENDATION_REFRESH_MINUTES) * time.Minute)
+
+	router := router.New(ingredienController, recipeController, userController, trashController,
+		catalogueController, cookbookController, recommendationController, substitutionController,
+		translationController, keyController, invitationController, keyService, revocationService, userStatusService)
 
-	app := fiber.New()
+	app := fiber.New(fiber.Config{ErrorHandler: controller.ErrorHandler})
 
 	app.Use(logger.New())
 
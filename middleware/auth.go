@@ -1,54 +1,193 @@
 package middleware
 
 import (
-	"fmt"
-	"time"
+	"errors"
+	"strconv"
+	"strings"
 
+	"github.com/denisyao1/welsh-academy-api/exception"
 	"github.com/denisyao1/welsh-academy-api/model"
+	"github.com/denisyao1/welsh-academy-api/util"
 	"github.com/gofiber/fiber/v2"
-	"github.com/golang-jwt/jwt"
+	"github.com/golang-jwt/jwt/v5"
 )
 
+// TokenVerifier checks the signature and expiry of access tokens.
+type TokenVerifier interface {
+	// Verify returns the claims of a valid token.
+	Verify(token string) (jwt.MapClaims, error)
+}
+
+// TokenRevocations tells if access tokens were revoked before they expire.
+type TokenRevocations interface {
+	// IsRevoked returns true if one of the token or session IDs is revoked.
+	IsRevoked(ids ...string) (bool, error)
+}
+
+// UserStatuses tells if the users of access tokens can still use them.
+type UserStatuses interface {
+	// IsActive returns true if the user with an ID is neither disabled nor deleted.
+	IsActive(userID int) (bool, error)
+}
+
+// Restriction limits the tokens of a user to setting up its account.
+type Restriction string
+
+const (
+	RestrictPasswordChange Restriction = "pwd_change" // the password must be changed
+	RestrictMFASetup       Restriction = "mfa_setup"  // two-factor authentication must be enabled
+)
+
+// errors of the requests made with restricted tokens
+var restrictionCodes = map[Restriction]exception.Code{
+	RestrictPasswordChange: exception.CodePasswordChangeRequired,
+	RestrictMFASetup:       exception.CodeMFASetupRequired,
+}
+
 // JwtWare decodes auth token and allows user to access ressources
-// according to its role.
-func JwtWare(siginKey string, role model.Role) func(ctx *fiber.Ctx) error {
+// if the roles of the token have all the permissions.
+//
+// The token is read from the Authorization header with the Bearer scheme,
+// or else from the Auth cookie. Revoked tokens and the tokens of disabled or
+// deleted users are rejected, as well as restricted tokens of users who must
+// set up their account.
+func JwtWare(verifier TokenVerifier, revocations TokenRevocations, users UserStatuses,
+	permissions ...model.Permission) func(ctx *fiber.Ctx) error {
 	return func(ctx *fiber.Ctx) error {
-		tokenString := ctx.Cookies("Auth")
-		if tokenString == "" {
-			return ctx.Status(fiber.StatusUnauthorized).
-				JSON(fiber.Map{"message": "Missing or malformed token"})
+		claims, err := authenticate(ctx, verifier, revocations, users)
+		if err != nil {
+			return err
 		}
 
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			return []byte(siginKey), nil
-		})
+		if err = checkRestrictions(claims); err != nil {
+			return err
+		}
 
-		if err != nil {
-			return ctx.Status(fiber.StatusUnauthorized).
-				JSON(fiber.Map{"message": "Missing or malformed token"})
+		if !model.HasPermissions(rolesOf(claims), permissions...) {
+			return exception.New(exception.CodeForbidden)
 		}
 
-		// reference ctx json missing token response
-		invalidTokenResponse := ctx.Status(fiber.StatusUnauthorized).
-			JSON(fiber.Map{"message": "invalid or expired token"})
+		setLocals(ctx, claims)
+		return ctx.Next()
+	}
+}
 
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !(ok && token.Valid) {
-			return invalidTokenResponse
+// SetupJwtWare decodes auth token like JwtWare, also allowing tokens with
+// the restrictions, so that users can set up their account.
+func SetupJwtWare(verifier TokenVerifier, revocations TokenRevocations, users UserStatuses,
+	allowed ...Restriction) func(ctx *fiber.Ctx) error {
+	return func(ctx *fiber.Ctx) error {
+		claims, err := authenticate(ctx, verifier, revocations, users)
+		if err != nil {
+			return err
 		}
 
-		if float64(time.Now().Unix()) > claims["exp"].(float64) {
-			return invalidTokenResponse
+		if err = checkRestrictions(claims, allowed...); err != nil {
+			return err
 		}
 
-		user_role := model.Role(claims["role"].(float64))
-		if role == model.RoleAdmin && role != user_role {
-			return invalidTokenResponse
+		setLocals(ctx, claims)
+		return ctx.Next()
+	}
+}
+
+// OptionalJwtWare decodes auth token like JwtWare when there is a valid one,
+// and lets the request through in any case.
+func OptionalJwtWare(verifier TokenVerifier, revocations TokenRevocations, users UserStatuses) func(ctx *fiber.Ctx) error {
+	return func(ctx *fiber.Ctx) error {
+		if claims, err := authenticate(ctx, verifier, revocations, users); err == nil {
+			setLocals(ctx, claims)
 		}
-		ctx.Locals("userID", claims["ID"])
 		return ctx.Next()
 	}
 }
+
+// authenticate returns the claims of the auth token of a request if it's valid.
+func authenticate(ctx *fiber.Ctx, verifier TokenVerifier, revocations TokenRevocations,
+	users UserStatuses) (jwt.MapClaims, error) {
+	tokenString := tokenOf(ctx)
+	if tokenString == "" {
+		return nil, exception.ErrMalFormedJWT
+	}
+
+	invalidTokenErr := exception.New(exception.CodeInvalidToken)
+
+	claims, err := verifier.Verify(tokenString)
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenMalformed) {
+			return nil, exception.ErrMalFormedJWT
+		}
+		return nil, invalidTokenErr
+	}
+
+	tokenID, _ := claims["jti"].(string)
+	sessionID, _ := claims["sid"].(string)
+	revoked, err := revocations.IsRevoked(tokenID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, invalidTokenErr
+	}
+
+	// the sessions of other instances are revoked with a delay
+	userID, _ := claims["ID"].(string)
+	id, err := strconv.Atoi(userID)
+	if err != nil {
+		return nil, invalidTokenErr
+	}
+	active, err := users.IsActive(id)
+	if err != nil {
+		return nil, err
+	}
+	if !active {
+		return nil, invalidTokenErr
+	}
+
+	return claims, nil
+}
+
+// rolesOf returns the roles of the claims.
+func rolesOf(claims jwt.MapClaims) []model.Role {
+	values, _ := claims["roles"].([]interface{})
+	roles := make([]model.Role, 0, len(values))
+	for _, value := range values {
+		if role, ok := value.(string); ok {
+			roles = append(roles, model.Role(role))
+		}
+	}
+	return roles
+}
+
+// checkRestrictions returns the error of the first restriction of the claims
+// which isn't allowed, if any.
+func checkRestrictions(claims jwt.MapClaims, allowed ...Restriction) error {
+	for _, restriction := range []Restriction{RestrictPasswordChange, RestrictMFASetup} {
+		restricted, _ := claims[string(restriction)].(bool)
+		if restricted && !util.Contains(restriction, allowed) {
+			return exception.New(restrictionCodes[restriction])
+		}
+	}
+	return nil
+}
+
+func setLocals(ctx *fiber.Ctx, claims jwt.MapClaims) {
+	ctx.Locals("userID", claims["ID"])
+	if sessionID, ok := claims["sid"].(string); ok {
+		ctx.Locals("sessionID", sessionID)
+	}
+}
+
+// tokenOf returns the access token of a request, or an empty string.
+func tokenOf(ctx *fiber.Ctx) string {
+	authorization := ctx.Get(fiber.HeaderAuthorization)
+	if authorization == "" {
+		return ctx.Cookies("Auth")
+	}
+
+	scheme, token, found := strings.Cut(authorization, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
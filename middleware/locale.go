@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"github.com/denisyao1/welsh-academy-api/model"
+	"github.com/denisyao1/welsh-academy-api/util"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Locale negotiates the language of the response from the lang query param
+// or else the Accept-Language header, falling back to the default locale.
+// The locale is stored in the "locale" local.
+func Locale() func(ctx *fiber.Ctx) error {
+	return func(ctx *fiber.Ctx) error {
+		locale := ctx.Query("lang")
+		if !util.Contains(locale, model.Locales) {
+			locale = ctx.AcceptsLanguages(model.Locales...)
+		}
+		if locale == "" {
+			locale = model.DefaultLocale
+		}
+
+		ctx.Locals("locale", locale)
+		return ctx.Next()
+	}
+}
+
+// GetLocale returns the locale negotiated by the Locale middleware,
+// the default locale if it didn't run.
+func GetLocale(ctx *fiber.Ctx) string {
+	if locale, ok := ctx.Locals("locale").(string); ok {
+		return locale
+	}
+	return model.DefaultLocale
+}
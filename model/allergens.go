@@ -0,0 +1,78 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Allergens is a set of the 14 allergens food labels have to declare.
+type Allergens uint16
+
+const (
+	AllergenCelery Allergens = 1 << iota
+	AllergenGluten
+	AllergenCrustaceans
+	AllergenEggs
+	AllergenFish
+	AllergenLupin
+	AllergenMilk
+	AllergenMolluscs
+	AllergenMustard
+	AllergenNuts
+	AllergenPeanuts
+	AllergenSesame
+	AllergenSoya
+	AllergenSulphites
+)
+
+var allergenNames = []string{
+	"celery", "gluten", "crustaceans", "eggs", "fish", "lupin", "milk",
+	"molluscs", "mustard", "nuts", "peanuts", "sesame", "soya", "sulphites",
+}
+
+// Names returns the names of the allergens in the set.
+func (a Allergens) Names() []string {
+	names := []string{}
+	for i, name := range allergenNames {
+		if a&(1<<i) != 0 {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// ParseAllergens returns the set of the named allergens.
+func ParseAllergens(names []string) (Allergens, error) {
+	var allergens Allergens
+	for _, name := range names {
+		found := false
+		for i, allergen := range allergenNames {
+			if name == allergen {
+				allergens |= 1 << i
+				found = true
+				break
+			}
+		}
+		if !found {
+			return 0, fmt.Errorf("'%s' is not a valid allergen", name)
+		}
+	}
+	return allergens, nil
+}
+
+func (a Allergens) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.Names())
+}
+
+func (a *Allergens) UnmarshalJSON(data []byte) error {
+	var names []string
+	if err := json.Unmarshal(data, &names); err != nil {
+		return err
+	}
+	allergens, err := ParseAllergens(names)
+	if err != nil {
+		return err
+	}
+	*a = allergens
+	return nil
+}
@@ -1,8 +1,69 @@
 package model
 
-type Role int
+import "encoding/json"
+
+// Permission allows an action on a kind of resources.
+type Permission string
+
+const (
+	PermRecipeCreate     Permission = "recipe:create"     // create and import recipes
+	PermRecipePublish    Permission = "recipe:publish"    // translate, merge and delete recipes
+	PermIngredientManage Permission = "ingredient:manage" // create, translate, substitute and delete ingredients
+	PermUserManage       Permission = "user:manage"       // create and delete users, assign their roles
+	PermReviewModerate   Permission = "review:moderate"   // moderate the reviews of recipes
+)
+
+// Role is a set of permissions assigned to users.
+type Role string
 
 const (
-	RoleAdmin Role = iota + 1
-	RoleUser
+	RoleAdmin       Role = "admin"
+	RoleEditor      Role = "editor"
+	RoleContributor Role = "contributor"
+	RoleReader      Role = "reader"
 )
+
+// Roles lists the roles from the most to the least privileged.
+var Roles = []Role{RoleAdmin, RoleEditor, RoleContributor, RoleReader}
+
+// RolePermissions holds the permissions of each role. Readers can only
+// browse recipes and manage their favorites, which any user can do.
+var RolePermissions = map[Role][]Permission{
+	RoleAdmin:       {PermRecipeCreate, PermRecipePublish, PermIngredientManage, PermUserManage, PermReviewModerate},
+	RoleEditor:      {PermRecipeCreate, PermRecipePublish, PermIngredientManage, PermReviewModerate},
+	RoleContributor: {PermRecipeCreate},
+	RoleReader:      {},
+}
+
+// HasPermissions returns true if the roles have all the permissions together.
+func HasPermissions(roles []Role, permissions ...Permission) bool {
+	granted := make(map[Permission]bool)
+	for _, role := range roles {
+		for _, permission := range RolePermissions[role] {
+			granted[permission] = true
+		}
+	}
+
+	for _, permission := range permissions {
+		if !granted[permission] {
+			return false
+		}
+	}
+	return true
+}
+
+// UserRole assigns a role to a user.
+type UserRole struct {
+	UserID int  `gorm:"primarykey"`
+	Role   Role `gorm:"primarykey;size:32"`
+}
+
+// MarshalJSON writes the role name only.
+func (r UserRole) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.Role)
+}
+
+// UnmarshalJSON reads the role name only.
+func (r *UserRole) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &r.Role)
+}
@@ -2,34 +2,127 @@ package model
 
 import (
 	"time"
+
+	"github.com/denisyao1/welsh-academy-api/util"
+	"gorm.io/gorm"
 )
 
 type BaseModel struct {
-	ID        int       `gorm:"primarykey" json:"id" example:"1" extensions:"x-order=1"`
-	CreatedAt time.Time `gorm:"autoCreateTime" json:"-"`
-	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"-"`
+	ID        int            `gorm:"primarykey" json:"id" example:"1" extensions:"x-order=1"`
+	CreatedAt time.Time      `gorm:"autoCreateTime" json:"-"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime" json:"-"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
 type Ingredient struct {
 	BaseModel
-	Name string `gorm:"uniqueIndex" json:"name" example:"Tomato"`
+	Name      string    `gorm:"uniqueIndex" json:"name" example:"Tomato"`
+	NameKey   string    `gorm:"not null;default:''" json:"-"` // normalized and case folded, unique
+	Allergens Allergens `gorm:"not null;default:0" json:"allergens" swaggertype:"array,string" enums:"celery,gluten,crustaceans,eggs,fish,lupin,milk,molluscs,mustard,nuts,peanuts,sesame,soya,sulphites"`
 }
 
 type Recipe struct {
 	BaseModel
 	Name        string       `gorm:"uniqueIndex" json:"name" extensions:"x-order=2"`
+	NameKey     string       `gorm:"not null;default:''" json:"-"` // normalized and case folded, unique
 	Making      string       `gorm:"type:text;not null" json:"making" extensions:"x-order=3"`
 	Ingredients []Ingredient `gorm:"many2many:recipe_ingredients" json:"ingredients"`
+	Tags        []Tag        `gorm:"many2many:recipe_tags" json:"tags,omitempty"`
+	Yield       string       `json:"yield,omitempty" example:"4 servings"`
+	PrepTime    int          `json:"prepTime,omitempty" example:"15"` // in minutes
+	CookTime    int          `json:"cookTime,omitempty" example:"10"` // in minutes
+}
+
+// Tag is a free-form label of recipes, like "soup" or "vegetarian".
+type Tag struct {
+	ID   int    `gorm:"primarykey" json:"-"`
+	Name string `gorm:"uniqueIndex;not null" json:"name" example:"soup"`
+}
+
+// BeforeCreate normalizes the ingredient name.
+func (i *Ingredient) BeforeCreate(tx *gorm.DB) error {
+	i.Name = util.NormalizeName(i.Name)
+	i.NameKey = util.NameKey(i.Name)
+	return nil
+}
+
+// BeforeCreate normalizes the recipe name.
+func (r *Recipe) BeforeCreate(tx *gorm.DB) error {
+	r.Name = util.NormalizeName(r.Name)
+	r.NameKey = util.NameKey(r.Name)
+	return nil
+}
+
+// Allergens returns the allergens of all the recipe ingredients.
+func (r Recipe) Allergens() Allergens {
+	var allergens Allergens
+	for _, ingredient := range r.Ingredients {
+		allergens |= ingredient.Allergens
+	}
+	return allergens
+}
+
+// Substitution tells which ingredients can replace an ingredient.
+type Substitution struct {
+	ID           int          `gorm:"primarykey" json:"id" example:"1" extensions:"x-order=1"`
+	IngredientID int          `gorm:"index;not null" json:"-"`
+	Ingredient   Ingredient   `json:"ingredient" extensions:"x-order=2"`
+	Substitutes  []Substitute `json:"substitutes" extensions:"x-order=3"`
+	Notes        string       `json:"notes,omitempty" example:"Gives a crumblier sauce." extensions:"x-order=4"`
+	CreatedAt    time.Time    `gorm:"autoCreateTime" json:"-"`
+}
+
+// Substitute is an ingredient used in a substitution.
+type Substitute struct {
+	ID             int        `gorm:"primarykey" json:"-"`
+	SubstitutionID int        `gorm:"index;not null" json:"-"`
+	IngredientID   int        `gorm:"not null" json:"-"`
+	Ingredient     Ingredient `json:"ingredient" extensions:"x-order=1"`
+	Ratio          float64    `gorm:"not null;default:1" json:"ratio" example:"1" extensions:"x-order=2"` // quantity per unit of the replaced ingredient
 }
 
 type User struct {
-	ID        int       `gorm:"primarykey" json:"-"`
-	Username  string    `gorm:"UniqueIndex;not null" json:"username" extensions:"x-order=1"`
-	Password  string    `gorm:"not null"  json:"-"`
-	IsAdmin   bool      `json:"admin"`
-	Recipes   []Recipe  `gorm:"many2many:user_favorites" json:"-"`
-	CreatedAt time.Time `gorm:"autoCreateTime" json:"-"`
-	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"-"`
+	ID                 int            `gorm:"primarykey" json:"id" example:"1" extensions:"x-order=0"`
+	Username           string         `gorm:"UniqueIndex;not null" json:"username" extensions:"x-order=1"`
+	UsernameKey        string         `gorm:"not null;default:''" json:"-"` // normalized and case folded, unique
+	Password           string         `gorm:"not null"  json:"-"`
+	Roles              []UserRole     `gorm:"constraint:OnDelete:CASCADE" json:"roles" swaggertype:"array,string" example:"reader" extensions:"x-order=2"`
+	DisabledAt         *time.Time     `json:"disabledAt,omitempty" extensions:"x-order=3"`                             // disabled users can't log in
+	MustChangePassword bool           `gorm:"not null;default:false" json:"mustChangePassword" extensions:"x-order=4"` // temporary passwords only let the user change them
+	PasswordChangedAt  *time.Time     `json:"passwordChangedAt,omitempty" extensions:"x-order=5"`                      // last change by the user
+	TOTPEnabled        bool           `gorm:"not null;default:false" json:"totpEnabled" extensions:"x-order=6"`        // logins need a code of the TOTP secret
+	TOTPSecret         string         `gorm:"not null;default:''" json:"-"`                                            // base32, enabled once verified
+	TOTPLastStep       int64          `gorm:"not null;default:0" json:"-"`                                             // time step of the last accepted code, not accepted again
+	Recipes            []Recipe       `gorm:"many2many:user_favorites" json:"-"`
+	CreatedAt          time.Time      `gorm:"autoCreateTime" json:"-"`
+	UpdatedAt          time.Time      `gorm:"autoUpdateTime" json:"-"`
+	DeletedAt          gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// BeforeCreate normalizes the username.
+func (u *User) BeforeCreate(tx *gorm.DB) error {
+	u.Username = util.NormalizeName(u.Username)
+	u.UsernameKey = util.NameKey(u.Username)
+	return nil
+}
+
+// RoleNames returns the roles assigned to the user.
+func (u User) RoleNames() []Role {
+	roles := make([]Role, 0, len(u.Roles))
+	for _, userRole := range u.Roles {
+		roles = append(roles, userRole.Role)
+	}
+	return roles
+}
+
+// HasRole returns true if the role is assigned to the user.
+func (u User) HasRole(role Role) bool {
+	for _, userRole := range u.Roles {
+		if userRole.Role == role {
+			return true
+		}
+	}
+	return false
 }
 
 type UserFavorite struct {
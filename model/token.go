@@ -0,0 +1,85 @@
+package model
+
+import "time"
+
+// Session is a login of a user, kept alive by refreshing its tokens.
+type Session struct {
+	ID         string     `gorm:"primarykey;size:32"`
+	UserID     int        `gorm:"index;not null"`
+	UserAgent  string     `gorm:"not null;default:''"`
+	IP         string     `gorm:"size:64;not null;default:''"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime"`
+	LastUsedAt time.Time  `gorm:"not null"`
+	ExpiresAt  time.Time  `gorm:"not null"` // when its last refresh token expires
+	RevokedAt  *time.Time `gorm:"index"`
+}
+
+// RefreshToken lets a user get new access tokens without logging in again.
+//
+// Each use replaces it with a new token of the same session. Using a replaced
+// token again revokes the whole session, as one of them may have been stolen.
+type RefreshToken struct {
+	ID        int        `gorm:"primarykey"`
+	UserID    int        `gorm:"index;not null"`
+	Family    string     `gorm:"index;size:32;not null"`       // ID of the session
+	Hash      string     `gorm:"uniqueIndex;size:64;not null"` // SHA-256 of the token, which isn't stored
+	ExpiresAt time.Time  `gorm:"not null"`
+	UsedAt    *time.Time // set when the token is replaced
+	RevokedAt *time.Time
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// Revocation rejects the access tokens of a session, or a single access token,
+// until they expire.
+type Revocation struct {
+	ID        string    `gorm:"primarykey;size:32"` // session or token ID
+	ExpiresAt time.Time `gorm:"index;not null"`
+}
+
+// LoginFailure counts the recent failed logins of a username or an IP address.
+type LoginFailure struct {
+	ID           string    `gorm:"primarykey"` // user:<username key> or ip:<address>
+	Count        int       `gorm:"not null"`
+	LastFailedAt time.Time `gorm:"index;not null"`
+	LockedUntil  time.Time `gorm:"not null"` // no login is tried before
+}
+
+// RecoveryCode lets a user log in once without its TOTP code.
+type RecoveryCode struct {
+	ID     int    `gorm:"primarykey"`
+	UserID int    `gorm:"index;not null"`
+	Hash   string `gorm:"size:64;not null"` // SHA-256 of the code, which isn't stored
+	UsedAt *time.Time
+}
+
+// MFAChallenge is a login whose password was checked, waiting for the TOTP code.
+type MFAChallenge struct {
+	ID        string    `gorm:"primarykey;size:64"` // SHA-256 of the challenge token, which isn't stored
+	UserID    int       `gorm:"index;not null"`
+	ExpiresAt time.Time `gorm:"index;not null"`
+}
+
+// Invitation lets people register with its code, getting its role, until it
+// expires or all its uses are taken.
+type Invitation struct {
+	ID        int             `gorm:"primarykey" json:"id" example:"1" extensions:"x-order=1"`
+	Hash      string          `gorm:"uniqueIndex;size:64;not null" json:"-"`          // SHA-256 of the code, which isn't stored
+	Code      string          `gorm:"-" json:"code,omitempty" extensions:"x-order=2"` // only returned on creation
+	Role      Role            `gorm:"size:32;not null" json:"role" example:"reader" extensions:"x-order=3"`
+	MaxUses   int             `gorm:"not null" json:"maxUses" example:"1" extensions:"x-order=4"`
+	UseCount  int             `gorm:"not null;default:0" json:"useCount" example:"0" extensions:"x-order=5"`
+	ExpiresAt time.Time       `gorm:"not null" json:"expiresAt" extensions:"x-order=6"`
+	CreatedBy int             `gorm:"not null" json:"createdBy" example:"1" extensions:"x-order=7"` // ID of the admin
+	CreatedAt time.Time       `gorm:"autoCreateTime" json:"createdAt" extensions:"x-order=8"`
+	RevokedAt *time.Time      `json:"revokedAt,omitempty" extensions:"x-order=9"`
+	Uses      []InvitationUse `gorm:"constraint:OnDelete:CASCADE" json:"uses" extensions:"x-order=10"`
+}
+
+// InvitationUse is the registration of a user with an invitation.
+type InvitationUse struct {
+	ID           int       `gorm:"primarykey" json:"-"`
+	InvitationID int       `gorm:"index;not null" json:"-"`
+	UserID       int       `gorm:"not null" json:"userId" example:"2" extensions:"x-order=1"`
+	Username     string    `gorm:"not null" json:"username" extensions:"x-order=2"` // kept when the user is purged
+	UsedAt       time.Time `gorm:"not null" json:"usedAt" extensions:"x-order=3"`
+}
@@ -0,0 +1,31 @@
+package model
+
+// Locales of the content. Recipes and ingredients are written in the default
+// locale; the other locales are translations.
+const (
+	LocaleEnglish = "en"
+	LocaleWelsh   = "cy"
+
+	DefaultLocale = LocaleEnglish
+)
+
+// Locales lists the supported locales, the default one first.
+var Locales = []string{LocaleEnglish, LocaleWelsh}
+
+// IngredientTranslation is the name of an ingredient in a locale.
+type IngredientTranslation struct {
+	ID           int    `gorm:"primarykey" json:"-"`
+	IngredientID int    `gorm:"uniqueIndex:idx_ingredient_translation;not null" json:"-"`
+	Locale       string `gorm:"uniqueIndex:idx_ingredient_translation;uniqueIndex:idx_ingredient_translation_name;size:8;not null" json:"locale" example:"cy" extensions:"x-order=1"`
+	Name         string `gorm:"not null" json:"name" example:"Caws" extensions:"x-order=2"`
+	NameKey      string `gorm:"uniqueIndex:idx_ingredient_translation_name;not null" json:"-"`
+}
+
+// RecipeTranslation is the name and making of a recipe in a locale.
+type RecipeTranslation struct {
+	ID       int    `gorm:"primarykey" json:"-"`
+	RecipeID int    `gorm:"uniqueIndex:idx_recipe_translation;not null" json:"-"`
+	Locale   string `gorm:"uniqueIndex:idx_recipe_translation;size:8;not null" json:"locale" example:"cy" extensions:"x-order=1"`
+	Name     string `gorm:"not null" json:"name" example:"Caws Pobi" extensions:"x-order=2"`
+	Making   string `gorm:"type:text;not null" json:"making" extensions:"x-order=3"`
+}
@@ -0,0 +1,24 @@
+package repository
+
+import "gorm.io/gorm"
+
+// CatalogueRepository gives access to the ingredients and recipes in a single transaction.
+type CatalogueRepository interface {
+	// Transaction runs fn with repositories bound to a DB transaction.
+	// The transaction is rolled back if fn returns an error, else it's committed.
+	Transaction(fn func(ingredientRepo IngredientRepository, recipeRepo RecipeRepository) error) error
+}
+
+type gormCatalogueRepo struct {
+	db *gorm.DB
+}
+
+func NewGormCatalogueRepository(db *gorm.DB) CatalogueRepository {
+	return &gormCatalogueRepo{db: db}
+}
+
+func (r gormCatalogueRepo) Transaction(fn func(IngredientRepository, RecipeRepository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(NewGormIngredientRepository(tx), NewGormRecipeRepository(tx))
+	})
+}
@@ -2,8 +2,11 @@ package repository
 
 import (
 	"errors"
+	"time"
 
+	"github.com/denisyao1/welsh-academy-api/exception"
 	"github.com/denisyao1/welsh-academy-api/model"
+	"github.com/denisyao1/welsh-academy-api/util"
 	"gorm.io/gorm"
 )
 
@@ -14,15 +17,36 @@ type IngredientRepository interface {
 	// FindAll returns all ingredients from DB.
 	FindAll() ([]model.Ingredient, error)
 
+	// FindInBatches calls fn with all ingredients from DB, batchSize at a time.
+	FindInBatches(batchSize int, fn func(ingredients []model.Ingredient) error) error
+
 	// IsNotCreated returns true if the ingredient is not present in DB, else false.
+	// Names are compared ignoring case and spaces.
+	// Ingredients in the trash are taken into account as they still hold their name.
 	IsNotCreated(ingredient model.Ingredient) (bool, error)
 
-	// FindNamed returns all ingredients those names equal the names parameters.
+	// FindNamed returns all ingredients those names equal the names parameters,
+	// ignoring case and spaces.
 	FindNamed(names []string) ([]model.Ingredient, error)
 
+	// GetByID returns an ingredient by its ID.
+	GetByID(ingredientID int) (model.Ingredient, error)
+
 	//GetOrCreate creates an ingredient if it's not already created or retuns it if so.
 	// this fonction is mostly used for testing.
 	GetOrCreate(name string) (model.Ingredient, error)
+
+	// Delete moves an ingredient to the trash.
+	Delete(ingredientID int) error
+
+	// FindDeleted returns all ingredients in the trash.
+	FindDeleted() ([]model.Ingredient, error)
+
+	// Restore takes an ingredient out of the trash.
+	Restore(ingredientID int) error
+
+	// Purge permanently removes ingredients moved to the trash before deletedBefore.
+	Purge(deletedBefore time.Time) (int64, error)
 }
 
 type gormIngredientRepo struct {
@@ -51,9 +75,16 @@ func (r gormIngredientRepo) FindAll() ([]model.Ingredient, error) {
 	return ingredients, nil
 }
 
+func (r gormIngredientRepo) FindInBatches(batchSize int, fn func([]model.Ingredient) error) error {
+	var ingredients []model.Ingredient
+	return r.db.Order("id").FindInBatches(&ingredients, batchSize, func(tx *gorm.DB, batch int) error {
+		return fn(ingredients)
+	}).Error
+}
+
 func (r gormIngredientRepo) IsNotCreated(ingredient model.Ingredient) (bool, error) {
 	var ingredientB model.Ingredient
-	err := r.db.Where("name=?", ingredient.Name).First(&ingredientB).Error
+	err := r.db.Unscoped().Where("name_key=?", util.NameKey(ingredient.Name)).First(&ingredientB).Error
 
 	if err != nil && errors.Is(err, gorm.ErrRecordNotFound) {
 		return true, nil
@@ -61,10 +92,19 @@ func (r gormIngredientRepo) IsNotCreated(ingredient model.Ingredient) (bool, err
 	return false, err
 }
 
+func (r gormIngredientRepo) GetByID(ingredientID int) (model.Ingredient, error) {
+	var ingredient model.Ingredient
+	err := r.db.Where("id = ?", ingredientID).First(&ingredient).Error
+	if err != nil && errors.Is(err, gorm.ErrRecordNotFound) {
+		return ingredient, exception.ErrRecordNotFound
+	}
+	return ingredient, err
+}
+
 func (r gormIngredientRepo) FindNamed(names []string) ([]model.Ingredient, error) {
 	var ingredients []model.Ingredient
 
-	err := r.db.Where("name IN ?", names).Find(&ingredients).Error
+	err := r.db.Where("name_key IN ?", util.NameKeys(names)).Find(&ingredients).Error
 	if err != nil {
 		return nil, err
 	}
@@ -73,19 +113,93 @@ func (r gormIngredientRepo) FindNamed(names []string) ([]model.Ingredient, error
 }
 
 func (r gormIngredientRepo) GetOrCreate(name string) (model.Ingredient, error) {
-	ingredient := model.Ingredient{Name: name}
-	err := r.db.Create(&ingredient).Error
-
-	var errUniqueFailedMsg = "UNIQUE constraint failed: ingredients.name"
-	if err != nil && err.Error() != errUniqueFailedMsg {
+	var ingredient model.Ingredient
+	err := r.db.Where("name_key = ?", util.NameKey(name)).First(&ingredient).Error
+	if err == nil || !errors.Is(err, gorm.ErrRecordNotFound) {
 		return ingredient, err
 	}
 
-	if ingredient.ID != 0 {
-		return ingredient, nil
+	ingredient = model.Ingredient{Name: name}
+	err = r.db.Create(&ingredient).Error
+	return ingredient, err
+}
+
+func (r gormIngredientRepo) Delete(ingredientID int) error {
+	result := r.db.Delete(&model.Ingredient{}, ingredientID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return exception.ErrRecordNotFound
 	}
+	return nil
+}
 
-	err = r.db.Where("name=?", ingredient.Name).First(&ingredient).Error
+func (r gormIngredientRepo) FindDeleted() ([]model.Ingredient, error) {
+	var ingredients []model.Ingredient
+	err := r.db.Unscoped().Where("deleted_at IS NOT NULL").Find(&ingredients).Error
+	return ingredients, err
+}
 
-	return ingredient, err
+func (r gormIngredientRepo) Restore(ingredientID int) error {
+	result := r.db.Unscoped().Model(&model.Ingredient{}).
+		Where("id = ? AND deleted_at IS NOT NULL", ingredientID).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return exception.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r gormIngredientRepo) Purge(deletedBefore time.Time) (int64, error) {
+	var count int64
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		purged := tx.Unscoped().Model(&model.Ingredient{}).
+			Select("id").
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", deletedBefore)
+
+		err := tx.Exec("DELETE FROM recipe_ingredients WHERE ingredient_id IN (?)", purged).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Exec("DELETE FROM ingredient_translations WHERE ingredient_id IN (?)", purged).Error
+		if err != nil {
+			return err
+		}
+
+		// substitutions of or with purged ingredients are useless
+		var substitutionIDs []int
+		err = tx.Model(&model.Substitution{}).
+			Where("ingredient_id IN (?) OR id IN (?)", purged,
+				tx.Model(&model.Substitute{}).Select("substitution_id").Where("ingredient_id IN (?)", purged)).
+			Pluck("id", &substitutionIDs).Error
+		if err != nil {
+			return err
+		}
+
+		if len(substitutionIDs) != 0 {
+			err = tx.Where("substitution_id IN ?", substitutionIDs).Delete(&model.Substitute{}).Error
+			if err != nil {
+				return err
+			}
+
+			err = tx.Delete(&model.Substitution{}, substitutionIDs).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		result := tx.Unscoped().
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", deletedBefore).
+			Delete(&model.Ingredient{})
+		count = result.RowsAffected
+		return result.Error
+	})
+
+	return count, err
 }
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/denisyao1/welsh-academy-api/exception"
+	"github.com/denisyao1/welsh-academy-api/model"
+	"gorm.io/gorm"
+)
+
+type InvitationRepository interface {
+	// Create adds an invitation to DB.
+	Create(invitation *model.Invitation) error
+
+	// List returns the invitations with their uses, most recent first.
+	List() ([]model.Invitation, error)
+
+	// GetByHash returns the invitation whose code has a hash.
+	//
+	// It returns exception.ErrRecordNotFound if there is none.
+	GetByHash(hash string) (model.Invitation, error)
+
+	// Revoke prevents the invitation with an ID from being used again.
+	//
+	// It returns exception.ErrRecordNotFound if there is no such active invitation.
+	Revoke(id int) error
+
+	// Redeem creates a user with an invitation, taking one of its uses.
+	// It returns false if it is revoked, expired or used up meanwhile.
+	Redeem(invitationID int, user *model.User) (bool, error)
+}
+
+type gormInvitationRepo struct {
+	db *gorm.DB
+}
+
+func NewGormInvitationRepository(db *gorm.DB) InvitationRepository {
+	return &gormInvitationRepo{db: db}
+}
+
+func (r gormInvitationRepo) Create(invitation *model.Invitation) error {
+	return r.db.Create(invitation).Error
+}
+
+func (r gormInvitationRepo) List() ([]model.Invitation, error) {
+	var invitations []model.Invitation
+	err := r.db.Preload("Uses", func(db *gorm.DB) *gorm.DB {
+		return db.Order("used_at")
+	}).Order("created_at DESC, id DESC").Find(&invitations).Error
+	return invitations, err
+}
+
+func (r gormInvitationRepo) GetByHash(hash string) (model.Invitation, error) {
+	var invitation model.Invitation
+	err := r.db.Where("hash = ?", hash).First(&invitation).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return invitation, exception.ErrRecordNotFound
+	}
+	return invitation, err
+}
+
+func (r gormInvitationRepo) Revoke(id int) error {
+	result := r.db.Model(&model.Invitation{}).Where("id = ? AND revoked_at IS NULL", id).Update("revoked_at", time.Now())
+	if result.Error == nil && result.RowsAffected == 0 {
+		return exception.ErrRecordNotFound
+	}
+	return result.Error
+}
+
+// errInvitationTaken rolls back a redemption whose invitation can't be used anymore.
+var errInvitationTaken = errors.New("invitation taken")
+
+func (r gormInvitationRepo) Redeem(invitationID int, user *model.User) (bool, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		// the condition makes the uses beyond the maximum fail
+		result := tx.Model(&model.Invitation{}).
+			Where("id = ? AND revoked_at IS NULL AND expires_at > ? AND use_count < max_uses", invitationID, now).
+			Update("use_count", gorm.Expr("use_count + 1"))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errInvitationTaken
+		}
+
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+
+		use := model.InvitationUse{InvitationID: invitationID, UserID: user.ID, Username: user.Username, UsedAt: now}
+		return tx.Create(&use).Error
+	})
+	if errors.Is(err, errInvitationTaken) {
+		return false, nil
+	}
+	return err == nil, err
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/denisyao1/welsh-academy-api/model"
+	"gorm.io/gorm"
+)
+
+type LoginFailureRepository interface {
+	// Find returns the failures with IDs, the IDs without failure being left out.
+	Find(ids ...string) ([]model.LoginFailure, error)
+
+	// Save adds or replaces failures.
+	Save(failure model.LoginFailure) error
+
+	// Delete removes the failures with IDs.
+	Delete(ids ...string) error
+
+	// DeleteBefore removes the failures whose last one is older than a time.
+	DeleteBefore(t time.Time) error
+}
+
+type gormLoginFailureRepo struct {
+	db *gorm.DB
+}
+
+func NewGormLoginFailureRepository(db *gorm.DB) LoginFailureRepository {
+	return &gormLoginFailureRepo{db: db}
+}
+
+func (r gormLoginFailureRepo) Find(ids ...string) ([]model.LoginFailure, error) {
+	var failures []model.LoginFailure
+	err := r.db.Where("id IN ?", ids).Find(&failures).Error
+	return failures, err
+}
+
+func (r gormLoginFailureRepo) Save(failure model.LoginFailure) error {
+	return r.db.Save(&failure).Error
+}
+
+func (r gormLoginFailureRepo) Delete(ids ...string) error {
+	return r.db.Where("id IN ?", ids).Delete(&model.LoginFailure{}).Error
+}
+
+func (r gormLoginFailureRepo) DeleteBefore(t time.Time) error {
+	return r.db.Where("last_failed_at < ?", t).Delete(&model.LoginFailure{}).Error
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/denisyao1/welsh-academy-api/exception"
+	"github.com/denisyao1/welsh-academy-api/model"
+	"gorm.io/gorm"
+)
+
+type MFARepository interface {
+	// SetRecoveryCodes replaces the recovery codes of a user.
+	SetRecoveryCodes(userID int, codes []model.RecoveryCode) error
+
+	// UseRecoveryCode marks the unused recovery code of a user with a hash as used.
+	// It returns false if there is none.
+	UseRecoveryCode(userID int, hash string) (bool, error)
+
+	// CreateChallenge adds a challenge to DB, removing the expired ones.
+	CreateChallenge(challenge *model.MFAChallenge) error
+
+	// GetChallenge returns the challenge with an ID.
+	//
+	// It returns exception.ErrRecordNotFound if there is none.
+	GetChallenge(id string) (model.MFAChallenge, error)
+
+	// DeleteChallenge removes the challenge with an ID. It returns false if there is none.
+	DeleteChallenge(id string) (bool, error)
+}
+type gormMFARepo struct {
+	db *gorm.DB
+}
+
+func NewGormMFARepository(db *gorm.DB) MFARepository {
+	return &gormMFARepo{db: db}
+}
+
+func (r gormMFARepo) SetRecoveryCodes(userID int, codes []model.RecoveryCode) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&model.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+func (r gormMFARepo) UseRecoveryCode(userID int, hash string) (bool, error) {
+	// the condition makes concurrent uses of the code fail
+	result := r.db.Model(&model.RecoveryCode{}).
+		Where("user_id = ? AND hash = ? AND used_at IS NULL", userID, hash).
+		Update("used_at", time.Now())
+	return result.RowsAffected != 0, result.Error
+}
+
+func (r gormMFARepo) CreateChallenge(challenge *model.MFAChallenge) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("expires_at <= ?", time.Now()).Delete(&model.MFAChallenge{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(challenge).Error
+	})
+}
+
+func (r gormMFARepo) GetChallenge(id string) (model.MFAChallenge, error) {
+	var challenge model.MFAChallenge
+	err := r.db.Where("id = ?", id).First(&challenge).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return challenge, exception.ErrRecordNotFound
+	}
+	return challenge, err
+}
+
+func (r gormMFARepo) DeleteChallenge(id string) (bool, error) {
+	result := r.db.Where("id = ?", id).Delete(&model.MFAChallenge{})
+	return result.RowsAffected != 0, result.Error
+}
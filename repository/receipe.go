@@ -2,28 +2,47 @@ package repository
 
 import (
 	"errors"
+	"time"
 
 	"github.com/denisyao1/welsh-academy-api/exception"
 	"github.com/denisyao1/welsh-academy-api/model"
+	"github.com/denisyao1/welsh-academy-api/util"
 	"gorm.io/gorm"
 )
 
 type RecipeRepository interface {
-	// Create adds new recipe to DB.
+	// Create adds new recipe to DB, with its ingredients without ID.
 	Create(recipe *model.Recipe) error
 
 	// IsNotCreated returns true is the recipe is not in the DB else false.
+	// Names are compared ignoring case and spaces.
+	// Recipes in the trash are taken into account as they still hold their name.
 	IsNotCreated(recipe model.Recipe) (bool, error)
 
 	// FindAll returns all recipes in the DB.
 	FindAll() ([]model.Recipe, error)
 
+	// FindInBatches calls fn with all recipes in the DB, batchSize at a time.
+	FindInBatches(batchSize int, fn func(recipes []model.Recipe) error) error
+
 	// FindAllContainging returns all recipes those ingredients name are in ingredientNames.
 	FindAllContainging(ingredientNames []string) ([]model.Recipe, error)
 
-	// GetByID retunrs recipe a model by its ID.
+	// GetByID retunrs recipe a model with its ingredients by its ID.
 	GetByID(recipeID int) (model.Recipe, error)
 
+	// FindByIDs returns the recipes with their ingredients whose IDs are in recipeIDs.
+	FindByIDs(recipeIDs []int) ([]model.Recipe, error)
+
+	// FindRelated returns the other recipes sharing at least one ingredient or tag with a recipe.
+	FindRelated(recipeID int) ([]model.Recipe, error)
+
+	// Count returns the number of recipes in the DB.
+	Count() (int64, error)
+
+	// CountByIngredient returns the number of recipes containing each ingredient of ingredientIDs.
+	CountByIngredient(ingredientIDs []int) (map[int]int64, error)
+
 	// IsInUserFavorites returns true if a recipe is in user favorites else false.
 	IsInUserFavorites(userID, recipeID int) (bool, error)
 
@@ -36,9 +55,27 @@ type RecipeRepository interface {
 	// FindFavorites returns all user favorite recipes.
 	FindFavorites(userID int) ([]model.Recipe, error)
 
+	// FindFavoriteIDs returns the IDs of all user favorite recipes.
+	FindFavoriteIDs(userID int) ([]int, error)
+
+	// FindAllFavorites returns the favorites of all users.
+	FindAllFavorites() ([]model.UserFavorite, error)
+
 	//GetOrCreate creates a recipe if it's not already created or retuns it if so.
 	// this fonction is mostly used for testing.
 	GetOrCreate(recipe *model.Recipe) error
+
+	// Delete moves a recipe to the trash.
+	Delete(recipeID int) error
+
+	// FindDeleted returns all recipes in the trash.
+	FindDeleted() ([]model.Recipe, error)
+
+	// Restore takes a recipe out of the trash.
+	Restore(recipeID int) error
+
+	// Purge permanently removes recipes moved to the trash before deletedBefore.
+	Purge(deletedBefore time.Time) (int64, error)
 }
 
 type gormRecipeRepo struct {
@@ -51,7 +88,7 @@ func NewGormRecipeRepository(db *gorm.DB) RecipeRepository {
 
 func (r gormRecipeRepo) IsNotCreated(recipe model.Recipe) (bool, error) {
 	var recipeB model.Recipe
-	err := r.db.Where("name=?", recipe.Name).First(&recipeB).Error
+	err := r.db.Unscoped().Where("name_key=?", util.NameKey(recipe.Name)).First(&recipeB).Error
 	if err != nil && errors.Is(err, gorm.ErrRecordNotFound) {
 		return true, nil
 	}
@@ -59,19 +96,45 @@ func (r gormRecipeRepo) IsNotCreated(recipe model.Recipe) (bool, error) {
 }
 
 func (r gormRecipeRepo) Create(recipe *model.Recipe) error {
-	err := r.db.Create(recipe).Error
-	return err
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// the ingredients are only created with the recipe
+		for i := range recipe.Ingredients {
+			if recipe.Ingredients[i].ID != 0 {
+				continue
+			}
+			if err := tx.Create(&recipe.Ingredients[i]).Error; err != nil {
+				return err
+			}
+		}
+
+		// reuse existing tags
+		for i := range recipe.Tags {
+			err := tx.Where("name = ?", recipe.Tags[i].Name).FirstOrCreate(&recipe.Tags[i]).Error
+			if err != nil {
+				return err
+			}
+		}
+		return tx.Create(recipe).Error
+	})
 }
 
 func (r gormRecipeRepo) FindAll() ([]model.Recipe, error) {
 	var recipes []model.Recipe
 
 	err := r.db.Model(&model.Recipe{}).
-		Preload("Ingredients").
+		Preload("Ingredients").Preload("Tags").
 		Find(&recipes).Error
 	return recipes, err
 }
 
+func (r gormRecipeRepo) FindInBatches(batchSize int, fn func([]model.Recipe) error) error {
+	var recipes []model.Recipe
+	return r.db.Preload("Ingredients").Preload("Tags").Order("id").
+		FindInBatches(&recipes, batchSize, func(tx *gorm.DB, batch int) error {
+			return fn(recipes)
+		}).Error
+}
+
 func (r gormRecipeRepo) FindAllContainging(ingredientNames []string) ([]model.Recipe, error) {
 	var recipes []model.Recipe
 
@@ -79,10 +142,10 @@ func (r gormRecipeRepo) FindAllContainging(ingredientNames []string) ([]model.Re
 		Select("r.id").
 		Joins("INNER JOIN recipe_ingredients ri ON ri.recipe_id=r.id").
 		Joins("INNER JOIN ingredients ing ON ing.id=ri.ingredient_id").
-		Where("ing.name in ?", ingredientNames)
+		Where("ing.name_key in ? AND ing.deleted_at IS NULL", util.NameKeys(ingredientNames))
 
 	err := r.db.Model(&model.Recipe{}).
-		Preload("Ingredients").
+		Preload("Ingredients").Preload("Tags").
 		Where("id in (?)", subQuery).
 		Find(&recipes).Error
 	return recipes, err
@@ -91,13 +154,70 @@ func (r gormRecipeRepo) FindAllContainging(ingredientNames []string) ([]model.Re
 
 func (r gormRecipeRepo) GetByID(recipeID int) (model.Recipe, error) {
 	var recipe model.Recipe
-	err := r.db.Where("id = ?", recipeID).First(&recipe).Error
+	err := r.db.Where("id = ?", recipeID).Preload("Ingredients").Preload("Tags").First(&recipe).Error
 	if err != nil && errors.Is(err, gorm.ErrRecordNotFound) {
 		return recipe, exception.ErrRecordNotFound
 	}
 	return recipe, err
 }
 
+func (r gormRecipeRepo) FindByIDs(recipeIDs []int) ([]model.Recipe, error) {
+	var recipes []model.Recipe
+	err := r.db.Where("id IN ?", recipeIDs).Preload("Ingredients").Preload("Tags").Find(&recipes).Error
+	return recipes, err
+}
+
+func (r gormRecipeRepo) FindRelated(recipeID int) ([]model.Recipe, error) {
+	var recipes []model.Recipe
+
+	sharingIngredient := r.db.Table("recipe_ingredients AS ri").
+		Select("other.recipe_id").
+		Joins("INNER JOIN recipe_ingredients other ON other.ingredient_id=ri.ingredient_id").
+		Joins("INNER JOIN ingredients ing ON ing.id=ri.ingredient_id").
+		Where("ri.recipe_id = ? AND ing.deleted_at IS NULL", recipeID)
+
+	sharingTag := r.db.Table("recipe_tags AS rt").
+		Select("other.recipe_id").
+		Joins("INNER JOIN recipe_tags other ON other.tag_id=rt.tag_id").
+		Where("rt.recipe_id = ?", recipeID)
+
+	err := r.db.Model(&model.Recipe{}).
+		Preload("Ingredients").Preload("Tags").
+		Where("id <> ?", recipeID).
+		Where("id in (?) OR id in (?)", sharingIngredient, sharingTag).
+		Find(&recipes).Error
+	return recipes, err
+}
+
+func (r gormRecipeRepo) Count() (int64, error) {
+	var count int64
+	err := r.db.Model(&model.Recipe{}).Count(&count).Error
+	return count, err
+}
+
+func (r gormRecipeRepo) CountByIngredient(ingredientIDs []int) (map[int]int64, error) {
+	var rows []struct {
+		IngredientID int
+		Count        int64
+	}
+
+	err := r.db.Table("recipe_ingredients AS ri").
+		Select("ri.ingredient_id, COUNT(*) AS count").
+		Joins("INNER JOIN recipes r ON r.id=ri.recipe_id").
+		Where("ri.ingredient_id IN ? AND r.deleted_at IS NULL", ingredientIDs).
+		Group("ri.ingredient_id").
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[int]int64)
+	for _, row := range rows {
+		counts[row.IngredientID] = row.Count
+	}
+	return counts, nil
+}
+
 func (r gormRecipeRepo) IsInUserFavorites(userID int, recipeID int) (bool, error) {
 	var userFavorite model.UserFavorite
 	err := r.db.Table("user_favorites").
@@ -134,29 +254,109 @@ func (r gormRecipeRepo) FindFavorites(userID int) ([]model.Recipe, error) {
 		Select("r.id").
 		Joins("INNER JOIN user_favorites f ON f.recipe_id=r.id").
 		Joins("INNER JOIN users u ON u.id=f.user_id").
-		Where("u.id = ?", userID)
+		Where("u.id = ? AND u.deleted_at IS NULL", userID)
 
 	err := r.db.Model(&model.Recipe{}).
-		Preload("Ingredients").
+		Preload("Ingredients").Preload("Tags").
 		Where("id in (?)", subQuery).
 		Find(&recipes).Error
 
 	return recipes, err
 }
 
-func (r gormRecipeRepo) GetOrCreate(recipe *model.Recipe) error {
-	err := r.db.Create(&recipe).Error
+func (r gormRecipeRepo) FindFavoriteIDs(userID int) ([]int, error) {
+	var recipeIDs []int
+	err := r.db.Table("user_favorites").
+		Where("user_id = ?", userID).
+		Pluck("recipe_id", &recipeIDs).Error
+	return recipeIDs, err
+}
 
-	var errUniqueMsg = "UNIQUE constraint failed: recipes.name"
-	if err != nil && err.Error() != errUniqueMsg {
+func (r gormRecipeRepo) FindAllFavorites() ([]model.UserFavorite, error) {
+	var favorites []model.UserFavorite
+	err := r.db.Table("user_favorites f").
+		Select("f.user_id, f.recipe_id").
+		Joins("INNER JOIN users u ON u.id=f.user_id").
+		Where("u.deleted_at IS NULL").
+		Find(&favorites).Error
+	return favorites, err
+}
+
+func (r gormRecipeRepo) GetOrCreate(recipe *model.Recipe) error {
+	err := r.db.Where("name_key = ?", util.NameKey(recipe.Name)).
+		Preload("Ingredients").Preload("Tags").
+		First(recipe).Error
+	if err == nil || !errors.Is(err, gorm.ErrRecordNotFound) {
 		return err
 	}
 
-	if recipe.ID != 0 {
-		return nil
+	return r.db.Create(recipe).Error
+}
+
+func (r gormRecipeRepo) Delete(recipeID int) error {
+	result := r.db.Delete(&model.Recipe{}, recipeID)
+	if result.Error != nil {
+		return result.Error
 	}
+	if result.RowsAffected == 0 {
+		return exception.ErrRecordNotFound
+	}
+	return nil
+}
 
-	err = r.db.Where("name=?", recipe.Name).Preload("Ingredients").First(&recipe).Error
+func (r gormRecipeRepo) FindDeleted() ([]model.Recipe, error) {
+	var recipes []model.Recipe
+	err := r.db.Unscoped().Where("deleted_at IS NOT NULL").Find(&recipes).Error
+	return recipes, err
+}
+
+func (r gormRecipeRepo) Restore(recipeID int) error {
+	result := r.db.Unscoped().Model(&model.Recipe{}).
+		Where("id = ? AND deleted_at IS NOT NULL", recipeID).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return exception.ErrRecordNotFound
+	}
+	return nil
+}
 
-	return err
+func (r gormRecipeRepo) Purge(deletedBefore time.Time) (int64, error) {
+	var count int64
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		purged := tx.Unscoped().Model(&model.Recipe{}).
+			Select("id").
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", deletedBefore)
+
+		err := tx.Exec("DELETE FROM recipe_ingredients WHERE recipe_id IN (?)", purged).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Exec("DELETE FROM recipe_translations WHERE recipe_id IN (?)", purged).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Exec("DELETE FROM recipe_tags WHERE recipe_id IN (?)", purged).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Exec("DELETE FROM user_favorites WHERE recipe_id IN (?)", purged).Error
+		if err != nil {
+			return err
+		}
+
+		result := tx.Unscoped().
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", deletedBefore).
+			Delete(&model.Recipe{})
+		count = result.RowsAffected
+		return result.Error
+	})
+
+	return count, err
 }
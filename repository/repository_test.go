@@ -57,3 +57,65 @@ func TestGetOrCreateRecipe(t *testing.T) {
 	ok := len(recipe1.Ingredients) == len(recipe2.Ingredients)
 	assert.True(ok, "recipes ingredients length should be the same")
 }
+
+func TestCreateRecipeWithNewIngredients(t *testing.T) {
+	t.Parallel()
+
+	assert := assert.New(t)
+
+	db, _ := database.NewInMemoryDB(false)
+	db.Migrate(model.Ingredient{}, model.Recipe{}, model.Tag{})
+
+	ingredientRepo := NewGormIngredientRepository(db.GetDB())
+	recipeRepo := NewGormRecipeRepository(db.GetDB())
+
+	ingredient1, _ := ingredientRepo.GetOrCreate("ingredient1")
+	recipe1 := model.Recipe{
+		Name:        "recipe1",
+		Making:      "dummy",
+		Ingredients: []model.Ingredient{ingredient1, {Name: "ingredient2"}}}
+	assert.NoError(recipeRepo.Create(&recipe1))
+	assert.True(recipe1.Ingredients[1].ID != 0, "new ingredient should be created with the recipe")
+
+	// the recipe name is taken, nothing is created
+	recipe2 := model.Recipe{
+		Name:        "recipe1",
+		Making:      "dummy",
+		Ingredients: []model.Ingredient{{Name: "ingredient3"}}}
+	assert.Error(recipeRepo.Create(&recipe2))
+	ok, _ := ingredientRepo.IsNotCreated(model.Ingredient{Name: "ingredient3"})
+	assert.True(ok, "new ingredient of a recipe failing to be created should be rolled back")
+}
+
+func TestSetRoles(t *testing.T) {
+	t.Parallel()
+
+	assert := assert.New(t)
+
+	db, _ := database.NewInMemoryDB(false)
+	db.Migrate(model.User{}, model.UserRole{})
+
+	userRepo := NewUserRepository(db.GetDB())
+
+	admin := model.User{Username: "chef", Password: "x", Roles: []model.UserRole{{Role: model.RoleAdmin}}}
+	cook := model.User{Username: "cook", Password: "x", Roles: []model.UserRole{{Role: model.RoleReader}}}
+	userRepo.Create(&admin)
+	userRepo.Create(&cook)
+
+	count, err := userRepo.CountWithRole(model.RoleAdmin)
+	assert.NoError(err)
+	assert.Equal(int64(1), count, "there should be one admin")
+
+	assert.NoError(userRepo.SetRoles(cook.ID, []model.Role{model.RoleAdmin, model.RoleEditor}))
+	cook = model.User{ID: cook.ID}
+	userRepo.GetByID(&cook)
+	assert.ElementsMatch([]model.Role{model.RoleAdmin, model.RoleEditor}, cook.RoleNames(), "roles should be replaced")
+
+	count, _ = userRepo.CountWithRole(model.RoleAdmin)
+	assert.Equal(int64(2), count, "there should be two admins")
+
+	// users in the trash don't count
+	userRepo.Delete(admin.ID)
+	count, _ = userRepo.CountWithRole(model.RoleAdmin)
+	assert.Equal(int64(1), count, "deleted admin should not count")
+}
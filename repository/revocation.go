@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/denisyao1/welsh-academy-api/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RevocationRepository interface {
+	// Create adds a revocation to DB, extending the existing one with the same ID.
+	Create(revocation model.Revocation) error
+
+	// FindActive returns the revocations which aren't expired.
+	FindActive() ([]model.Revocation, error)
+
+	// DeleteExpired removes the expired revocations.
+	DeleteExpired() error
+}
+
+type gormRevocationRepo struct {
+	db *gorm.DB
+}
+
+func NewGormRevocationRepository(db *gorm.DB) RevocationRepository {
+	return &gormRevocationRepo{db: db}
+}
+
+func (r gormRevocationRepo) Create(revocation model.Revocation) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"expires_at"}),
+	}).Create(&revocation).Error
+}
+
+func (r gormRevocationRepo) FindActive() ([]model.Revocation, error) {
+	var revocations []model.Revocation
+	err := r.db.Where("expires_at > ?", time.Now()).Find(&revocations).Error
+	return revocations, err
+}
+
+func (r gormRevocationRepo) DeleteExpired() error {
+	return r.db.Where("expires_at <= ?", time.Now()).Delete(&model.Revocation{}).Error
+}
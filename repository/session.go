@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/denisyao1/welsh-academy-api/exception"
+	"github.com/denisyao1/welsh-academy-api/model"
+	"gorm.io/gorm"
+)
+
+type SessionRepository interface {
+	// Create adds a session to DB.
+	Create(session *model.Session) error
+
+	// GetByID returns a session.
+	//
+	// It returns exception.ErrRecordNotFound if the session doesn't exist.
+	GetByID(sessionID string) (model.Session, error)
+
+	// FindActive returns the sessions of a user which are neither revoked nor expired,
+	// most recently used first.
+	FindActive(userID int) ([]model.Session, error)
+
+	// Touch records a use of a session, which can then last until expiresAt.
+	Touch(sessionID string, ip string, expiresAt time.Time) error
+
+	// Revoke revokes sessions and their refresh tokens.
+	Revoke(sessionIDs ...string) error
+}
+
+type gormSessionRepo struct {
+	db *gorm.DB
+}
+
+func NewGormSessionRepository(db *gorm.DB) SessionRepository {
+	return &gormSessionRepo{db: db}
+}
+
+func (r gormSessionRepo) Create(session *model.Session) error {
+	return r.db.Create(session).Error
+}
+
+func (r gormSessionRepo) GetByID(sessionID string) (model.Session, error) {
+	var session model.Session
+	err := r.db.Where("id = ?", sessionID).First(&session).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return session, exception.ErrRecordNotFound
+	}
+	return session, err
+}
+
+func (r gormSessionRepo) FindActive(userID int) ([]model.Session, error) {
+	var sessions []model.Session
+	err := r.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_used_at DESC").Find(&sessions).Error
+	return sessions, err
+}
+
+func (r gormSessionRepo) Touch(sessionID string, ip string, expiresAt time.Time) error {
+	return r.db.Model(&model.Session{}).Where("id = ?", sessionID).
+		Updates(map[string]any{"ip": ip, "last_used_at": time.Now(), "expires_at": expiresAt}).Error
+}
+
+func (r gormSessionRepo) Revoke(sessionIDs ...string) error {
+	if len(sessionIDs) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&model.Session{}).Where("id IN ? AND revoked_at IS NULL", sessionIDs).
+			Update("revoked_at", now).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&model.RefreshToken{}).Where("family IN ? AND revoked_at IS NULL", sessionIDs).
+			Update("revoked_at", now).Error
+	})
+}
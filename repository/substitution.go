@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"github.com/denisyao1/welsh-academy-api/exception"
+	"github.com/denisyao1/welsh-academy-api/model"
+	"gorm.io/gorm"
+)
+
+type SubstitutionRepository interface {
+	// Create adds new substitution with its substitutes to DB.
+	Create(substitution *model.Substitution) error
+
+	// FindAll returns all substitutions with their ingredients.
+	FindAll() ([]model.Substitution, error)
+
+	// FindForIngredient returns the substitutions of an ingredient with their ingredients.
+	FindForIngredient(ingredientID int) ([]model.Substitution, error)
+
+	// Delete removes a substitution with its substitutes.
+	Delete(substitutionID int) error
+}
+
+type gormSubstitutionRepo struct {
+	db *gorm.DB
+}
+
+func NewGormSubstitutionRepository(db *gorm.DB) SubstitutionRepository {
+	return &gormSubstitutionRepo{db: db}
+}
+
+func (r gormSubstitutionRepo) Create(substitution *model.Substitution) error {
+	// ingredients already exist, only link them
+	return r.db.Omit("Ingredient", "Substitutes.Ingredient").Create(substitution).Error
+}
+
+func (r gormSubstitutionRepo) FindAll() ([]model.Substitution, error) {
+	var substitutions []model.Substitution
+	err := r.preload().Order("id").Find(&substitutions).Error
+	return substitutions, err
+}
+
+func (r gormSubstitutionRepo) FindForIngredient(ingredientID int) ([]model.Substitution, error) {
+	var substitutions []model.Substitution
+	err := r.preload().Where("ingredient_id = ?", ingredientID).Order("id").Find(&substitutions).Error
+	return substitutions, err
+}
+
+func (r gormSubstitutionRepo) Delete(substitutionID int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("substitution_id = ?", substitutionID).Delete(&model.Substitute{}).Error
+		if err != nil {
+			return err
+		}
+
+		result := tx.Delete(&model.Substitution{}, substitutionID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return exception.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+func (r gormSubstitutionRepo) preload() *gorm.DB {
+	return r.db.Preload("Ingredient").Preload("Substitutes.Ingredient")
+}
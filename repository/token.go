@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/denisyao1/welsh-academy-api/exception"
+	"github.com/denisyao1/welsh-academy-api/model"
+	"gorm.io/gorm"
+)
+
+type RefreshTokenRepository interface {
+	// Create adds a refresh token to DB.
+	Create(token *model.RefreshToken) error
+
+	// GetByHash returns the refresh token with a hash.
+	//
+	// It returns exception.ErrRecordNotFound if there is none.
+	GetByHash(hash string) (model.RefreshToken, error)
+
+	// Rotate marks a refresh token as used and adds the token replacing it.
+	// It returns false if the token was already used or revoked.
+	Rotate(tokenID int, replacement *model.RefreshToken) (bool, error)
+
+	// Replace revokes the refresh tokens of a session still in use and adds the token replacing them.
+	Replace(replacement *model.RefreshToken) error
+}
+
+type gormRefreshTokenRepo struct {
+	db *gorm.DB
+}
+
+func NewGormRefreshTokenRepository(db *gorm.DB) RefreshTokenRepository {
+	return &gormRefreshTokenRepo{db: db}
+}
+
+func (r gormRefreshTokenRepo) Create(token *model.RefreshToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r gormRefreshTokenRepo) GetByHash(hash string) (model.RefreshToken, error) {
+	var token model.RefreshToken
+	err := r.db.Where("hash = ?", hash).First(&token).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return token, exception.ErrRecordNotFound
+	}
+	return token, err
+}
+
+func (r gormRefreshTokenRepo) Rotate(tokenID int, replacement *model.RefreshToken) (bool, error) {
+	rotated := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// the condition makes concurrent uses of the token fail
+		result := tx.Model(&model.RefreshToken{}).
+			Where("id = ? AND used_at IS NULL AND revoked_at IS NULL", tokenID).
+			Update("used_at", time.Now())
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		rotated = true
+		return tx.Create(replacement).Error
+	})
+	return rotated && err == nil, err
+}
+
+func (r gormRefreshTokenRepo) Replace(replacement *model.RefreshToken) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&model.RefreshToken{}).
+			Where("family = ? AND used_at IS NULL AND revoked_at IS NULL", replacement.Family).
+			Update("revoked_at", time.Now()).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(replacement).Error
+	})
+}
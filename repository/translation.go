@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"github.com/denisyao1/welsh-academy-api/exception"
+	"github.com/denisyao1/welsh-academy-api/model"
+	"github.com/denisyao1/welsh-academy-api/util"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TranslationRepository interface {
+	// SaveIngredientTranslation creates or replaces the translation of an ingredient in a locale.
+	SaveIngredientTranslation(translation *model.IngredientTranslation) error
+
+	// SaveRecipeTranslation creates or replaces the translation of a recipe in a locale.
+	SaveRecipeTranslation(translation *model.RecipeTranslation) error
+
+	// FindIngredientTranslations returns all translations of an ingredient.
+	FindIngredientTranslations(ingredientID int) ([]model.IngredientTranslation, error)
+
+	// FindRecipeTranslations returns all translations of a recipe.
+	FindRecipeTranslations(recipeID int) ([]model.RecipeTranslation, error)
+
+	// FindIngredientTranslationsIn returns the translations of ingredients in a locale.
+	FindIngredientTranslationsIn(locale string, ingredientIDs []int) ([]model.IngredientTranslation, error)
+
+	// FindRecipeTranslationsIn returns the translations of recipes in a locale.
+	FindRecipeTranslationsIn(locale string, recipeIDs []int) ([]model.RecipeTranslation, error)
+
+	// FindIngredientTranslationsNamed returns the translations of ingredients
+	// in any locale whose names equal the names parameters, ignoring case and spaces.
+	FindIngredientTranslationsNamed(names []string) ([]model.IngredientTranslation, error)
+
+	// DeleteIngredientTranslation removes the translation of an ingredient in a locale.
+	DeleteIngredientTranslation(ingredientID int, locale string) error
+
+	// DeleteRecipeTranslation removes the translation of a recipe in a locale.
+	DeleteRecipeTranslation(recipeID int, locale string) error
+}
+
+type gormTranslationRepo struct {
+	db *gorm.DB
+}
+
+func NewGormTranslationRepository(db *gorm.DB) TranslationRepository {
+	return &gormTranslationRepo{db: db}
+}
+
+func (r gormTranslationRepo) SaveIngredientTranslation(translation *model.IngredientTranslation) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "ingredient_id"}, {Name: "locale"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "name_key"}),
+	}).Create(translation).Error
+}
+
+func (r gormTranslationRepo) SaveRecipeTranslation(translation *model.RecipeTranslation) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "recipe_id"}, {Name: "locale"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "making"}),
+	}).Create(translation).Error
+}
+
+func (r gormTranslationRepo) FindIngredientTranslations(ingredientID int) ([]model.IngredientTranslation, error) {
+	var translations []model.IngredientTranslation
+	err := r.db.Where("ingredient_id = ?", ingredientID).Order("locale").Find(&translations).Error
+	return translations, err
+}
+
+func (r gormTranslationRepo) FindRecipeTranslations(recipeID int) ([]model.RecipeTranslation, error) {
+	var translations []model.RecipeTranslation
+	err := r.db.Where("recipe_id = ?", recipeID).Order("locale").Find(&translations).Error
+	return translations, err
+}
+
+func (r gormTranslationRepo) FindIngredientTranslationsIn(locale string, ingredientIDs []int) ([]model.IngredientTranslation, error) {
+	var translations []model.IngredientTranslation
+	err := r.db.Where("locale = ? AND ingredient_id IN ?", locale, ingredientIDs).Find(&translations).Error
+	return translations, err
+}
+
+func (r gormTranslationRepo) FindRecipeTranslationsIn(locale string, recipeIDs []int) ([]model.RecipeTranslation, error) {
+	var translations []model.RecipeTranslation
+	err := r.db.Where("locale = ? AND recipe_id IN ?", locale, recipeIDs).Find(&translations).Error
+	return translations, err
+}
+
+func (r gormTranslationRepo) FindIngredientTranslationsNamed(names []string) ([]model.IngredientTranslation, error) {
+	var translations []model.IngredientTranslation
+	err := r.db.Where("name_key IN ?", util.NameKeys(names)).Find(&translations).Error
+	return translations, err
+}
+
+func (r gormTranslationRepo) DeleteIngredientTranslation(ingredientID int, locale string) error {
+	result := r.db.Where("ingredient_id = ? AND locale = ?", ingredientID, locale).
+		Delete(&model.IngredientTranslation{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return exception.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r gormTranslationRepo) DeleteRecipeTranslation(recipeID int, locale string) error {
+	result := r.db.Where("recipe_id = ? AND locale = ?", recipeID, locale).
+		Delete(&model.RecipeTranslation{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return exception.ErrRecordNotFound
+	}
+	return nil
+}
@@ -2,29 +2,69 @@ package repository
 
 import (
 	"errors"
+	"strings"
+	"time"
 
 	"github.com/denisyao1/welsh-academy-api/exception"
 	"github.com/denisyao1/welsh-academy-api/model"
+	"github.com/denisyao1/welsh-academy-api/util"
 	"gorm.io/gorm"
 )
 
 type UserRepository interface {
-	// IsNotCreated returns true if user is not present in DB else false
+	// IsNotCreated returns true if user is not present in DB else false.
+	// Usernames are compared ignoring case and spaces.
+	// Users in the trash are taken into account as they still hold their username.
 	IsNotCreated(user model.User) (bool, error)
 
 	// Create adds user to DB.
 	Create(user *model.User) error
 
-	// GetByUsername returns user model corresponding to username.
+	// GetByUsername returns user model corresponding to username, ignoring case and spaces.
 	GetByUsername(user *model.User) error
 
 	// GetByID returns user from DB by its ID.
 	GetByID(user *model.User) error
 
-	// UpdatePassword updates user password.
+	// Find returns a page of users whose username contains search, ignoring case
+	// and spaces, ordered by username, along with the number of matching users.
+	Find(search string, offset int, limit int) ([]model.User, int64, error)
+
+	// SetDisabledAt disables a user from disabledAt, or enables it if disabledAt is nil.
+	SetDisabledAt(userID int, disabledAt *time.Time) error
+
+	// UpdatePassword updates user password, whether it must be changed and when it was changed.
 	UpdatePassword(user *model.User) error
+
+	// UpdateTOTP updates the TOTP secret of a user and whether it is enabled.
+	UpdateTOTP(user *model.User) error
+
+	// AcceptTOTPStep records the time step of a TOTP code accepted for a user.
+	// It returns false if a code of this step or a later one was already accepted.
+	AcceptTOTPStep(userID int, step int64) (bool, error)
+
+	// SetRoles replaces the roles assigned to a user.
+	SetRoles(userID int, roles []model.Role) error
+
+	// CountWithRole returns the number of enabled users, out of the trash, with a role.
+	CountWithRole(role model.Role) (int64, error)
+
+	// Delete moves a user to the trash and removes its favorites.
+	Delete(userID int) error
+
+	// FindDeleted returns all users in the trash.
+	FindDeleted() ([]model.User, error)
+
+	// Restore takes a user out of the trash.
+	Restore(userID int) error
+
+	// Purge permanently removes users moved to the trash before deletedBefore.
+	Purge(deletedBefore time.Time) (int64, error)
 }
 
+// likeEscaper escapes the wildcards of LIKE patterns.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
 type userRepo struct {
 	db *gorm.DB
 }
@@ -39,7 +79,7 @@ func (r userRepo) Create(user *model.User) error {
 
 func (r userRepo) IsNotCreated(user model.User) (bool, error) {
 	var userB model.User
-	err := r.db.Where("username=?", user.Username).First(&userB).Error
+	err := r.db.Unscoped().Where("username_key=?", util.NameKey(user.Username)).First(&userB).Error
 	if err != nil && errors.Is(err, gorm.ErrRecordNotFound) {
 		return true, nil
 	}
@@ -47,7 +87,7 @@ func (r userRepo) IsNotCreated(user model.User) (bool, error) {
 }
 
 func (r userRepo) GetByUsername(user *model.User) error {
-	err := r.db.Where("username=?", user.Username).First(user).Error
+	err := r.db.Preload("Roles").Where("username_key=?", util.NameKey(user.Username)).First(user).Error
 	if err != nil && errors.Is(err, gorm.ErrRecordNotFound) {
 		return exception.ErrRecordNotFound
 	}
@@ -55,17 +95,140 @@ func (r userRepo) GetByUsername(user *model.User) error {
 }
 
 func (r userRepo) GetByID(user *model.User) error {
-	err := r.db.Where("id=?", user.ID).First(user).Error
+	err := r.db.Preload("Roles").Where("id=?", user.ID).First(user).Error
 	if err != nil && errors.Is(err, gorm.ErrRecordNotFound) {
 		return exception.ErrRecordNotFound
 	}
 	return err
 }
 
+func (r userRepo) Find(search string, offset int, limit int) ([]model.User, int64, error) {
+	var users []model.User
+	var count int64
+
+	query := r.db.Model(&model.User{})
+	if key := util.NameKey(search); key != "" {
+		query = query.Where("username_key LIKE ? ESCAPE '\\'", "%"+likeEscaper.Replace(key)+"%")
+	}
+
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Preload("Roles").Order("username_key").Offset(offset).Limit(limit).Find(&users).Error
+	return users, count, err
+}
+
+func (r userRepo) SetDisabledAt(userID int, disabledAt *time.Time) error {
+	result := r.db.Model(&model.User{}).Where("id = ?", userID).Update("disabled_at", disabledAt)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return exception.ErrRecordNotFound
+	}
+	return nil
+}
+
 func (r userRepo) UpdatePassword(user *model.User) error {
-	err := r.db.Model(user).Update("password", user.Password).Error
+	err := r.db.Model(user).Select("password", "must_change_password", "password_changed_at").Updates(user).Error
 	if err != nil && errors.Is(err, gorm.ErrRecordNotFound) {
 		return exception.ErrRecordNotFound
 	}
 	return err
 }
+
+func (r userRepo) UpdateTOTP(user *model.User) error {
+	return r.db.Model(user).Select("totp_enabled", "totp_secret").Updates(user).Error
+}
+
+func (r userRepo) AcceptTOTPStep(userID int, step int64) (bool, error) {
+	// the condition makes concurrent uses of the code fail
+	result := r.db.Model(&model.User{}).Where("id = ? AND totp_last_step < ?", userID, step).Update("totp_last_step", step)
+	return result.RowsAffected != 0, result.Error
+}
+
+func (r userRepo) SetRoles(userID int, roles []model.Role) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&model.UserRole{}).Error; err != nil {
+			return err
+		}
+
+		userRoles := make([]model.UserRole, 0, len(roles))
+		for _, role := range roles {
+			userRoles = append(userRoles, model.UserRole{UserID: userID, Role: role})
+		}
+		if len(userRoles) == 0 {
+			return nil
+		}
+		return tx.Create(&userRoles).Error
+	})
+}
+
+func (r userRepo) CountWithRole(role model.Role) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.User{}).
+		Joins("JOIN user_roles ON user_roles.user_id = users.id").
+		Where("user_roles.role = ? AND users.disabled_at IS NULL", role).
+		Count(&count).Error
+	return count, err
+}
+
+func (r userRepo) Delete(userID int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&model.User{}, userID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return exception.ErrRecordNotFound
+		}
+
+		return tx.Exec("DELETE FROM user_favorites WHERE user_id = ?", userID).Error
+	})
+}
+
+func (r userRepo) FindDeleted() ([]model.User, error) {
+	var users []model.User
+	err := r.db.Unscoped().Where("deleted_at IS NOT NULL").Find(&users).Error
+	return users, err
+}
+
+func (r userRepo) Restore(userID int) error {
+	result := r.db.Unscoped().Model(&model.User{}).
+		Where("id = ? AND deleted_at IS NOT NULL", userID).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return exception.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r userRepo) Purge(deletedBefore time.Time) (int64, error) {
+	var count int64
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		purged := tx.Unscoped().Model(&model.User{}).
+			Select("id").
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", deletedBefore)
+
+		// the rows pointing at the purged users
+		for _, table := range []string{"user_favorites", "user_roles", "recovery_codes",
+			"sessions", "refresh_tokens", "mfa_challenges"} {
+			if err := tx.Exec("DELETE FROM "+table+" WHERE user_id IN (?)", purged).Error; err != nil {
+				return err
+			}
+		}
+
+		result := tx.Unscoped().
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", deletedBefore).
+			Delete(&model.User{})
+		count = result.RowsAffected
+		return result.Error
+	})
+
+	return count, err
+}
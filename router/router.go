@@ -7,55 +7,149 @@ import (
 	"github.com/denisyao1/welsh-academy-api/middleware"
 	"github.com/denisyao1/welsh-academy-api/model"
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
 )
 
 type Router struct {
 	ingredientController controller.IngredientController
 	recipeController     controller.RecipeController
 	userController       controller.UserController
-	SigningKey           string
+	trashController      controller.TrashController
+	catalogueController  controller.CatalogueController
+	cookbookController   controller.CookbookController
+	recommendController  controller.RecommendationController
+	substituteController controller.SubstitutionController
+	translateController  controller.TranslationController
+	keyController        controller.KeyController
+	inviteController     controller.InvitationController
+	Keys                 middleware.TokenVerifier
+	Revocations          middleware.TokenRevocations
+	Users                middleware.UserStatuses
 }
 
 func New(
 	ingredientController controller.IngredientController,
 	recipeController controller.RecipeController,
 	userController controller.UserController,
-	signingKey string,
-
+	trashController controller.TrashController,
+	catalogueController controller.CatalogueController,
+	cookbookController controller.CookbookController,
+	recommendController controller.RecommendationController,
+	substituteController controller.SubstitutionController,
+	translateController controller.TranslationController,
+	keyController controller.KeyController,
+	inviteController controller.InvitationController,
+	keys middleware.TokenVerifier,
+	revocations middleware.TokenRevocations,
+	users middleware.UserStatuses,
 ) *Router {
 	return &Router{
 		ingredientController: ingredientController,
 		recipeController:     recipeController,
 		userController:       userController,
-		SigningKey:           signingKey,
+		trashController:      trashController,
+		catalogueController:  catalogueController,
+		cookbookController:   cookbookController,
+		recommendController:  recommendController,
+		substituteController: substituteController,
+		translateController:  translateController,
+		keyController:        keyController,
+		inviteController:     inviteController,
+		Keys:                 keys,
+		Revocations:          revocations,
+		Users:                users,
 	}
 }
 
 func (r Router) InitRoutes(app *fiber.App) {
-	key := r.SigningKey
-	user := model.RoleUser
-	admin := model.RoleAdmin
-	jware := middleware.JwtWare
+	key := r.Keys
+	recipeCreate := model.PermRecipeCreate
+	recipePublish := model.PermRecipePublish
+	ingredientManage := model.PermIngredientManage
+	userManage := model.PermUserManage
+	jware := func(key middleware.TokenVerifier, permissions ...model.Permission) fiber.Handler {
+		return middleware.JwtWare(key, r.Revocations, r.Users, permissions...)
+	}
+
+	// correlates the logs of unexpected errors with the responses
+	app.Use(requestid.New())
+
+	// public keys verifying the access tokens
+	app.Get("/.well-known/jwks.json", r.keyController.GetJWKS)
 
-	api := app.Group("/api/v1")
+	api := app.Group("/api/v1", middleware.Locale())
 
 	// routes thant required no auth
 	api.Get("/health", controller.HealthCheck)
 	api.Post("/login", r.userController.Login)
-	api.Get("/logout", r.userController.Logout)
+	api.Post("/login/mfa", r.userController.LoginMFA)
+	api.Post("/token/refresh", r.userController.RefreshToken)
+	api.Post("/register", r.inviteController.Register)
+	api.Get("/logout", middleware.OptionalJwtWare(key, r.Revocations, r.Users), r.userController.Logout)
 
 	// required user auth routes
-	api.Get("/ingredients", jware(key, user), r.ingredientController.ListIngredients)
-	api.Get("/recipes", jware(key, user), r.recipeController.ListRecipes)
-	api.Post("/recipes/:id/flag-unflag", jware(key, user), r.recipeController.FlagOrUnflag)
-	api.Get("/recipes/favorites", jware(key, user), r.recipeController.ListUserFavorites)
-	api.Get("/users/my-infos", jware(key, user), r.userController.GetInfos)
-	api.Patch("/users/password-change", jware(key, user), r.userController.UpdatePassword)
+	api.Get("/ingredients", jware(key), r.ingredientController.ListIngredients)
+	api.Get("/ingredients/:id/substitutes", jware(key), r.substituteController.ListSubstitutes)
+	api.Get("/recipes", jware(key), r.recipeController.ListRecipes)
+	api.Post("/recipes/:id/flag-unflag", jware(key), r.recipeController.FlagOrUnflag)
+	api.Get("/recipes/favorites", jware(key), r.recipeController.ListUserFavorites)
+	api.Get("/recipes/favorites/export", jware(key), r.cookbookController.ExportFavorites)
+	api.Get("/recipes/recommended", jware(key), r.recommendController.ListRecommendations)
+	api.Post("/cookbooks", jware(key), r.cookbookController.CreateCookbook)
+	api.Get("/recipes/:id", jware(key), r.recipeController.GetRecipe)
+	api.Get("/recipes/:id/similar", jware(key), r.recipeController.ListSimilarRecipes)
+	api.Get("/users/my-infos", jware(key), r.userController.GetInfos)
+	api.Get("/users/me/sessions", jware(key), r.userController.ListSessions)
+	api.Delete("/users/me/sessions/:id", jware(key), r.userController.RevokeSession)
+	api.Delete("/users/me/mfa/totp", jware(key), r.userController.DisableTOTP)
+
+	// routes letting users set up their account before using it
+	setupWare := func(key middleware.TokenVerifier, allowed ...middleware.Restriction) fiber.Handler {
+		return middleware.SetupJwtWare(key, r.Revocations, r.Users, allowed...)
+	}
+	pwdChange, mfaSetup := middleware.RestrictPasswordChange, middleware.RestrictMFASetup
+	api.Patch("/users/password-change", setupWare(key, pwdChange, mfaSetup), r.userController.UpdatePassword)
+	api.Post("/users/me/mfa/totp", setupWare(key, mfaSetup), r.userController.EnrolTOTP)
+	api.Post("/users/me/mfa/totp/verify", setupWare(key, mfaSetup), r.userController.EnableTOTP)
+
+	// routes requiring permissions
+	api.Post("/users", jware(key, userManage), r.userController.Create)
+	api.Get("/users", jware(key, userManage), r.userController.ListUsers)
+	api.Get("/users/:id", jware(key, userManage), r.userController.GetUser)
+	api.Patch("/users/:id", jware(key, userManage), r.userController.UpdateUser)
+	api.Post("/users/:id/disable", jware(key, userManage), r.userController.DisableUser)
+	api.Post("/users/:id/enable", jware(key, userManage), r.userController.EnableUser)
+	api.Post("/users/:id/unlock", jware(key, userManage), r.userController.UnlockUser)
+	api.Post("/users/:id/reset-password", jware(key, userManage), r.userController.ResetPassword)
+	api.Get("/roles", jware(key, userManage), r.userController.ListRoles)
+	api.Put("/users/:id/roles", jware(key, userManage), r.userController.SetRoles)
+	api.Delete("/users/:id", jware(key, userManage), r.userController.Delete)
+	api.Delete("/users/:id/sessions", jware(key, userManage), r.userController.RevokeUserSessions)
+	api.Post("/invitations", jware(key, userManage), r.inviteController.CreateInvitation)
+	api.Get("/invitations", jware(key, userManage), r.inviteController.ListInvitations)
+	api.Delete("/invitations/:id", jware(key, userManage), r.inviteController.RevokeInvitation)
+	api.Post("/ingredients", jware(key, ingredientManage), r.ingredientController.CreateIngredient)
+	api.Delete("/ingredients/:id", jware(key, ingredientManage), r.ingredientController.DeleteIngredient)
+	api.Post("/substitutions", jware(key, ingredientManage), r.substituteController.CreateSubstitution)
+	api.Delete("/substitutions/:id", jware(key, ingredientManage), r.substituteController.DeleteSubstitution)
+	api.Get("/ingredients/:id/translations", jware(key, ingredientManage), r.translateController.ListIngredientTranslations)
+	api.Put("/ingredients/:id/translations/:locale", jware(key, ingredientManage), r.translateController.TranslateIngredient)
+	api.Delete("/ingredients/:id/translations/:locale", jware(key, ingredientManage), r.translateController.DeleteIngredientTranslation)
+	api.Post("/recipes", jware(key, recipeCreate), r.recipeController.CreateRecipe)
+	api.Post("/recipes/import", jware(key, recipeCreate), r.recipeController.ImportRecipes)
+	api.Delete("/recipes/:id", jware(key, recipePublish), r.recipeController.DeleteRecipe)
+	api.Get("/recipes/:id/translations", jware(key, recipePublish), r.translateController.ListRecipeTranslations)
+	api.Put("/recipes/:id/translations/:locale", jware(key, recipePublish), r.translateController.TranslateRecipe)
+	api.Delete("/recipes/:id/translations/:locale", jware(key, recipePublish), r.translateController.DeleteRecipeTranslation)
+	api.Get("/admin/recipes/duplicates", jware(key, recipePublish), r.recipeController.ListDuplicates)
+	api.Post("/admin/import", jware(key, recipeCreate, ingredientManage), r.catalogueController.Import)
+	api.Get("/admin/export", jware(key, recipeCreate, ingredientManage), r.catalogueController.Export)
 
-	// required admin auth routes
-	api.Post("/users", jware(key, admin), r.userController.Create)
-	api.Post("/ingredients", jware(key, admin), r.ingredientController.CreateIngredient)
-	api.Post("/recipes", jware(key, admin), r.recipeController.CreateRecipe)
+	// the trash holds users, ingredients and recipes
+	trash := []model.Permission{userManage, ingredientManage, recipePublish}
+	api.Get("/admin/trash", jware(key, trash...), r.trashController.ListTrash)
+	api.Post("/admin/trash/:type/:id/restore", jware(key, trash...), r.trashController.Restore)
+	api.Delete("/admin/trash", jware(key, trash...), r.trashController.Purge)
 
 }
 
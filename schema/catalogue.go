@@ -0,0 +1,52 @@
+package schema
+
+// Formats of catalogue imports and exports.
+const (
+	CatalogueCSV    = "csv"
+	CatalogueNDJSON = "ndjson"
+
+	MIMETextCSV           = "text/csv"
+	MIMEApplicationNDJSON = "application/x-ndjson"
+)
+
+// Types of catalogue rows.
+const (
+	CatalogueIngredient = "ingredient"
+	CatalogueRecipe     = "recipe"
+)
+
+// CatalogueHeader is the header of catalogue CSV files. Files without the
+// tags column, exported before recipes had tags, can still be imported.
+var CatalogueHeader = []string{"type", "name", "making", "ingredients", "yield", "prepTime", "cookTime", "allergens", "tags"}
+
+// CatalogueRow models an ingredient or a recipe of an imported or exported catalogue.
+//
+// In CSV files, recipe ingredient names, ingredient allergens and recipe tags are separated by a |.
+type CatalogueRow struct {
+	Type        string   `json:"type" enums:"ingredient,recipe" extensions:"x-order=1"`
+	Name        string   `json:"name" extensions:"x-order=2"`
+	Making      string   `json:"making,omitempty" extensions:"x-order=3"`
+	Ingredients []string `json:"ingredients,omitempty" extensions:"x-order=4"`
+	Yield       string   `json:"yield,omitempty" extensions:"x-order=5"`
+	PrepTime    int      `json:"prepTime,omitempty" extensions:"x-order=6"` // in minutes
+	CookTime    int      `json:"cookTime,omitempty" extensions:"x-order=7"` // in minutes
+	Allergens   []string `json:"allergens,omitempty" extensions:"x-order=8"`
+	Tags        []string `json:"tags,omitempty" extensions:"x-order=9"`
+}
+
+// ImportRowError lists the reasons a row of an imported catalogue is invalid.
+type ImportRowError struct {
+	Line   int     `json:"line" extensions:"x-order=1"`
+	Type   string  `json:"type" extensions:"x-order=2"`
+	Name   string  `json:"name" extensions:"x-order=3"`
+	Errors []error `json:"errors" swaggertype:"array,object" extensions:"x-order=4"`
+}
+
+// ImportResponse reports the result of a catalogue import.
+type ImportResponse struct {
+	DryRun      bool             `json:"dryRun" extensions:"x-order=1"`
+	Applied     bool             `json:"applied" extensions:"x-order=2"`
+	Ingredients int              `json:"ingredients" extensions:"x-order=3"` // number of valid ingredients
+	Recipes     int              `json:"recipes" extensions:"x-order=4"`     // number of valid recipes
+	Errors      []ImportRowError `json:"errors,omitempty" extensions:"x-order=5"`
+}
@@ -0,0 +1,41 @@
+package schema
+
+import "github.com/denisyao1/welsh-academy-api/model"
+
+// MIMEApplicationLDJSON is the media type of JSON-LD documents.
+const MIMEApplicationLDJSON = "application/ld+json"
+
+// HowToStep models a schema.org HowToStep.
+type HowToStep struct {
+	Type string `json:"@type" example:"HowToStep"`
+	Text string `json:"text" example:"Melt the butter."`
+}
+
+// RecipeJSONLD models a schema.org Recipe in JSON-LD.
+type RecipeJSONLD struct {
+	Context            string      `json:"@context" example:"https://schema.org" extensions:"x-order=1"`
+	Type               string      `json:"@type" example:"Recipe" extensions:"x-order=2"`
+	Identifier         int         `json:"identifier,omitempty" example:"1" extensions:"x-order=3"`
+	Name               string      `json:"name" extensions:"x-order=4"`
+	RecipeYield        string      `json:"recipeYield,omitempty" example:"4 servings" extensions:"x-order=5"`
+	PrepTime           string      `json:"prepTime,omitempty" example:"PT15M" extensions:"x-order=6"`
+	CookTime           string      `json:"cookTime,omitempty" example:"PT10M" extensions:"x-order=7"`
+	TotalTime          string      `json:"totalTime,omitempty" example:"PT25M" extensions:"x-order=8"`
+	RecipeIngredient   []string    `json:"recipeIngredient" extensions:"x-order=9"`
+	RecipeInstructions []HowToStep `json:"recipeInstructions" extensions:"x-order=10"`
+	InLanguage         string      `json:"inLanguage,omitempty" example:"en" extensions:"x-order=11"`
+}
+
+// RecipeImportError lists the reasons a recipe of an imported document was rejected.
+type RecipeImportError struct {
+	Index  int     `json:"index" extensions:"x-order=1"` // position of the recipe in the document
+	Name   string  `json:"name" extensions:"x-order=2"`
+	Errors []error `json:"errors" swaggertype:"array,object" extensions:"x-order=3"`
+}
+
+// RecipeImportResponse reports the result of a recipe import.
+type RecipeImportResponse struct {
+	Count   int                 `json:"count" extensions:"x-order=1"`
+	Recipes []model.Recipe      `json:"recipes" extensions:"x-order=2"`
+	Errors  []RecipeImportError `json:"errors,omitempty" extensions:"x-order=3"`
+}
@@ -1,40 +1,220 @@
 package schema
 
-import "github.com/denisyao1/welsh-academy-api/model"
+import (
+	"time"
+
+	"github.com/denisyao1/welsh-academy-api/exception"
+	"github.com/denisyao1/welsh-academy-api/model"
+)
 
 // IngredientQuery represents ingredients query params
 type IngredientQuery struct {
 	Ingredients []string `query:"ingredients"`
+	Substitutes bool     `query:"substitutes"` // also match ingredients the listed ones can replace
 }
 
 // User models inputs admin user has to provide to create new user.
 type User struct {
-	Username string `json:"username" extensions:"x-order=1"`
-	Password string `json:"password" extensions:"x-order=2"`
-	IsAdmin  bool   `json:"admin" extensions:"x-order=3"`
+	Username string       `json:"username" validate:"required,min=3" extensions:"x-order=1"`
+	Password string       `json:"password" validate:"required" extensions:"x-order=2"`
+	Roles    []model.Role `json:"roles" validate:"unique,oneof=admin editor contributor reader" extensions:"x-order=3"` // reader by default
+}
+
+// UserUpdate models inputs admin user has to provide to update a user.
+// Missing fields are left unchanged.
+type UserUpdate struct {
+	Roles []model.Role `json:"roles" validate:"unique,oneof=admin editor contributor reader"`
+}
+
+// UserQuery represents users query params.
+type UserQuery struct {
+	Search string `query:"search"` // part of the username
+	Page   int    `query:"page" validate:"min=1"`
+	Limit  int    `query:"limit" validate:"min=1,max=50"`
+}
+
+type UsersResponse struct {
+	Count int          `json:"count" extensions:"x-order=1"` // users of the page
+	Total int64        `json:"total" extensions:"x-order=2"` // matching users
+	Page  int          `json:"page" extensions:"x-order=3"`
+	Limit int          `json:"limit" extensions:"x-order=4"`
+	Users []model.User `json:"users" extensions:"x-order=5"`
+}
+
+// TemporaryPassword is a password generated for a user, shown only once.
+type TemporaryPassword struct {
+	Password string `json:"password"`
+}
+
+// Invitation models inputs admin has to provide to invite people to register with POST /register.
+type Invitation struct {
+	Role      model.Role `json:"role" example:"reader" validate:"omitempty,oneof=admin editor contributor reader" extensions:"x-order=1"` // reader by default
+	MaxUses   int        `json:"maxUses" example:"1" validate:"min=0,max=1000" extensions:"x-order=2"`                                    // 1 by default
+	ValidDays int        `json:"validDays" example:"7" validate:"min=0,max=365" extensions:"x-order=3"`                                   // 7 by default
+}
+
+type InvitationsResponse struct {
+	Count       int                `json:"count"`
+	Invitations []model.Invitation `json:"invitations"`
+}
+
+// Registration models inputs people have to provide to create their account with an invitation code.
+type Registration struct {
+	InvitationCode string `json:"invitationCode" validate:"required" extensions:"x-order=1"`
+	Username       string `json:"username" validate:"required,min=3" extensions:"x-order=2"`
+	Password       string `json:"password" validate:"required" extensions:"x-order=3"`
+}
+
+// UserRoles models inputs admin user has to provide to assign roles to a user.
+type UserRoles struct {
+	Roles []model.Role `json:"roles" validate:"min=1,unique,oneof=admin editor contributor reader"`
+}
+
+// Role is a role which can be assigned to users, with its permissions.
+type Role struct {
+	Name        model.Role         `json:"name" example:"editor" extensions:"x-order=1"`
+	Permissions []model.Permission `json:"permissions" extensions:"x-order=2"`
+}
+
+type RolesResponse struct {
+	Count int    `json:"count"`
+	Roles []Role `json:"roles"`
 }
 
 // Login models inputs user has to provide to log in.
 type Login struct {
-	Username string `json:"username" example:"username" minLength:"3" extensions:"x-order=1"`
-	Password string `json:"password" example:"password" minLength:"4" extensions:"x-order=2"`
+	Username string `json:"username" example:"username" validate:"required" extensions:"x-order=1"`
+	Password string `json:"password" example:"password" validate:"required" extensions:"x-order=2"`
+}
+
+// Modes of login, giving the access token in a cookie or in the response body.
+const (
+	LoginCookie = "cookie"
+	LoginToken  = "token"
+)
+
+// Token is an access token to send in the Authorization header with the Bearer scheme,
+// and the refresh token to get new ones from POST /token/refresh.
+type Token struct {
+	AccessToken      string    `json:"accessToken" extensions:"x-order=1"`
+	TokenType        string    `json:"tokenType" example:"Bearer" extensions:"x-order=2"`
+	ExpiresAt        time.Time `json:"expiresAt" extensions:"x-order=3"`
+	ExpiresIn        int       `json:"expiresIn" example:"900" extensions:"x-order=4"` // in seconds
+	RefreshToken     string    `json:"refreshToken" extensions:"x-order=5"`            // can be used once
+	RefreshExpiresAt time.Time `json:"refreshExpiresAt" extensions:"x-order=6"`
+
+	// the access token only lets the user change its password,
+	// refresh it once changed
+	MustChangePassword bool `json:"mustChangePassword,omitempty" extensions:"x-order=7"`
+
+	// the access token only lets the user enable two-factor authentication,
+	// refresh it once enabled
+	MFASetupRequired bool `json:"mfaSetupRequired,omitempty" extensions:"x-order=8"`
+}
+
+// MFAChallenge is a login whose password was checked, to complete with a code
+// of the authenticator app at POST /login/mfa.
+type MFAChallenge struct {
+	ChallengeToken string    `json:"challengeToken" extensions:"x-order=1"`
+	ExpiresAt      time.Time `json:"expiresAt" extensions:"x-order=2"`
+	ExpiresIn      int       `json:"expiresIn" example:"300" extensions:"x-order=3"` // in seconds
+}
+
+// MFALogin models inputs user has to provide to complete a login with a code
+// of its authenticator app, or else one of its recovery codes.
+type MFALogin struct {
+	ChallengeToken string `json:"challengeToken" validate:"required" extensions:"x-order=1"`
+	Code           string `json:"code" example:"123456" validate:"required" extensions:"x-order=2"`
+}
+
+// PasswordConfirmation models inputs user has to provide to enrol in two-factor authentication.
+type PasswordConfirmation struct {
+	CurrentPassword string `json:"currentPassword" validate:"required"`
+}
+
+// TOTPCode models inputs user has to provide to enable or disable two-factor authentication.
+type TOTPCode struct {
+	Code string `json:"code" example:"123456" validate:"required"`
+}
+
+// TOTPEnrolment is the secret to add to an authenticator app, as an otpauth URI
+// and as a QR code of it.
+type TOTPEnrolment struct {
+	Secret string `json:"secret" example:"JBSWY3DPEHPK3PXP" extensions:"x-order=1"` // base32
+	URI    string `json:"uri" example:"otpauth://totp/Welsh%20Academy:username?issuer=Welsh+Academy&secret=JBSWY3DPEHPK3PXP" extensions:"x-order=2"`
+	QRCode string `json:"qrCode" example:"data:image/png;base64,iVBORw0KGgo=" extensions:"x-order=3"` // PNG data URI
+}
+
+// RecoveryCodes are shown once, each letting the user log in once without its authenticator app.
+type RecoveryCodes struct {
+	Codes []string `json:"codes" example:"3f9a0-c5d1b"`
+}
+
+// Session is a login of the connected user, from a device.
+type Session struct {
+	ID         string    `json:"id" example:"3f9a0c5d1b7e4a2f8c6d0e1f2a3b4c5d" extensions:"x-order=1"`
+	UserAgent  string    `json:"userAgent" example:"Mozilla/5.0" extensions:"x-order=2"`
+	IP         string    `json:"ip" example:"192.0.2.1" extensions:"x-order=3"`
+	CreatedAt  time.Time `json:"createdAt" extensions:"x-order=4"`
+	LastUsedAt time.Time `json:"lastUsedAt" extensions:"x-order=5"` // last login or refresh
+	Current    bool      `json:"current" extensions:"x-order=6"`    // session of the request
+}
+
+type SessionsResponse struct {
+	Count    int       `json:"count"`
+	Sessions []Session `json:"sessions"`
+}
+
+// JWK is a public key verifying access tokens (RFC 7517),
+// with n and e for RSA keys or crv and x for Ed25519 ones.
+type JWK struct {
+	Kty string `json:"kty" example:"OKP" extensions:"x-order=1"`
+	Use string `json:"use" example:"sig" extensions:"x-order=2"`
+	Alg string `json:"alg" example:"EdDSA" extensions:"x-order=3"`
+	Kid string `json:"kid" extensions:"x-order=4"` // kid header of the tokens it signs
+	Crv string `json:"crv,omitempty" example:"Ed25519" extensions:"x-order=5"`
+	X   string `json:"x,omitempty" extensions:"x-order=6"`
+	N   string `json:"n,omitempty" extensions:"x-order=7"`
+	E   string `json:"e,omitempty" extensions:"x-order=8"`
+}
+
+// JWKS is the set of keys verifying access tokens.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// RefreshToken models inputs user has to provide to refresh its tokens,
+// unless the refresh token is in the Refresh cookie.
+type RefreshToken struct {
+	RefreshToken string `json:"refreshToken"`
 }
 
 // Ingredient models inputs user has to provide to create an ingredient.
 type Ingredient struct {
-	Name string `json:"name"`
+	Name      string   `json:"name" example:"Tomato" validate:"required" extensions:"x-order=1"`
+	Allergens []string `json:"allergens" validate:"unique,oneof=celery gluten crustaceans eggs fish lupin milk molluscs mustard nuts peanuts sesame soya sulphites" extensions:"x-order=2"`
 }
 
 // Password models inputs user has to provide to update its password.
 type Password struct {
-	Password string `json:"password" minLength:"4"`
+	CurrentPassword string `json:"currentPassword" validate:"required" extensions:"x-order=1"`
+	Password        string `json:"password" validate:"required" extensions:"x-order=2"` // the new password
 }
 
 // Recipe models inputs user has to provide to create recipe
 type Recipe struct {
-	Name        string       `json:"name" extensions:"x-order=1"`
-	Making      string       `json:"making" extensions:"x-order=2"`
-	Ingredients []Ingredient `json:"ingredients" minLength:"1" extensions:"x-order=3"`
+	Name        string       `json:"name" validate:"required" extensions:"x-order=1"`
+	Making      string       `json:"making" validate:"required" extensions:"x-order=2"`
+	Ingredients []Ingredient `json:"ingredients" validate:"min=1,unique=Name,dive" extensions:"x-order=3"`
+	Yield       string       `json:"yield" example:"4 servings" extensions:"x-order=4"`
+	PrepTime    int          `json:"prepTime" example:"15" validate:"min=0" extensions:"x-order=5"` // in minutes
+	CookTime    int          `json:"cookTime" example:"10" validate:"min=0" extensions:"x-order=6"` // in minutes
+	Tags        []Tag        `json:"tags" validate:"unique=Name,dive" extensions:"x-order=7"`
+}
+
+// Tag models a recipe tag. Tags are created when they don't exist.
+type Tag struct {
+	Name string `json:"name" example:"soup" validate:"required"`
 }
 
 type IngredientsResponse struct {
@@ -43,6 +223,156 @@ type IngredientsResponse struct {
 }
 
 type RecipesResponse struct {
+	Count         int                  `json:"count"`
+	Recipes       []model.Recipe       `json:"recipes"`
+	Substitutions []model.Substitution `json:"substitutions,omitempty"` // only with the substitutes query param
+}
+
+// Types of items the trash can hold.
+const (
+	TrashIngredient = "ingredient"
+	TrashRecipe     = "recipe"
+	TrashUser       = "user"
+)
+
+// TrashItem describes an item moved to the trash.
+type TrashItem struct {
+	Type      string    `json:"type" enums:"ingredient,recipe,user" extensions:"x-order=1"`
+	ID        int       `json:"id" extensions:"x-order=2"`
+	Name      string    `json:"name" extensions:"x-order=3"`
+	DeletedAt time.Time `json:"deletedAt" extensions:"x-order=4"`
+}
+
+type TrashResponse struct {
+	Count int         `json:"count"`
+	Items []TrashItem `json:"items"`
+}
+
+// PurgeResponse gives the number of items permanently removed from the trash.
+type PurgeResponse struct {
+	Ingredients int64 `json:"ingredients"`
+	Recipes     int64 `json:"recipes"`
+	Users       int64 `json:"users"`
+}
+
+// Formats of cookbooks.
+const (
+	CookbookMarkdown = "markdown"
+	CookbookHTML     = "html"
+	CookbookPDF      = "pdf"
+)
+
+// CookbookMIMETypes maps cookbook formats to their media type.
+var CookbookMIMETypes = map[string]string{
+	CookbookMarkdown: "text/markdown; charset=utf-8",
+	CookbookHTML:     "text/html; charset=utf-8",
+	CookbookPDF:      "application/pdf",
+}
+
+// Cookbook models inputs user has to provide to export recipes as a cookbook.
+type Cookbook struct {
+	Title     string `json:"title" example:"Cheddar classics" extensions:"x-order=1"`
+	RecipeIDs []int  `json:"recipeIds" validate:"min=1,unique" extensions:"x-order=2"`
+}
+
+// Recommendation is a recipe suggested to the connected user.
+type Recommendation struct {
+	Recipe  model.Recipe `json:"recipe" extensions:"x-order=1"`
+	Score   float64      `json:"score" example:"0.42" extensions:"x-order=2"`
+	Because []string     `json:"because,omitempty" extensions:"x-order=3"` // favorite recipes the suggestion is based on
+}
+
+type RecommendationsResponse struct {
+	Count           int              `json:"count"`
+	Recommendations []Recommendation `json:"recommendations"`
+}
+
+// SimilarRecipe is a recipe resembling the requested one.
+type SimilarRecipe struct {
+	Recipe             model.Recipe `json:"recipe" extensions:"x-order=1"`
+	Score              float64      `json:"score" example:"0.42" extensions:"x-order=2"`
+	SharedIngredients  []string     `json:"sharedIngredients" extensions:"x-order=3"`
+	MissingIngredients []string     `json:"missingIngredients" extensions:"x-order=4"` // only in the requested recipe
+	ExtraIngredients   []string     `json:"extraIngredients" extensions:"x-order=5"`   // only in the similar recipe
+	SharedTags         []string     `json:"sharedTags" extensions:"x-order=6"`
+}
+
+type SimilarRecipesResponse struct {
+	Count   int             `json:"count"`
+	Recipes []SimilarRecipe `json:"recipes"`
+}
+
+// Substitution models inputs admin user has to provide to create a substitution.
+type Substitution struct {
+	Ingredient  string       `json:"ingredient" example:"buttermilk" validate:"required" extensions:"x-order=1"`
+	Substitutes []Substitute `json:"substitutes" validate:"min=1,unique=Name,dive" extensions:"x-order=2"`
+	Notes       string       `json:"notes" example:"Let the mix rest for 10 minutes." extensions:"x-order=3"`
+}
+
+// Substitute models an ingredient of a substitution.
+type Substitute struct {
+	Name  string  `json:"name" example:"milk" validate:"required" extensions:"x-order=1"`
+	Ratio float64 `json:"ratio" example:"1" default:"1" validate:"min=0" extensions:"x-order=2"` // quantity per unit of the replaced ingredient
+}
+
+type SubstitutionsResponse struct {
+	Count         int                  `json:"count"`
+	Substitutions []model.Substitution `json:"substitutions"`
+}
+
+// DuplicateCandidate is an existing recipe likely to be a duplicate of another one.
+type DuplicateCandidate struct {
+	Recipe               model.Recipe `json:"recipe" extensions:"x-order=1"`
+	NameSimilarity       float64      `json:"nameSimilarity" example:"1" extensions:"x-order=2"`
+	IngredientSimilarity float64      `json:"ingredientSimilarity" example:"0.8" extensions:"x-order=3"`
+}
+
+// DuplicateResponse is returned when creating a recipe likely to be a duplicate.
+type DuplicateResponse struct {
+	Problem
+	Candidates []DuplicateCandidate `json:"candidates" extensions:"x-order=9"`
+}
+
+// DuplicateCluster is a group of recipes likely to be duplicates.
+type DuplicateCluster struct {
 	Count   int            `json:"count"`
 	Recipes []model.Recipe `json:"recipes"`
 }
+
+type DuplicateClustersResponse struct {
+	Count    int                `json:"count"`
+	Clusters []DuplicateCluster `json:"clusters"`
+}
+
+// IngredientTranslation models inputs admin user has to provide to translate an ingredient.
+type IngredientTranslation struct {
+	Name string `json:"name" example:"Caws" validate:"required"`
+}
+
+// RecipeTranslation models inputs admin user has to provide to translate a recipe.
+type RecipeTranslation struct {
+	Name   string `json:"name" example:"Caws Pobi" validate:"required" extensions:"x-order=1"`
+	Making string `json:"making" validate:"required" extensions:"x-order=2"`
+}
+
+type IngredientTranslationsResponse struct {
+	Count        int                           `json:"count"`
+	Translations []model.IngredientTranslation `json:"translations"`
+}
+
+type RecipeTranslationsResponse struct {
+	Count        int                       `json:"count"`
+	Translations []model.RecipeTranslation `json:"translations"`
+}
+
+// Problem describes an error as RFC 7807 problem details.
+type Problem struct {
+	Type          string                    `json:"type" example:"urn:welsh-academy:problem:recipe_not_found" extensions:"x-order=1"`
+	Title         string                    `json:"title" example:"Not Found" extensions:"x-order=2"`
+	Status        int                       `json:"status" example:"404" extensions:"x-order=3"`
+	Detail        string                    `json:"detail" example:"Recipe not found." extensions:"x-order=4"` // in the user language
+	Instance      string                    `json:"instance" example:"/api/v1/recipes/42" extensions:"x-order=5"`
+	Code          exception.Code            `json:"code" swaggertype:"string" example:"recipe_not_found" extensions:"x-order=6"`
+	Errors        []exception.ErrValidation `json:"errors,omitempty" extensions:"x-order=7"`        // invalid fields of the request
+	CorrelationID string                    `json:"correlationId,omitempty" extensions:"x-order=8"` // only for unexpected errors
+}
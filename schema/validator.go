@@ -0,0 +1,203 @@
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/denisyao1/welsh-academy-api/exception"
+	"github.com/denisyao1/welsh-academy-api/util"
+)
+
+// Validate checks the fields of a struct against the rules of their validate
+// tag and returns all the validation errors at once, named after the json tags.
+//
+// Rules are separated by commas:
+//   - required: the value must not be empty, strings being trimmed.
+//   - omitempty: the next rules don't apply to empty values.
+//   - min=n, max=n: bounds of the length of strings and slices, or of numbers.
+//   - oneof=a b c: the value, or each slice item, must be one of the listed ones.
+//   - unique, unique=Field: slice items, or their Field, must not be repeated.
+//     Strings are compared like names, ignoring case and spacing.
+//   - dive: the following rules apply to each slice item. Struct items are
+//     validated with their own tags.
+//
+// The same tags set the constraints of the OpenAPI documentation.
+func Validate(input any) []error {
+	value := reflect.Indirect(reflect.ValueOf(input))
+	return validateStruct(value, "")
+}
+
+func validateStruct(value reflect.Value, prefix string) []error {
+	var errs []error
+
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		tag, ok := field.Tag.Lookup("validate")
+		if !ok {
+			continue
+		}
+		errs = append(errs, validateValue(value.Field(i), prefix+fieldName(field), strings.Split(tag, ","))...)
+	}
+
+	return errs
+}
+
+// validateValue applies the rules to a value, stopping at the first broken one
+// except for dive.
+func validateValue(value reflect.Value, field string, rules []string) []error {
+	var errs []error
+
+	for i, rule := range rules {
+		name, param, _ := strings.Cut(rule, "=")
+		if name == "dive" {
+			for j := 0; j < value.Len(); j++ {
+				errs = append(errs, validateValue(value.Index(j), fmt.Sprintf("%s[%d]", field, j), rules[i+1:])...)
+			}
+			return errs
+		}
+
+		if len(errs) != 0 {
+			continue
+		}
+		if name == "omitempty" {
+			if isBlank(value) {
+				return errs
+			}
+			continue
+		}
+		if err := checkRule(value, field, name, param); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) == 0 && value.Kind() == reflect.Struct {
+		errs = validateStruct(value, field+".")
+	}
+
+	return errs
+}
+
+// checkRule returns the error of a value breaking a rule, or nil.
+func checkRule(value reflect.Value, field string, rule string, param string) error {
+	var newErr = exception.NewErrValidation
+
+	switch rule {
+	case "required":
+		if isBlank(value) {
+			return newErr(field, exception.CodeRequired)
+		}
+
+	case "min", "max":
+		isMin := rule == "min"
+		switch value.Kind() {
+		case reflect.String, reflect.Slice:
+			bound := intParam(field, rule, param)
+			length := value.Len()
+			if value.Kind() == reflect.String {
+				length = utf8.RuneCountInString(strings.TrimSpace(value.String()))
+			}
+			switch {
+			case isMin && length < bound && value.Kind() == reflect.String:
+				return newErr(field, exception.CodeTooShort, bound)
+			case isMin && length < bound && bound == 1:
+				return newErr(field, exception.CodeEmptyList)
+			case isMin && length < bound:
+				return newErr(field, exception.CodeTooFewItems, bound)
+			case !isMin && length > bound && value.Kind() == reflect.String:
+				return newErr(field, exception.CodeTooLong, bound)
+			case !isMin && length > bound:
+				return newErr(field, exception.CodeTooManyItems, bound)
+			}
+		default:
+			bound, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				panic(fmt.Sprintf("schema: invalid rule %s=%s of %s", rule, param, field))
+			}
+			number := toFloat(value)
+			switch {
+			case isMin && number < bound:
+				return newErr(field, exception.CodeTooSmall, bound)
+			case !isMin && number > bound:
+				return newErr(field, exception.CodeTooLarge, bound)
+			}
+		}
+
+	case "oneof":
+		allowed := strings.Fields(param)
+		if value.Kind() != reflect.Slice {
+			if !util.Contains(fmt.Sprint(value.Interface()), allowed) {
+				return newErr(field, exception.CodeNotOneOf, strings.Join(allowed, ", "))
+			}
+			break
+		}
+		for i := 0; i < value.Len(); i++ {
+			if !util.Contains(fmt.Sprint(value.Index(i).Interface()), allowed) {
+				return newErr(fmt.Sprintf("%s[%d]", field, i), exception.CodeNotOneOf, strings.Join(allowed, ", "))
+			}
+		}
+
+	case "unique":
+		seen := make(map[any]bool)
+		for i := 0; i < value.Len(); i++ {
+			item := value.Index(i)
+			if param != "" {
+				item = item.FieldByName(param)
+			}
+			key := item.Interface()
+			if item.Kind() == reflect.String {
+				key = util.NameKey(item.String())
+				// blank items are reported by their own rules
+				if key == "" {
+					continue
+				}
+			}
+			if seen[key] {
+				return newErr(field, exception.CodeDuplicateItems)
+			}
+			seen[key] = true
+		}
+
+	default:
+		panic(fmt.Sprintf("schema: unknown rule %s of %s", rule, field))
+	}
+
+	return nil
+}
+
+func isBlank(value reflect.Value) bool {
+	if value.Kind() == reflect.String {
+		return strings.TrimSpace(value.String()) == ""
+	}
+	return value.IsZero()
+}
+
+func toFloat(value reflect.Value) float64 {
+	switch {
+	case value.CanInt():
+		return float64(value.Int())
+	case value.CanUint():
+		return float64(value.Uint())
+	default:
+		return value.Float()
+	}
+}
+
+func intParam(field string, rule string, param string) int {
+	bound, err := strconv.Atoi(param)
+	if err != nil {
+		panic(fmt.Sprintf("schema: invalid rule %s=%s of %s", rule, param, field))
+	}
+	return bound
+}
+
+// fieldName returns the name of a field in json documents.
+func fieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}
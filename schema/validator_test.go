@@ -0,0 +1,104 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/denisyao1/welsh-academy-api/exception"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	assert := assert.New(t)
+
+	type fieldError struct {
+		field string
+		code  exception.Code
+	}
+
+	testCases := []struct {
+		input       any
+		errors      []fieldError
+		description string
+	}{
+		{
+			input:       User{Username: "user", Password: "pass"},
+			description: "valid user, should have no error",
+		},
+		{
+			input:       User{Username: " ", Password: " "},
+			errors:      []fieldError{{"username", exception.CodeRequired}, {"password", exception.CodeRequired}},
+			description: "blank username and password, should report both",
+		},
+		{
+			input:       &Password{CurrentPassword: "pass", Password: "word"},
+			description: "pointer to valid input, should have no error",
+		},
+		{
+			input:       Ingredient{Name: "Tomato", Allergens: []string{"celery", "wood"}},
+			errors:      []fieldError{{"allergens[1]", exception.CodeNotOneOf}},
+			description: "unknown allergen, should report the item",
+		},
+		{
+			input:       Ingredient{Name: "Tomato", Allergens: []string{"milk", "milk"}},
+			errors:      []fieldError{{"allergens", exception.CodeDuplicateItems}},
+			description: "repeated allergen, should report duplicates",
+		},
+		{
+			input: Recipe{
+				Name:        "Welsh rarebit",
+				Making:      "Toast the bread.",
+				Ingredients: []Ingredient{{Name: "Cheddar"}, {Name: "Bread"}},
+				Tags:        []Tag{{Name: "snack"}},
+			},
+			description: "valid recipe, should have no error",
+		},
+		{
+			input:       Recipe{PrepTime: -5},
+			errors:      []fieldError{{"name", exception.CodeRequired}, {"making", exception.CodeRequired}, {"ingredients", exception.CodeEmptyList}, {"prepTime", exception.CodeTooSmall}},
+			description: "empty recipe, should report all fields",
+		},
+		{
+			input: Recipe{
+				Name:        "Cawl",
+				Making:      "Simmer.",
+				Ingredients: []Ingredient{{Name: "Lamb"}, {Name: " lamb "}},
+				Tags:        []Tag{{Name: "soup"}, {Name: ""}},
+			},
+			errors:      []fieldError{{"ingredients", exception.CodeDuplicateItems}, {"tags[1].name", exception.CodeRequired}},
+			description: "duplicate ingredients and blank tag, should compare names and dive into items",
+		},
+		{
+			input:       Cookbook{RecipeIDs: []int{1, 2, 1}},
+			errors:      []fieldError{{"recipeIds", exception.CodeDuplicateItems}},
+			description: "repeated recipe ids, should report duplicates",
+		},
+		{
+			input:       Substitution{Ingredient: "buttermilk", Substitutes: []Substitute{{Name: "milk", Ratio: -1}}},
+			errors:      []fieldError{{"substitutes[0].ratio", exception.CodeTooSmall}},
+			description: "negative ratio, should report the item field",
+		},
+		{
+			input:       Invitation{},
+			description: "invitation without role, should skip the role rules",
+		},
+		{
+			input:       Invitation{Role: "owner", MaxUses: -1},
+			errors:      []fieldError{{"role", exception.CodeNotOneOf}, {"maxUses", exception.CodeTooSmall}},
+			description: "unknown role and negative uses, should report both",
+		},
+	}
+
+	for _, tc := range testCases {
+		errs := Validate(tc.input)
+		if !assert.Len(errs, len(tc.errors), tc.description) {
+			continue
+		}
+		for i, err := range errs {
+			errValidation, ok := err.(exception.ErrValidation)
+			if assert.True(ok, tc.description) {
+				assert.Equal(tc.errors[i].field, errValidation.Field, tc.description)
+				assert.Equal(tc.errors[i].code, errValidation.Code, tc.description)
+			}
+		}
+	}
+}
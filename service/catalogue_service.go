@@ -0,0 +1,402 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/denisyao1/welsh-academy-api/exception"
+	"github.com/denisyao1/welsh-academy-api/model"
+	"github.com/denisyao1/welsh-academy-api/repository"
+	"github.com/denisyao1/welsh-academy-api/schema"
+)
+
+// number of rows read from the database at once during exports
+const exportBatchSize = 100
+
+// errRollback is returned in import transactions to discard the changes.
+var errRollback = errors.New("rollback")
+
+// CatalogueService contains business logic to import and export the whole catalogue.
+type CatalogueService interface {
+	// Import validates and creates the ingredients and recipes of a CSV or NDJSON catalogue.
+	// Either all the rows are created or none is.
+	//
+	// In dry run mode nothing is created, the response only reports invalid rows.
+	// It returns exception.ErrValidation if the format or the document is invalid.
+	Import(data []byte, format string, dryRun bool) (schema.ImportResponse, error)
+
+	// Export writes all the ingredients then all the recipes in the import format.
+	//
+	// It returns exception.ErrValidation if the format is invalid.
+	Export(w io.Writer, format string) error
+}
+
+type catalogueService struct {
+	repo           repository.CatalogueRepository
+	ingredientRepo repository.IngredientRepository
+	recipeRepo     repository.RecipeRepository
+}
+
+// NewCatalogueService creates new CatalogueService.
+func NewCatalogueService(
+	repo repository.CatalogueRepository,
+	ingredientRepo repository.IngredientRepository,
+	recipeRepo repository.RecipeRepository,
+) CatalogueService {
+	return &catalogueService{repo: repo, ingredientRepo: ingredientRepo, recipeRepo: recipeRepo}
+}
+
+// catalogueLine is a parsed row of an imported catalogue.
+type catalogueLine struct {
+	line int
+	row  schema.CatalogueRow
+	errs []error
+}
+
+func (s catalogueService) Import(data []byte, format string, dryRun bool) (schema.ImportResponse, error) {
+	response := schema.ImportResponse{DryRun: dryRun}
+
+	lines, err := parseCatalogue(data, format)
+	if err != nil {
+		return response, err
+	}
+
+	err = s.repo.Transaction(func(ingredientRepo repository.IngredientRepository, recipeRepo repository.RecipeRepository) error {
+		ingredientService := NewIngredientService(ingredientRepo)
+		recipeService := NewRecipeService(recipeRepo, ingredientRepo)
+
+		for _, l := range lines {
+			errs := l.errs
+			if len(errs) == 0 {
+				var err error
+				errs, err = importRow(l.row, ingredientService, recipeService)
+				if err != nil {
+					return err
+				}
+			}
+
+			if len(errs) != 0 {
+				response.Errors = append(response.Errors, schema.ImportRowError{
+					Line: l.line, Type: l.row.Type, Name: l.row.Name, Errors: errs,
+				})
+				continue
+			}
+
+			if l.row.Type == schema.CatalogueIngredient {
+				response.Ingredients++
+			} else {
+				response.Recipes++
+			}
+		}
+
+		if dryRun || len(response.Errors) != 0 {
+			return errRollback
+		}
+		return nil
+	})
+
+	if err != nil && !errors.Is(err, errRollback) {
+		return response, err
+	}
+
+	response.Applied = err == nil
+	return response, nil
+}
+
+// importRow validates and creates a catalogue row. It returns the validation
+// errors of the row or an unexpected error.
+func importRow(row schema.CatalogueRow, ingredientService IngredientService, recipeService RecipeService) ([]error, error) {
+	var newErr = exception.NewErrValidation
+
+	switch row.Type {
+	case schema.CatalogueIngredient:
+		input := schema.Ingredient{Name: row.Name, Allergens: row.Allergens}
+		if errs := schema.Validate(input); errs != nil {
+			return errs, nil
+		}
+
+		_, err := ingredientService.Create(input)
+		if errors.Is(err, exception.ErrDuplicateKey) {
+			return []error{newErr("name", exception.CodeIngredientExists, row.Name)}, nil
+		}
+		return nil, err
+
+	case schema.CatalogueRecipe:
+		input := schema.Recipe{
+			Name:     row.Name,
+			Making:   row.Making,
+			Yield:    row.Yield,
+			PrepTime: row.PrepTime,
+			CookTime: row.CookTime,
+		}
+		for _, name := range row.Ingredients {
+			input.Ingredients = append(input.Ingredients, schema.Ingredient{Name: name})
+		}
+		for _, name := range row.Tags {
+			input.Tags = append(input.Tags, schema.Tag{Name: name})
+		}
+		if errs := schema.Validate(input); errs != nil {
+			return errs, nil
+		}
+
+		recipe, errs := recipeService.Validate(input)
+		if errs != nil {
+			for _, err := range errs {
+				if _, ok := err.(exception.ErrValidation); !ok {
+					return nil, err
+				}
+			}
+			return errs, nil
+		}
+
+		err := recipeService.Create(&recipe)
+		if errors.Is(err, exception.ErrDuplicateKey) {
+			return []error{newErr("name", exception.CodeRecipeExists, row.Name)}, nil
+		}
+		return nil, err
+	}
+
+	return []error{newErr("type", exception.CodeInvalidRowType, row.Type)}, nil
+}
+
+// parseCatalogue reads the rows of a CSV or NDJSON catalogue.
+func parseCatalogue(data []byte, format string) ([]catalogueLine, error) {
+	switch format {
+	case schema.CatalogueCSV:
+		return parseCatalogueCSV(data)
+	case schema.CatalogueNDJSON:
+		return parseCatalogueNDJSON(data)
+	}
+	return nil, invalidFormatErr(format)
+}
+
+func parseCatalogueCSV(data []byte) ([]catalogueLine, error) {
+	var newErr = exception.NewErrValidation
+
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\ufeff"))))
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil || !isCatalogueHeader(header) {
+		return nil, newErr("document", exception.CodeInvalidHeader, strings.Join(schema.CatalogueHeader, ","))
+	}
+
+	var lines []catalogueLine
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, newErr("document", exception.CodeInvalidDocument, err.Error())
+		}
+
+		l := catalogueLine{}
+		l.line, _ = reader.FieldPos(0)
+
+		if len(record) != len(header) {
+			l.errs = append(l.errs, newErr("line", exception.CodeFieldCount, len(header), len(record)))
+			lines = append(lines, l)
+			continue
+		}
+
+		l.row = schema.CatalogueRow{
+			Type:   strings.TrimSpace(record[0]),
+			Name:   strings.TrimSpace(record[1]),
+			Making: strings.TrimSpace(record[2]),
+			Yield:  strings.TrimSpace(record[4]),
+		}
+		l.row.Ingredients = splitCSVList(record[3])
+		l.row.Allergens = splitCSVList(record[7])
+		if len(record) > 8 {
+			l.row.Tags = splitCSVList(record[8])
+		}
+
+		times := []struct {
+			field   string
+			value   string
+			minutes *int
+		}{
+			{field: "prepTime", value: record[5], minutes: &l.row.PrepTime},
+			{field: "cookTime", value: record[6], minutes: &l.row.CookTime},
+		}
+		for _, t := range times {
+			if t.value = strings.TrimSpace(t.value); t.value == "" {
+				continue
+			}
+			minutes, err := strconv.Atoi(t.value)
+			if err != nil || minutes < 0 {
+				l.errs = append(l.errs, newErr(t.field, exception.CodeInvalidMinutes, t.value))
+				continue
+			}
+			*t.minutes = minutes
+		}
+
+		lines = append(lines, l)
+	}
+	return lines, nil
+}
+
+func parseCatalogueNDJSON(data []byte) ([]catalogueLine, error) {
+	var lines []catalogueLine
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for number := 1; scanner.Scan(); number++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+
+		l := catalogueLine{line: number}
+		if err := json.Unmarshal(text, &l.row); err != nil {
+			l.errs = append(l.errs, exception.NewErrValidation("line", exception.CodeInvalidJSONLine))
+		}
+		lines = append(lines, l)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, exception.NewErrValidation("document", exception.CodeInvalidDocument, err.Error())
+	}
+	return lines, nil
+}
+
+func (s catalogueService) Export(w io.Writer, format string) error {
+	writer, err := newCatalogueWriter(w, format)
+	if err != nil {
+		return err
+	}
+
+	err = s.ingredientRepo.FindInBatches(exportBatchSize, func(ingredients []model.Ingredient) error {
+		for _, ingredient := range ingredients {
+			row := schema.CatalogueRow{
+				Type:      schema.CatalogueIngredient,
+				Name:      ingredient.Name,
+				Allergens: ingredient.Allergens.Names(),
+			}
+			if err := writer.write(row); err != nil {
+				return err
+			}
+		}
+		return writer.flush()
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.recipeRepo.FindInBatches(exportBatchSize, func(recipes []model.Recipe) error {
+		for _, recipe := range recipes {
+			row := schema.CatalogueRow{
+				Type:     schema.CatalogueRecipe,
+				Name:     recipe.Name,
+				Making:   recipe.Making,
+				Yield:    recipe.Yield,
+				PrepTime: recipe.PrepTime,
+				CookTime: recipe.CookTime,
+			}
+			for _, ingredient := range recipe.Ingredients {
+				row.Ingredients = append(row.Ingredients, ingredient.Name)
+			}
+			for _, tag := range recipe.Tags {
+				row.Tags = append(row.Tags, tag.Name)
+			}
+			if err := writer.write(row); err != nil {
+				return err
+			}
+		}
+		return writer.flush()
+	})
+}
+
+// catalogueWriter writes catalogue rows in CSV or NDJSON.
+type catalogueWriter struct {
+	write func(row schema.CatalogueRow) error
+	flush func() error
+}
+
+func newCatalogueWriter(w io.Writer, format string) (catalogueWriter, error) {
+	// flush the underlying writer too so that rows are streamed batch by batch
+	flush := func() error {
+		if flusher, ok := w.(interface{ Flush() error }); ok {
+			return flusher.Flush()
+		}
+		return nil
+	}
+
+	switch format {
+	case schema.CatalogueCSV:
+		csvWriter := csv.NewWriter(w)
+		if err := csvWriter.Write(schema.CatalogueHeader); err != nil {
+			return catalogueWriter{}, err
+		}
+		return catalogueWriter{
+			write: func(row schema.CatalogueRow) error {
+				return csvWriter.Write([]string{
+					row.Type,
+					row.Name,
+					row.Making,
+					strings.Join(row.Ingredients, "|"),
+					row.Yield,
+					formatMinutes(row.PrepTime),
+					formatMinutes(row.CookTime),
+					strings.Join(row.Allergens, "|"),
+					strings.Join(row.Tags, "|"),
+				})
+			},
+			flush: func() error {
+				csvWriter.Flush()
+				if err := csvWriter.Error(); err != nil {
+					return err
+				}
+				return flush()
+			},
+		}, nil
+
+	case schema.CatalogueNDJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetEscapeHTML(false)
+		return catalogueWriter{
+			write: func(row schema.CatalogueRow) error { return encoder.Encode(row) },
+			flush: flush,
+		}, nil
+	}
+
+	return catalogueWriter{}, invalidFormatErr(format)
+}
+
+// isCatalogueHeader tells if a CSV header is the catalogue one, with or without the tags column.
+func isCatalogueHeader(header []string) bool {
+	columns := strings.ToLower(strings.Join(header, ","))
+	return columns == strings.ToLower(strings.Join(schema.CatalogueHeader, ",")) ||
+		columns == strings.ToLower(strings.Join(schema.CatalogueHeader[:len(schema.CatalogueHeader)-1], ","))
+}
+
+// splitCSVList returns the non empty elements of a | separated CSV field.
+func splitCSVList(field string) []string {
+	var elms []string
+	for _, elm := range strings.Split(field, "|") {
+		if elm = strings.TrimSpace(elm); elm != "" {
+			elms = append(elms, elm)
+		}
+	}
+	return elms
+}
+
+func formatMinutes(minutes int) string {
+	if minutes == 0 {
+		return ""
+	}
+	return strconv.Itoa(minutes)
+}
+
+func invalidFormatErr(format string) error {
+	formats := strings.Join([]string{schema.CatalogueCSV, schema.CatalogueNDJSON}, ", ")
+	return exception.NewErrValidation("format", exception.CodeInvalidFormat, format, formats)
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/go-pdf/fpdf"
+	"golang.org/x/text/unicode/norm"
+)
+
+const pdfFont = "Helvetica"
+
+// renderCookbookPDF writes a cookbook as an A4 PDF document.
+func renderCookbookPDF(w io.Writer, data cookbookData) error {
+	// a first pass finds the page of each recipe for the table of contents
+	draft, pages := buildCookbookPDF(data, nil)
+	if err := draft.Error(); err != nil {
+		return err
+	}
+
+	pdf, _ := buildCookbookPDF(data, pages)
+	return pdf.Output(w)
+}
+
+// buildCookbookPDF lays out a cookbook and returns the page each recipe starts on.
+func buildCookbookPDF(data cookbookData, tocPages []int) (*fpdf.Fpdf, []int) {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle(data.Title, true)
+	pdf.SetCreator("Welsh Academy API", true)
+	pdf.SetMargins(20, 20, 20)
+	pdf.SetAutoPageBreak(true, 20)
+
+	tr := pdfTranslator(pdf)
+
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-15)
+		pdf.SetFont(pdfFont, "I", 9)
+		pdf.CellFormat(0, 10, strconv.Itoa(pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
+
+	links := make([]int, len(data.Recipes))
+	for i := range links {
+		links[i] = pdf.AddLink()
+	}
+
+	// title and table of contents
+	pdf.AddPage()
+	pdf.SetFont(pdfFont, "B", 24)
+	pdf.MultiCell(0, 12, tr(data.Title), "", "C", false)
+	pdf.Ln(8)
+	pdf.SetFont(pdfFont, "B", 16)
+	pdf.CellFormat(0, 10, "Contents", "", 1, "L", false, 0, "")
+	pdf.SetFont(pdfFont, "", 12)
+	for i, recipe := range data.Recipes {
+		page := ""
+		if tocPages != nil {
+			page = strconv.Itoa(tocPages[i])
+		}
+		name := fmt.Sprintf("%d. %s", i+1, recipe.Name)
+		pdf.CellFormat(150, 8, tr(name), "", 0, "L", false, links[i], "")
+		pdf.CellFormat(0, 8, page, "", 1, "R", false, links[i], "")
+	}
+
+	// one recipe per page
+	pages := make([]int, len(data.Recipes))
+	for i, recipe := range data.Recipes {
+		pdf.AddPage()
+		pages[i] = pdf.PageNo()
+		pdf.SetLink(links[i], 0, -1)
+		pdf.Bookmark(tr(recipe.Name), 0, -1)
+
+		pdf.SetFont(pdfFont, "B", 20)
+		pdf.MultiCell(0, 10, tr(recipe.Name), "", "L", false)
+		if recipe.Details != "" {
+			pdf.SetFont(pdfFont, "I", 11)
+			pdf.MultiCell(0, 6, tr(recipe.Details), "", "L", false)
+		}
+
+		pdfHeading(pdf, "Ingredients")
+		for _, ingredient := range recipe.Ingredients {
+			pdf.MultiCell(0, 6, tr("• "+ingredient), "", "L", false)
+		}
+
+		pdfHeading(pdf, "Method")
+		for j, step := range recipe.Steps {
+			pdf.MultiCell(0, 6, tr(fmt.Sprintf("%d. %s", j+1, step)), "", "L", false)
+			pdf.Ln(1)
+		}
+
+		allergens := "none declared"
+		if len(recipe.Allergens) != 0 {
+			allergens = strings.Join(recipe.Allergens, ", ")
+		}
+		pdf.Ln(4)
+		pdf.SetFont(pdfFont, "B", 11)
+		pdf.Write(6, "Allergens: ")
+		pdf.SetFont(pdfFont, "", 11)
+		pdf.Write(6, tr(allergens))
+	}
+
+	return pdf, pages
+}
+
+func pdfHeading(pdf *fpdf.Fpdf, text string) {
+	pdf.Ln(4)
+	pdf.SetFont(pdfFont, "B", 14)
+	pdf.CellFormat(0, 9, text, "", 1, "L", false, 0, "")
+	pdf.SetFont(pdfFont, "", 11)
+}
+
+// pdfTranslator returns a function converting UTF-8 text to the code page of
+// the PDF core fonts. Letters the code page lacks, like the Welsh ŵ and ŷ,
+// lose their accent instead of being replaced.
+func pdfTranslator(pdf *fpdf.Fpdf) func(string) string {
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	return func(text string) string {
+		var b strings.Builder
+		for _, r := range text {
+			if r >= 0x80 && tr(string(r)) == "." {
+				if base := []rune(norm.NFD.String(string(r)))[0]; tr(string(base)) != "." {
+					r = base
+				} else {
+					r = '?'
+				}
+			}
+			b.WriteRune(r)
+		}
+		return tr(b.String())
+	}
+}
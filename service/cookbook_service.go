@@ -0,0 +1,178 @@
+package service
+
+import (
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"strings"
+	"text/template"
+
+	"github.com/denisyao1/welsh-academy-api/exception"
+	"github.com/denisyao1/welsh-academy-api/model"
+	"github.com/denisyao1/welsh-academy-api/repository"
+	"github.com/denisyao1/welsh-academy-api/schema"
+)
+
+//go:embed templates
+var templatesFS embed.FS
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", `*`, `\*`, `_`, `\_`, `[`, `\[`, `]`, `\]`, `<`, `\<`, `>`, `\>`, `#`, `\#`,
+)
+
+var templateFuncs = map[string]any{
+	"inc":  func(i int) int { return i + 1 },
+	"join": strings.Join,
+	"md":   markdownEscaper.Replace,
+}
+
+var (
+	markdownCookbookTemplate = template.Must(template.New("cookbook.md.tmpl").
+					Funcs(templateFuncs).ParseFS(templatesFS, "templates/cookbook.md.tmpl"))
+	htmlCookbookTemplate = htmltemplate.Must(htmltemplate.New("cookbook.html.tmpl").
+				Funcs(templateFuncs).ParseFS(templatesFS, "templates/cookbook.html.tmpl"))
+)
+
+const (
+	defaultCookbookTitle   = "Welsh Academy Cookbook"
+	favoritesCookbookTitle = "My favorite recipes"
+)
+
+// CookbookService contains business logic to export recipes as printable cookbooks.
+type CookbookService interface {
+	// RenderFavorites writes the cookbook of the user favorite recipes in the format and locale.
+	//
+	// It returns exception.ErrValidation if the format is unknown or the user has no favorite.
+	RenderFavorites(w io.Writer, userID int, format string, locale string) error
+
+	// Render writes the cookbook of the recipes in the format and locale, in the order of their IDs.
+	//
+	// It returns exception.ErrValidation if the format is unknown or a recipe doesn't exist.
+	Render(w io.Writer, cookbook schema.Cookbook, format string, locale string) error
+}
+
+type cookbookService struct {
+	recipeRepo         repository.RecipeRepository
+	translationService TranslationService
+}
+
+// NewCookbookService creates new CookbookService.
+func NewCookbookService(recipeRepo repository.RecipeRepository, translationService TranslationService) CookbookService {
+	return &cookbookService{recipeRepo: recipeRepo, translationService: translationService}
+}
+
+// cookbookData is the content of a cookbook given to renderers.
+type cookbookData struct {
+	Title   string
+	Locale  string
+	Recipes []cookbookRecipe
+}
+
+type cookbookRecipe struct {
+	Anchor      string
+	Name        string
+	Details     string
+	Ingredients []string
+	Steps       []string
+	Allergens   []string
+}
+
+func (s cookbookService) RenderFavorites(w io.Writer, userID int, format string, locale string) error {
+	recipes, err := s.recipeRepo.FindFavorites(userID)
+	if err != nil {
+		return err
+	}
+
+	if len(recipes) == 0 {
+		return exception.NewErrValidation("recipes", exception.CodeNoFavorites)
+	}
+
+	if err = s.translationService.LocalizeRecipes(recipes, locale); err != nil {
+		return err
+	}
+	return render(w, newCookbookData(favoritesCookbookTitle, locale, recipes), format)
+}
+
+func (s cookbookService) Render(w io.Writer, cookbook schema.Cookbook, format string, locale string) error {
+	recipes, err := s.recipeRepo.FindByIDs(cookbook.RecipeIDs)
+	if err != nil {
+		return err
+	}
+
+	byID := make(map[int]model.Recipe)
+	for _, recipe := range recipes {
+		byID[recipe.ID] = recipe
+	}
+
+	// keep the order chosen by the user
+	ordered := make([]model.Recipe, 0, len(cookbook.RecipeIDs))
+	for _, id := range cookbook.RecipeIDs {
+		recipe, ok := byID[id]
+		if !ok {
+			return exception.NewErrValidation("recipeIds", exception.CodeUnknownRecipe, id)
+		}
+		ordered = append(ordered, recipe)
+	}
+
+	title := strings.TrimSpace(cookbook.Title)
+	if title == "" {
+		title = defaultCookbookTitle
+	}
+
+	if err = s.translationService.LocalizeRecipes(ordered, locale); err != nil {
+		return err
+	}
+	return render(w, newCookbookData(title, locale, ordered), format)
+}
+
+func render(w io.Writer, data cookbookData, format string) error {
+	switch format {
+	case schema.CookbookMarkdown:
+		return markdownCookbookTemplate.Execute(w, data)
+	case schema.CookbookHTML:
+		return htmlCookbookTemplate.Execute(w, data)
+	case schema.CookbookPDF:
+		return renderCookbookPDF(w, data)
+	}
+	formats := strings.Join([]string{schema.CookbookMarkdown, schema.CookbookHTML, schema.CookbookPDF}, ", ")
+	return exception.NewErrValidation("format", exception.CodeInvalidFormat, format, formats)
+}
+
+func newCookbookData(title string, locale string, recipes []model.Recipe) cookbookData {
+	data := cookbookData{Title: title, Locale: locale}
+
+	for _, recipe := range recipes {
+		r := cookbookRecipe{
+			Anchor:    fmt.Sprintf("recipe-%d", recipe.ID),
+			Name:      recipe.Name,
+			Allergens: recipe.Allergens().Names(),
+		}
+
+		var details []string
+		if recipe.Yield != "" {
+			details = append(details, "Serves "+recipe.Yield)
+		}
+		if recipe.PrepTime > 0 {
+			details = append(details, fmt.Sprintf("Preparation %d min", recipe.PrepTime))
+		}
+		if recipe.CookTime > 0 {
+			details = append(details, fmt.Sprintf("Cooking %d min", recipe.CookTime))
+		}
+		r.Details = strings.Join(details, " · ")
+
+		for _, ingredient := range recipe.Ingredients {
+			r.Ingredients = append(r.Ingredients, ingredient.Name)
+		}
+
+		for _, step := range strings.Split(recipe.Making, "\n") {
+			if step = strings.TrimSpace(step); step != "" {
+				r.Steps = append(r.Steps, step)
+			}
+		}
+
+		data.Recipes = append(data.Recipes, r)
+	}
+
+	return data
+}
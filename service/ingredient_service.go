@@ -4,20 +4,23 @@ import (
 	"github.com/denisyao1/welsh-academy-api/exception"
 	"github.com/denisyao1/welsh-academy-api/model"
 	"github.com/denisyao1/welsh-academy-api/repository"
+	"github.com/denisyao1/welsh-academy-api/schema"
 )
 
 // IngredientService contains business logic to save and retreive ingredients.
 type IngredientService interface {
-	// Validate validates user input
-	Validate(ingredient model.Ingredient) exception.ErrValidation
-
-	// Create adds new ingredient to database.
+	// Create adds new ingredient to database from user inputs checked with schema.Validate.
 	//
 	// It returns exception.ErrDuplicateKey if the recipe name is alredy used.
-	Create(ingredient *model.Ingredient) error
+	Create(input schema.Ingredient) (model.Ingredient, error)
 
 	// FindAll returns all the ingredients from the database.
 	FindAll() ([]model.Ingredient, error)
+
+	// Delete moves an ingredient to the trash.
+	//
+	// It returns exception.ErrRecordNotFound if the ingredient doesn't exist.
+	Delete(ingredientID int) error
 }
 
 // NewIngredientService returns new IngredientService.
@@ -29,30 +32,34 @@ type ingredientService struct {
 	repo repository.IngredientRepository
 }
 
-func (s ingredientService) Validate(ingredient model.Ingredient) exception.ErrValidation {
-	if ingredient.Name == "" {
-		return exception.NewErrValidation("name", "the name is reqired")
-	}
-	return exception.ErrValidation{}
+func (s ingredientService) Create(input schema.Ingredient) (model.Ingredient, error) {
+	ingredient := model.Ingredient{Name: input.Name}
 
-}
+	allergens, err := model.ParseAllergens(input.Allergens)
+	if err != nil {
+		return ingredient, err
+	}
+	ingredient.Allergens = allergens
 
-func (s ingredientService) Create(ingredient *model.Ingredient) error {
 	// Check if ingredient is already in the database
-	ok, err := s.repo.IsNotCreated(*ingredient)
+	ok, err := s.repo.IsNotCreated(ingredient)
 	if err != nil {
-		return err
+		return ingredient, err
 	}
 
 	if !ok {
-		return exception.ErrDuplicateKey
+		return ingredient, exception.ErrDuplicateKey
 	}
 
-	return s.repo.Create(ingredient)
-
+	err = s.repo.Create(&ingredient)
+	return ingredient, err
 }
 
 func (s ingredientService) FindAll() ([]model.Ingredient, error) {
 
 	return s.repo.FindAll()
 }
+
+func (s ingredientService) Delete(ingredientID int) error {
+	return s.repo.Delete(ingredientID)
+}
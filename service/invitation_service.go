@@ -0,0 +1,130 @@
+package service
+
+import (
+	"encoding/base64"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/denisyao1/welsh-academy-api/exception"
+	"github.com/denisyao1/welsh-academy-api/model"
+	"github.com/denisyao1/welsh-academy-api/repository"
+	"github.com/denisyao1/welsh-academy-api/schema"
+)
+
+const (
+	defaultInvitationUses = 1
+	defaultInvitationDays = 7
+	invitationCodeBytes   = 12 // giving 16 characters
+)
+
+// InvitationService contains business logic to invite people to register.
+type InvitationService interface {
+	// Create creates new invitation of an admin, returned with its code shown only once.
+	Create(adminID int, input schema.Invitation) (model.Invitation, error)
+
+	// List returns the invitations with the users who registered with them, most recent first.
+	List() ([]model.Invitation, error)
+
+	// Revoke prevents an invitation from being used again.
+	//
+	// It returns exception.ErrRecordNotFound if there is no such active invitation.
+	Revoke(invitationID int) error
+
+	// Register creates an account with an invitation code, which gives its role.
+	//
+	// It returns exception.ErrValidations if the code is invalid, expired or used up,
+	// or if the password breaks the policy, and exception.ErrDuplicateKey if the
+	// username is used.
+	Register(input schema.Registration) (model.User, error)
+}
+
+type invitationService struct {
+	repo  repository.InvitationRepository
+	users UserService
+}
+
+// NewInvitationService creates new InvitationService.
+func NewInvitationService(repo repository.InvitationRepository, users UserService) InvitationService {
+	return &invitationService{repo: repo, users: users}
+}
+
+func (s invitationService) Create(adminID int, input schema.Invitation) (model.Invitation, error) {
+	invitation := model.Invitation{
+		Role:      input.Role,
+		MaxUses:   input.MaxUses,
+		CreatedBy: adminID,
+	}
+	if invitation.Role == "" {
+		invitation.Role = model.RoleReader
+	}
+	if invitation.MaxUses == 0 {
+		invitation.MaxUses = defaultInvitationUses
+	}
+	validDays := input.ValidDays
+	if validDays == 0 {
+		validDays = defaultInvitationDays
+	}
+	invitation.ExpiresAt = time.Now().Add(time.Duration(validDays) * 24 * time.Hour)
+
+	code, err := randomString(invitationCodeBytes, base64.RawURLEncoding.EncodeToString)
+	if err != nil {
+		return invitation, err
+	}
+	invitation.Hash = hashToken(code)
+
+	if err = s.repo.Create(&invitation); err != nil {
+		return invitation, err
+	}
+	log.Printf("User %d created invitation %d for the %s role", adminID, invitation.ID, invitation.Role)
+
+	invitation.Code = code
+	invitation.Uses = []model.InvitationUse{}
+	return invitation, nil
+}
+
+func (s invitationService) List() ([]model.Invitation, error) {
+	return s.repo.List()
+}
+
+func (s invitationService) Revoke(invitationID int) error {
+	return s.repo.Revoke(invitationID)
+}
+
+func (s invitationService) Register(input schema.Registration) (model.User, error) {
+	invalidCodeErr := exception.NewErrValidations(exception.NewErrValidation("invitationCode", exception.CodeInvalidInvitation))
+
+	invitation, err := s.repo.GetByHash(hashToken(input.InvitationCode))
+	if err != nil {
+		if errors.Is(err, exception.ErrRecordNotFound) {
+			return model.User{}, invalidCodeErr
+		}
+		return model.User{}, err
+	}
+	if !isUsable(invitation) {
+		return model.User{}, invalidCodeErr
+	}
+
+	userSchema := schema.User{Username: input.Username, Password: input.Password, Roles: []model.Role{invitation.Role}}
+	user, err := s.users.ValidateUserCreation(userSchema)
+	if err != nil {
+		return user, err
+	}
+
+	// the last use may be taken meanwhile
+	redeemed, err := s.repo.Redeem(invitation.ID, &user)
+	if err != nil {
+		return user, err
+	}
+	if !redeemed {
+		return user, invalidCodeErr
+	}
+	log.Printf("User %d registered with invitation %d", user.ID, invitation.ID)
+
+	return user, nil
+}
+
+// isUsable tells if an invitation is neither revoked, expired nor used up.
+func isUsable(invitation model.Invitation) bool {
+	return invitation.RevokedAt == nil && time.Now().Before(invitation.ExpiresAt) && invitation.UseCount < invitation.MaxUses
+}
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+
+	"github.com/denisyao1/welsh-academy-api/schema"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// kid of the key made of JWT_SECRET, which is never published
+const hmacKeyID = "hmac"
+
+// KeyService signs access tokens and verifies them with the key named by their kid header.
+type KeyService interface {
+	// Sign returns the token of the claims signed with the current key.
+	Sign(claims jwt.MapClaims) (string, error)
+
+	// Verify returns the claims of a token signed with one of the keys and not expired.
+	Verify(token string) (jwt.MapClaims, error)
+
+	// JWKS returns the public keys for other services to verify the tokens.
+	JWKS() schema.JWKS
+}
+
+// signingKey is a key with the kid naming it in the tokens.
+type signingKey struct {
+	id      string
+	method  jwt.SigningMethod
+	private any // nil for keys only verifying tokens
+	public  any
+	jwk     schema.JWK
+}
+
+type keyService struct {
+	signing signingKey
+	keys    map[string]signingKey
+}
+
+// NewKeyService creates new KeyService signing with the first key and verifying with all of them.
+//
+// Keys are RSA or Ed25519 keys, the signing one being a private key,
+// or an HMAC secret as bytes.
+func NewKeyService(signing any, verification ...any) (KeyService, error) {
+	s := keyService{keys: make(map[string]signingKey)}
+
+	for i, key := range append([]any{signing}, verification...) {
+		k, err := newSigningKey(key)
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 {
+			if k.private == nil {
+				return nil, errors.New("the signing key must be a private key")
+			}
+			s.signing = k
+		}
+		s.keys[k.id] = k
+	}
+
+	return s, nil
+}
+
+// LoadKeyService creates new KeyService with the PEM keys of files, the signing one being
+// a private key. Tokens are signed with the secret when there is no signing key file.
+func LoadKeyService(signingFile string, verificationFiles []string, secret string) (KeyService, error) {
+	if signingFile == "" {
+		if secret == "" {
+			return nil, errors.New("no JWT signing key nor secret")
+		}
+		return NewKeyService([]byte(secret))
+	}
+
+	var keys []any
+	for _, file := range append([]string{signingFile}, verificationFiles...) {
+		key, err := readPEMKey(file)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return NewKeyService(keys[0], keys[1:]...)
+}
+
+func (s keyService) Sign(claims jwt.MapClaims) (string, error) {
+	token := jwt.NewWithClaims(s.signing.method, claims)
+	token.Header["kid"] = s.signing.id
+	return token.SignedString(s.signing.private)
+}
+
+func (s keyService) Verify(tokenString string) (jwt.MapClaims, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := s.keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown key: %q", kid)
+		}
+		// the algorithm is the one of the key, not the one the token claims
+		if token.Method.Alg() != key.method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return key.public, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !(ok && token.Valid) {
+		return nil, jwt.ErrTokenInvalidClaims
+	}
+
+	// tokens never expiring are not accepted
+	if exp, err := claims.GetExpirationTime(); err != nil || exp == nil {
+		return nil, jwt.ErrTokenInvalidClaims
+	}
+
+	return claims, nil
+}
+
+func (s keyService) JWKS() schema.JWKS {
+	jwks := schema.JWKS{Keys: []schema.JWK{}}
+	for _, key := range s.sortedKeys() {
+		if key.id != hmacKeyID {
+			jwks.Keys = append(jwks.Keys, key.jwk)
+		}
+	}
+	return jwks
+}
+
+// sortedKeys returns the signing key first, then the other ones by kid.
+func (s keyService) sortedKeys() []signingKey {
+	var others []signingKey
+	for id, key := range s.keys {
+		if id != s.signing.id {
+			others = append(others, key)
+		}
+	}
+	sort.Slice(others, func(i, j int) bool { return others[i].id < others[j].id })
+	return append([]signingKey{s.signing}, others...)
+}
+
+// newSigningKey returns the key named by its RFC 7638 thumbprint.
+func newSigningKey(key any) (signingKey, error) {
+	var k signingKey
+	encode := base64.RawURLEncoding.EncodeToString
+
+	switch key := key.(type) {
+	case []byte:
+		return signingKey{id: hmacKeyID, method: jwt.SigningMethodHS256, private: key, public: key}, nil
+	case *rsa.PrivateKey:
+		k, err := newSigningKey(&key.PublicKey)
+		k.private = key
+		return k, err
+	case *rsa.PublicKey:
+		if key.N.BitLen() < 2048 {
+			return signingKey{}, errors.New("RSA keys must have at least 2048 bits")
+		}
+		k.method, k.public = jwt.SigningMethodRS256, key
+		k.jwk = schema.JWK{Kty: "RSA", N: encode(key.N.Bytes()), E: encode(big.NewInt(int64(key.E)).Bytes())}
+	case ed25519.PrivateKey:
+		k, err := newSigningKey(key.Public())
+		k.private = key
+		return k, err
+	case ed25519.PublicKey:
+		k.method, k.public = jwt.SigningMethodEdDSA, key
+		k.jwk = schema.JWK{Kty: "OKP", Crv: "Ed25519", X: encode(key)}
+	default:
+		return signingKey{}, fmt.Errorf("unsupported key type %T", key)
+	}
+
+	// members of the thumbprint are in lexicographic order
+	var members string
+	if k.jwk.Kty == "RSA" {
+		members = fmt.Sprintf(`{"e":%q,"kty":"RSA","n":%q}`, k.jwk.E, k.jwk.N)
+	} else {
+		members = fmt.Sprintf(`{"crv":"Ed25519","kty":"OKP","x":%q}`, k.jwk.X)
+	}
+	sum := sha256.Sum256([]byte(members))
+
+	k.id = encode(sum[:])
+	k.jwk.Kid = k.id
+	k.jwk.Alg = k.method.Alg()
+	k.jwk.Use = "sig"
+	return k, nil
+}
+
+// readPEMKey returns the RSA or Ed25519 key of a PEM file.
+func readPEMKey(file string) (any, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM key", file)
+	}
+
+	var key any
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		err = fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+
+	return key, nil
+}
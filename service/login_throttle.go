@@ -0,0 +1,194 @@
+package service
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/denisyao1/welsh-academy-api/exception"
+	"github.com/denisyao1/welsh-academy-api/model"
+	"github.com/denisyao1/welsh-academy-api/repository"
+	"github.com/denisyao1/welsh-academy-api/util"
+)
+
+// LoginThrottle slows down the logins of usernames and IP addresses after
+// failures, and locks them out for a while after too many of them.
+type LoginThrottle interface {
+	// Check returns an error of code exception.CodeTooManyLoginAttempts, telling
+	// when to retry, if the username or the IP address must wait before logging in.
+	//
+	// Otherwise it reserves the attempt until it is settled by Fail, Succeed or Release,
+	// so that concurrent attempts can't exceed the limits.
+	Check(username string, ip string) error
+
+	// Fail records a failed login of a username from an IP address.
+	Fail(username string, ip string) error
+
+	// Succeed forgets the failed logins of a username.
+	Succeed(username string, ip string) error
+
+	// Release settles an attempt which neither failed nor succeeded yet.
+	Release(username string, ip string)
+
+	// Unlock lets a locked out username log in again at once.
+	Unlock(username string) error
+}
+
+type loginThrottle struct {
+	repo             repository.LoginFailureRepository
+	maxFailures      int
+	maxFailuresPerIP int
+	backoff          time.Duration
+	lockout          time.Duration
+
+	mu      sync.Mutex
+	pending map[string]int // attempts checked but not settled yet, by ID of failures
+}
+
+// NewLoginThrottle creates new LoginThrottle making logins wait backoff after
+// a failure, twice as long after each next one, and locking out usernames after
+// maxFailures and IP addresses after maxFailuresPerIP during lockout.
+//
+// Failures are forgotten once lockout has passed since the last one.
+func NewLoginThrottle(repo repository.LoginFailureRepository, maxFailures int, maxFailuresPerIP int,
+	backoff time.Duration, lockout time.Duration) LoginThrottle {
+	return &loginThrottle{
+		repo:             repo,
+		maxFailures:      maxFailures,
+		maxFailuresPerIP: maxFailuresPerIP,
+		backoff:          backoff,
+		lockout:          lockout,
+		pending:          make(map[string]int),
+	}
+}
+
+func (t *loginThrottle) Check(username string, ip string) error {
+	// the attempts being checked would all fail before being counted otherwise
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	limits := t.limits(username, ip)
+	failures, err := t.repo.Find(usernameKey(username), ipKey(ip))
+	if err != nil {
+		return err
+	}
+	counts := make(map[string]int, len(failures))
+
+	var wait time.Duration
+	for _, failure := range failures {
+		counts[failure.ID] = failure.Count
+		if remaining := time.Until(failure.LockedUntil); remaining > wait {
+			wait = remaining
+		}
+	}
+
+	// the attempts in flight could reach the limit, they are settled within a password check
+	for id, limit := range limits {
+		if counts[id]+t.pending[id] >= limit && wait < time.Second {
+			wait = time.Second
+		}
+	}
+	if wait <= 0 {
+		for id := range limits {
+			t.pending[id]++
+		}
+		return nil
+	}
+
+	seconds := int((wait + time.Second - 1) / time.Second)
+	return exception.NewRetryLater(wait, exception.CodeTooManyLoginAttempts, seconds)
+}
+
+func (t *loginThrottle) Fail(username string, ip string) error {
+	// concurrent failures must all be counted
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.settle(username, ip)
+
+	now := time.Now()
+	if err := t.repo.DeleteBefore(now.Add(-t.lockout)); err != nil {
+		return err
+	}
+
+	limits := t.limits(username, ip)
+	failures, err := t.repo.Find(usernameKey(username), ipKey(ip))
+	if err != nil {
+		return err
+	}
+	counts := make(map[string]int, len(failures))
+	for _, failure := range failures {
+		counts[failure.ID] = failure.Count
+	}
+
+	for id, limit := range limits {
+		failure := model.LoginFailure{ID: id, Count: counts[id] + 1, LastFailedAt: now}
+		failure.LockedUntil = now.Add(t.delay(failure.Count, limit))
+		if failure.Count == limit {
+			log.Printf("Login locked out for %s after %d failures", id, failure.Count)
+		}
+		if err = t.repo.Save(failure); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *loginThrottle) Succeed(username string, ip string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.settle(username, ip)
+
+	return t.repo.Delete(usernameKey(username))
+}
+
+func (t *loginThrottle) Release(username string, ip string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.settle(username, ip)
+}
+
+func (t *loginThrottle) Unlock(username string) error {
+	return t.repo.Delete(usernameKey(username))
+}
+
+// limits returns the number of failures locking out a username and an IP address, by ID of failures.
+func (t *loginThrottle) limits(username string, ip string) map[string]int {
+	return map[string]int{usernameKey(username): t.maxFailures, ipKey(ip): t.maxFailuresPerIP}
+}
+
+// settle forgets an attempt reserved by Check, t.mu being locked.
+func (t *loginThrottle) settle(username string, ip string) {
+	for id := range t.limits(username, ip) {
+		if t.pending[id] <= 1 {
+			delete(t.pending, id)
+		} else {
+			t.pending[id]--
+		}
+	}
+}
+
+// delay returns the time to wait after a number of failures, doubling with each
+// of them until the limit locks out.
+func (t *loginThrottle) delay(count int, limit int) time.Duration {
+	if count >= limit {
+		return t.lockout
+	}
+
+	delay := t.backoff
+	for i := 1; i < count && delay < t.lockout; i++ {
+		delay *= 2
+	}
+	if delay > t.lockout {
+		return t.lockout
+	}
+	return delay
+}
+
+// usernameKey returns the ID of the failures of a username, which may not exist.
+func usernameKey(username string) string {
+	return "user:" + util.NameKey(username)
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
@@ -0,0 +1,278 @@
+package service
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"image/png"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/denisyao1/welsh-academy-api/exception"
+	"github.com/denisyao1/welsh-academy-api/model"
+	"github.com/denisyao1/welsh-academy-api/schema"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+)
+
+const (
+	totpIssuer         = "Welsh Academy"
+	totpPeriod         = 30 // in seconds
+	totpQRCodeSize     = 200
+	recoveryCodeCount  = 10
+	recoveryCodeBytes  = 5 // giving 10 hexadecimal digits
+	mfaChallengeExpiry = 5 * time.Minute
+)
+
+var totpOpts = totp.ValidateOpts{Period: totpPeriod, Digits: otp.DigitsSix, Algorithm: otp.AlgorithmSHA1}
+
+func (s userService) CompleteMFALogin(input schema.MFALogin, userAgent string, ip string) (schema.Token, error) {
+	invalidChallengeErr := exception.New(exception.CodeInvalidMFAChallenge)
+
+	challengeID := hashToken(input.ChallengeToken)
+	challenge, err := s.mfaRepo.GetChallenge(challengeID)
+	if err != nil {
+		if errors.Is(err, exception.ErrRecordNotFound) {
+			return schema.Token{}, invalidChallengeErr
+		}
+		return schema.Token{}, err
+	}
+	if time.Now().After(challenge.ExpiresAt) {
+		return schema.Token{}, invalidChallengeErr
+	}
+
+	user := model.User{ID: challenge.UserID}
+	if err = s.repo.GetByID(&user); err != nil {
+		if errors.Is(err, exception.ErrRecordNotFound) {
+			return schema.Token{}, invalidChallengeErr
+		}
+		return schema.Token{}, err
+	}
+	if user.DisabledAt != nil || !user.TOTPEnabled {
+		return schema.Token{}, invalidChallengeErr
+	}
+
+	// wrong codes count as failed logins, the password being known
+	if err = s.throttle.Check(user.Username, ip); err != nil {
+		return schema.Token{}, err
+	}
+	ok, err := s.verifySecondFactor(user, input.Code)
+	if err != nil {
+		s.throttle.Release(user.Username, ip)
+		return schema.Token{}, err
+	}
+	if !ok {
+		if err = s.throttle.Fail(user.Username, ip); err != nil {
+			return schema.Token{}, err
+		}
+		return schema.Token{}, exception.New(exception.CodeInvalidOTP)
+	}
+
+	// a challenge opens a single session
+	deleted, err := s.mfaRepo.DeleteChallenge(challengeID)
+	if err != nil {
+		s.throttle.Release(user.Username, ip)
+		return schema.Token{}, err
+	}
+	if !deleted {
+		s.throttle.Release(user.Username, ip)
+		return schema.Token{}, invalidChallengeErr
+	}
+	if err = s.throttle.Succeed(user.Username, ip); err != nil {
+		return schema.Token{}, err
+	}
+
+	return s.openSession(user, userAgent, ip)
+}
+
+func (s userService) EnrolTOTP(userID int, input schema.PasswordConfirmation) (schema.TOTPEnrolment, error) {
+	user := model.User{ID: userID}
+	if err := s.repo.GetByID(&user); err != nil {
+		return schema.TOTPEnrolment{}, err
+	}
+
+	// a stolen token is not enough to lock the user out with another authenticator app
+	if !s.passwords.Verify(user.Password, input.CurrentPassword) {
+		return schema.TOTPEnrolment{}, exception.NewErrValidations(exception.NewErrValidation("currentPassword", exception.CodeWrongPassword))
+	}
+	if user.TOTPEnabled {
+		return schema.TOTPEnrolment{}, exception.New(exception.CodeMFAAlreadyEnabled)
+	}
+
+	key, err := totp.Generate(totp.GenerateOpts{Issuer: totpIssuer, AccountName: user.Username, Period: totpPeriod})
+	if err != nil {
+		return schema.TOTPEnrolment{}, err
+	}
+
+	image, err := key.Image(totpQRCodeSize, totpQRCodeSize)
+	if err != nil {
+		return schema.TOTPEnrolment{}, err
+	}
+	var qrCode bytes.Buffer
+	if err = png.Encode(&qrCode, image); err != nil {
+		return schema.TOTPEnrolment{}, err
+	}
+
+	// the secret is only used once verified
+	user.TOTPSecret = key.Secret()
+	if err = s.repo.UpdateTOTP(&user); err != nil {
+		return schema.TOTPEnrolment{}, err
+	}
+
+	return schema.TOTPEnrolment{
+		Secret: key.Secret(),
+		URI:    key.String(),
+		QRCode: "data:image/png;base64," + base64.StdEncoding.EncodeToString(qrCode.Bytes()),
+	}, nil
+}
+
+func (s userService) EnableTOTP(userID int, input schema.TOTPCode) (schema.RecoveryCodes, error) {
+	user := model.User{ID: userID}
+	if err := s.repo.GetByID(&user); err != nil {
+		return schema.RecoveryCodes{}, err
+	}
+	if user.TOTPEnabled {
+		return schema.RecoveryCodes{}, exception.New(exception.CodeMFAAlreadyEnabled)
+	}
+	if user.TOTPSecret == "" {
+		return schema.RecoveryCodes{}, exception.New(exception.CodeMFANotEnrolled)
+	}
+
+	// proves the authenticator app has the secret
+	ok, err := s.verifyTOTP(user, input.Code)
+	if err != nil {
+		return schema.RecoveryCodes{}, err
+	}
+	if !ok {
+		return schema.RecoveryCodes{}, exception.NewErrValidations(exception.NewErrValidation("code", exception.CodeInvalidOTP))
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	records := make([]model.RecoveryCode, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := randomString(recoveryCodeBytes, hex.EncodeToString)
+		if err != nil {
+			return schema.RecoveryCodes{}, err
+		}
+		codes = append(codes, code[:len(code)/2]+"-"+code[len(code)/2:])
+		records = append(records, model.RecoveryCode{UserID: userID, Hash: hashToken(code)})
+	}
+	if err = s.mfaRepo.SetRecoveryCodes(userID, records); err != nil {
+		return schema.RecoveryCodes{}, err
+	}
+
+	user.TOTPEnabled = true
+	if err = s.repo.UpdateTOTP(&user); err != nil {
+		return schema.RecoveryCodes{}, err
+	}
+	log.Printf("User %d enabled two-factor authentication", userID)
+
+	return schema.RecoveryCodes{Codes: codes}, nil
+}
+
+func (s userService) DisableTOTP(userID int, input schema.TOTPCode) error {
+	user := model.User{ID: userID}
+	if err := s.repo.GetByID(&user); err != nil {
+		return err
+	}
+	if !user.TOTPEnabled {
+		return exception.New(exception.CodeMFANotEnrolled)
+	}
+	if s.isMFARequired(user) {
+		return exception.New(exception.CodeMFAMandatory)
+	}
+
+	// a stolen token is not enough to disable it
+	ok, err := s.verifySecondFactor(user, input.Code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return exception.NewErrValidations(exception.NewErrValidation("code", exception.CodeInvalidOTP))
+	}
+
+	user.TOTPEnabled = false
+	user.TOTPSecret = ""
+	if err = s.repo.UpdateTOTP(&user); err != nil {
+		return err
+	}
+	if err = s.mfaRepo.SetRecoveryCodes(userID, nil); err != nil {
+		return err
+	}
+	log.Printf("User %d disabled two-factor authentication", userID)
+	return nil
+}
+
+// isMFARequired tells if the user must enable two-factor authentication.
+func (s userService) isMFARequired(user model.User) bool {
+	return s.mfaRequiredForAdmins && user.HasRole(model.RoleAdmin)
+}
+
+// newMFAChallenge returns new challenge of a user whose password was checked.
+func (s userService) newMFAChallenge(user model.User) (schema.MFAChallenge, error) {
+	token, err := randomString(32, base64.RawURLEncoding.EncodeToString)
+	if err != nil {
+		return schema.MFAChallenge{}, err
+	}
+
+	challenge := model.MFAChallenge{
+		ID:        hashToken(token),
+		UserID:    user.ID,
+		ExpiresAt: time.Now().Add(mfaChallengeExpiry),
+	}
+	if err = s.mfaRepo.CreateChallenge(&challenge); err != nil {
+		return schema.MFAChallenge{}, err
+	}
+
+	return schema.MFAChallenge{
+		ChallengeToken: token,
+		ExpiresAt:      challenge.ExpiresAt,
+		ExpiresIn:      int(mfaChallengeExpiry.Seconds()),
+	}, nil
+}
+
+// verifySecondFactor tells if a code is a TOTP code of the user, or else one of its unused recovery codes.
+func (s userService) verifySecondFactor(user model.User, code string) (bool, error) {
+	ok, err := s.verifyTOTP(user, code)
+	if ok || err != nil {
+		return ok, err
+	}
+
+	recoveryCode := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	if len(recoveryCode) != 2*recoveryCodeBytes {
+		return false, nil
+	}
+	ok, err = s.mfaRepo.UseRecoveryCode(user.ID, hashToken(recoveryCode))
+	if ok {
+		log.Printf("User %d used a recovery code", user.ID)
+	}
+	return ok, err
+}
+
+// verifyTOTP tells if a code is a TOTP code of the user for the current time step or
+// an adjacent one. A code is accepted once, as well as the codes of the previous steps.
+func (s userService) verifyTOTP(user model.User, code string) (bool, error) {
+	code = strings.TrimSpace(code)
+	if user.TOTPSecret == "" || len(code) != int(otp.DigitsSix) {
+		return false, nil
+	}
+
+	now := time.Now()
+	step := now.Unix() / totpPeriod
+	for skew := int64(-1); skew <= 1; skew++ {
+		if step+skew <= user.TOTPLastStep {
+			continue
+		}
+		expected, err := totp.GenerateCodeCustom(user.TOTPSecret, now.Add(time.Duration(skew*totpPeriod)*time.Second), totpOpts)
+		if err != nil {
+			return false, err
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return s.repo.AcceptTOTPStep(user.ID, step+skew)
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	_ "embed"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/denisyao1/welsh-academy-api/exception"
+	"golang.org/x/crypto/bcrypt"
+)
+
+//go:embed passwords/breached.txt
+var breachedPasswords string
+
+// bcrypt ignores the bytes of passwords after the 72nd
+const maxPasswordBytes = 72
+
+// PasswordPolicy checks the strength of new passwords and hashes them.
+type PasswordPolicy interface {
+	// Check returns the validation errors of the password field, one per broken rule.
+	Check(username string, password string) []error
+
+	// Hash returns the bcrypt hash of a password with the configured cost.
+	Hash(password string) (string, error)
+
+	// NeedsRehash returns true if a hash doesn't have the configured cost.
+	NeedsRehash(hash string) bool
+
+	// Verify returns true if a password matches a hash. It takes as long without
+	// hash, so that unknown usernames can't be told from wrong passwords.
+	Verify(hash string, password string) bool
+}
+
+type passwordPolicy struct {
+	minLength      int
+	minCharClasses int
+	cost           int
+	breached       map[string]bool
+	dummyHash      []byte // compared when there is no hash
+}
+
+// NewPasswordPolicy creates new PasswordPolicy requiring passwords of at least
+// minLength characters, with minCharClasses of lowercase letters, uppercase
+// letters, digits and symbols, and hashing them with the bcrypt cost.
+//
+// Passwords of the bundled list of breached passwords are rejected.
+func NewPasswordPolicy(minLength int, minCharClasses int, cost int) (PasswordPolicy, error) {
+	if minLength < 1 {
+		return nil, errors.New("the minimum password length must be at least 1")
+	}
+	if minCharClasses < 0 || minCharClasses > 4 {
+		return nil, errors.New("the number of character classes of passwords must be between 0 and 4")
+	}
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		return nil, fmt.Errorf("the bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+	}
+
+	p := passwordPolicy{minLength: minLength, minCharClasses: minCharClasses, cost: cost, breached: make(map[string]bool)}
+	for _, line := range strings.Split(breachedPasswords, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			p.breached[strings.ToLower(line)] = true
+		}
+	}
+
+	dummy, err := randomString(temporaryPasswordBytes, base64.RawURLEncoding.EncodeToString)
+	if err != nil {
+		return nil, err
+	}
+	if p.dummyHash, err = bcrypt.GenerateFromPassword([]byte(dummy), cost); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+func (p passwordPolicy) Check(username string, password string) []error {
+	var errs []error
+	newErr := func(code exception.Code, args ...any) {
+		errs = append(errs, exception.NewErrValidation("password", code, args...))
+	}
+
+	if utf8.RuneCountInString(password) < p.minLength {
+		newErr(exception.CodeTooShort, p.minLength)
+	}
+	if len(password) > maxPasswordBytes {
+		newErr(exception.CodePasswordTooLong, maxPasswordBytes)
+	}
+	if charClasses(password) < p.minCharClasses {
+		newErr(exception.CodePasswordCharClasses, p.minCharClasses)
+	}
+	if strings.EqualFold(strings.TrimSpace(password), strings.TrimSpace(username)) {
+		newErr(exception.CodePasswordIsUsername)
+	}
+	if p.breached[strings.ToLower(password)] {
+		newErr(exception.CodePasswordBreached)
+	}
+
+	return errs
+}
+
+func (p passwordPolicy) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), p.cost)
+	return string(hash), err
+}
+
+func (p passwordPolicy) NeedsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err == nil && cost != p.cost
+}
+
+func (p passwordPolicy) Verify(hash string, password string) bool {
+	if hash == "" {
+		bcrypt.CompareHashAndPassword(p.dummyHash, []byte(password))
+		return false
+	}
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// charClasses returns the number of classes of characters among lowercase
+// letters, uppercase letters, digits and symbols in a password.
+func charClasses(password string) int {
+	var lower, upper, digit, symbol int
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = 1
+		case unicode.IsUpper(r):
+			upper = 1
+		case unicode.IsDigit(r):
+			digit = 1
+		default:
+			symbol = 1
+		}
+	}
+	return lower + upper + digit + symbol
+}
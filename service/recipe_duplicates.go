@@ -0,0 +1,181 @@
+package service
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/denisyao1/welsh-academy-api/model"
+	"github.com/denisyao1/welsh-academy-api/schema"
+)
+
+const (
+	// minimum name similarity for two recipes to be duplicates
+	duplicateNameSimilarity = 0.5
+
+	// minimum ingredient similarity for two recipes with different names to be duplicates
+	duplicateIngredientSimilarity = 0.75
+)
+
+// words left out when comparing recipe names
+var duplicateNameStopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "the": true, "of": true, "with": true,
+	"classic": true, "traditional": true, "easy": true, "simple": true, "quick": true,
+	"best": true, "homemade": true, "recipe": true,
+}
+
+func (s recipeService) FindDuplicates(recipe model.Recipe) ([]schema.DuplicateCandidate, error) {
+	recipes, err := s.recipeRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := []schema.DuplicateCandidate{}
+	for _, other := range recipes {
+		if other.ID == recipe.ID {
+			continue
+		}
+		if candidate, ok := compareDuplicates(recipe, other); ok {
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.NameSimilarity+a.IngredientSimilarity != b.NameSimilarity+b.IngredientSimilarity {
+			return a.NameSimilarity+a.IngredientSimilarity > b.NameSimilarity+b.IngredientSimilarity
+		}
+		return a.Recipe.ID < b.Recipe.ID
+	})
+	return candidates, nil
+}
+
+func (s recipeService) FindDuplicateClusters() ([]schema.DuplicateCluster, error) {
+	recipes, err := s.recipeRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(recipes, func(i, j int) bool { return recipes[i].ID < recipes[j].ID })
+
+	// only compare recipes sharing a name word
+	byWord := make(map[string][]int)
+	for i, recipe := range recipes {
+		for word := range nameWords(recipe.Name) {
+			byWord[word] = append(byWord[word], i)
+		}
+	}
+
+	// group duplicates with a union-find
+	parents := make([]int, len(recipes))
+	for i := range parents {
+		parents[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parents[i] != i {
+			parents[i] = find(parents[i])
+		}
+		return parents[i]
+	}
+
+	for i, recipe := range recipes {
+		compared := make(map[int]bool)
+		for word := range nameWords(recipe.Name) {
+			for _, j := range byWord[word] {
+				if j <= i || compared[j] {
+					continue
+				}
+				compared[j] = true
+				if _, ok := compareDuplicates(recipe, recipes[j]); ok {
+					parents[find(j)] = find(i)
+				}
+			}
+		}
+	}
+
+	members := make(map[int][]model.Recipe)
+	var roots []int
+	for i, recipe := range recipes {
+		root := find(i)
+		if _, ok := members[root]; !ok {
+			roots = append(roots, root)
+		}
+		members[root] = append(members[root], recipe)
+	}
+
+	clusters := []schema.DuplicateCluster{}
+	for _, root := range roots {
+		if len(members[root]) > 1 {
+			clusters = append(clusters, schema.DuplicateCluster{Count: len(members[root]), Recipes: members[root]})
+		}
+	}
+	return clusters, nil
+}
+
+// compareDuplicates returns true if other is likely a duplicate of recipe.
+func compareDuplicates(recipe, other model.Recipe) (schema.DuplicateCandidate, bool) {
+	candidate := schema.DuplicateCandidate{
+		Recipe:               other,
+		NameSimilarity:       jaccard(nameWords(recipe.Name), nameWords(other.Name)),
+		IngredientSimilarity: jaccard(ingredientNames(recipe), ingredientNames(other)),
+	}
+
+	ok := candidate.NameSimilarity == 1 ||
+		(candidate.NameSimilarity >= duplicateNameSimilarity &&
+			candidate.IngredientSimilarity >= duplicateIngredientSimilarity)
+	return candidate, ok
+}
+
+// nameWords returns the meaningful words of a recipe name,
+// ignoring case, punctuation and parenthesized text.
+func nameWords(name string) map[string]bool {
+	var b strings.Builder
+	depth := 0
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r == '(' || r == '[':
+			depth++
+		case r == ')' || r == ']':
+			if depth > 0 {
+				depth--
+			}
+		case depth > 0:
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+		default:
+			b.WriteRune(' ')
+		}
+	}
+
+	words := make(map[string]bool)
+	for _, word := range strings.Fields(b.String()) {
+		if !duplicateNameStopWords[word] {
+			words[word] = true
+		}
+	}
+	return words
+}
+
+func ingredientNames(recipe model.Recipe) map[string]bool {
+	names := make(map[string]bool)
+	for _, ingredient := range recipe.Ingredients {
+		names[ingredient.Name] = true
+	}
+	return names
+}
+
+// jaccard returns the Jaccard index of two sets, 0 if both are empty.
+func jaccard(a, b map[string]bool) float64 {
+	var shared int
+	for elm := range a {
+		if b[elm] {
+			shared++
+		}
+	}
+	union := len(a) + len(b) - shared
+	if union == 0 {
+		return 0
+	}
+	return math.Round(float64(shared)/float64(union)*1000) / 1000
+}
@@ -0,0 +1,397 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/denisyao1/welsh-academy-api/exception"
+	"github.com/denisyao1/welsh-academy-api/model"
+	"github.com/denisyao1/welsh-academy-api/schema"
+	"github.com/denisyao1/welsh-academy-api/util"
+)
+
+var (
+	ldScriptRegex = regexp.MustCompile(`(?is)<script[^>]*type=["']?application/ld\+json["']?[^>]*>(.*?)</script>`)
+	htmlTagRegex  = regexp.MustCompile(`<[^>]*>`)
+	bracketsRegex = regexp.MustCompile(`\([^)]*\)`)
+	quantityRegex = regexp.MustCompile(`^[\d½¼¾⅓⅔⅛/.,\-–x×]+[[:alpha:]]*\.?$`)
+)
+
+// units and filler words dropped from the beginning of an ingredient line.
+var ingredientLineStopWords = map[string]bool{
+	"g": true, "gr": true, "gram": true, "grams": true, "kg": true,
+	"ml": true, "cl": true, "dl": true, "l": true, "litre": true, "litres": true, "liter": true, "liters": true,
+	"oz": true, "lb": true, "lbs": true, "pint": true, "pints": true,
+	"tsp": true, "teaspoon": true, "teaspoons": true, "tbsp": true, "tablespoon": true, "tablespoons": true,
+	"cup": true, "cups": true, "pinch": true, "dash": true, "knob": true, "handful": true,
+	"slice": true, "slices": true, "clove": true, "cloves": true,
+	"large": true, "medium": true, "small": true, "a": true, "an": true, "of": true,
+}
+
+func (s recipeService) ToJSONLD(recipe model.Recipe) schema.RecipeJSONLD {
+	ld := schema.RecipeJSONLD{
+		Context:            "https://schema.org",
+		Type:               "Recipe",
+		Identifier:         recipe.ID,
+		Name:               recipe.Name,
+		RecipeYield:        recipe.Yield,
+		RecipeIngredient:   []string{},
+		RecipeInstructions: []schema.HowToStep{},
+	}
+
+	if recipe.PrepTime > 0 {
+		ld.PrepTime = util.FormatISODuration(recipe.PrepTime)
+	}
+	if recipe.CookTime > 0 {
+		ld.CookTime = util.FormatISODuration(recipe.CookTime)
+	}
+	if total := recipe.PrepTime + recipe.CookTime; total > 0 {
+		ld.TotalTime = util.FormatISODuration(total)
+	}
+
+	for _, ingredient := range recipe.Ingredients {
+		ld.RecipeIngredient = append(ld.RecipeIngredient, ingredient.Name)
+	}
+
+	for _, line := range strings.Split(recipe.Making, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			ld.RecipeInstructions = append(ld.RecipeInstructions, schema.HowToStep{Type: "HowToStep", Text: line})
+		}
+	}
+
+	return ld
+}
+
+func (s recipeService) ImportJSONLD(data []byte) (schema.RecipeImportResponse, error) {
+	response := schema.RecipeImportResponse{Recipes: []model.Recipe{}}
+
+	var nodes []map[string]any
+	for _, doc := range extractJSONLD(data) {
+		var v any
+		if err := json.Unmarshal(doc, &v); err != nil {
+			return response, exception.NewErrValidation("document", exception.CodeInvalidJSONLD)
+		}
+		nodes = append(nodes, findRecipeNodes(v)...)
+	}
+
+	if len(nodes) == 0 {
+		return response, exception.NewErrValidation("document", exception.CodeNoRecipeInDocument)
+	}
+
+	for i, node := range nodes {
+		recipe, errs := fromJSONLD(node)
+		if len(errs) == 0 {
+			recipe, errs = s.importRecipe(recipe)
+		}
+
+		if len(errs) == 0 {
+			response.Recipes = append(response.Recipes, recipe)
+			continue
+		}
+
+		// errors other than validation ones are unexpected
+		for _, err := range errs {
+			if _, ok := err.(exception.ErrValidation); !ok {
+				return response, err
+			}
+		}
+		response.Errors = append(response.Errors, schema.RecipeImportError{Index: i, Name: recipe.Name, Errors: errs})
+	}
+
+	response.Count = len(response.Recipes)
+	return response, nil
+}
+
+// importRecipe matches the ingredients of a recipe mapped from JSON-LD and saves it.
+// The missing ingredients are created with the recipe, once it is known to be valid.
+func (s recipeService) importRecipe(recipe model.Recipe) (model.Recipe, []error) {
+	var newErr = exception.NewErrValidation
+
+	if errs := schema.Validate(newRecipeInput(recipe)); errs != nil {
+		return recipe, errs
+	}
+
+	ok, err := s.recipeRepo.IsNotCreated(recipe)
+	if err != nil {
+		return recipe, []error{err}
+	}
+	if !ok {
+		return recipe, []error{newErr("name", exception.CodeRecipeExists, recipe.Name)}
+	}
+
+	if errs := s.matchIngredients(&recipe); errs != nil {
+		return recipe, errs
+	}
+	normalizeTags(&recipe)
+
+	if err = s.Create(&recipe); err != nil {
+		return recipe, []error{err}
+	}
+	return recipe, nil
+}
+
+// matchIngredients replaces the recipe ingredient lines by existing ingredients,
+// or by new ones without ID for the missing ones.
+func (s recipeService) matchIngredients(recipe *model.Recipe) []error {
+	existing, err := s.ingredientRepo.FindAll()
+	if err != nil {
+		return []error{err}
+	}
+
+	byName := make(map[string]model.Ingredient)
+	for _, ingredient := range existing {
+		byName[util.NameKey(ingredient.Name)] = ingredient
+	}
+
+	var ingredients []model.Ingredient
+	var errs []error
+	for _, line := range recipe.Ingredients {
+		name := ingredientNameFromLine(line.Name)
+		ingredient, found := matchIngredient(name, byName)
+
+		if !found {
+			ingredient = model.Ingredient{Name: name}
+			ok, err := s.ingredientRepo.IsNotCreated(ingredient)
+			if err != nil {
+				return []error{err}
+			}
+			if !ok {
+				errs = append(errs, exception.NewErrValidation("ingredients", exception.CodeUnknownIngredient, name))
+				continue
+			}
+			byName[util.NameKey(ingredient.Name)] = ingredient
+		}
+
+		// several lines can refer to the same ingredient
+		if !util.Contains(ingredient, ingredients) {
+			ingredients = append(ingredients, ingredient)
+		}
+	}
+
+	recipe.Ingredients = ingredients
+	return errs
+}
+
+// matchIngredient finds the ingredient a name refers to, either by its exact name
+// or by the longest ingredient name it contains as whole words, the first in
+// alphabetical order among names of the same length.
+func matchIngredient(name string, byName map[string]model.Ingredient) (model.Ingredient, bool) {
+	nameKey := util.NameKey(name)
+	if ingredient, ok := byName[nameKey]; ok {
+		return ingredient, true
+	}
+
+	var bestKey string
+	words := " " + nameKey + " "
+	for key := range byName {
+		if !strings.Contains(words, " "+key+" ") {
+			continue
+		}
+		if len(key) > len(bestKey) || (len(key) == len(bestKey) && key < bestKey) {
+			bestKey = key
+		}
+	}
+	if bestKey == "" {
+		return model.Ingredient{}, false
+	}
+	return byName[bestKey], true
+}
+
+// ingredientNameFromLine strips quantities, units and preparation notes
+// from an ingredient line like "200g mature cheddar, grated".
+func ingredientNameFromLine(line string) string {
+	line = strings.TrimSpace(line)
+	name := bracketsRegex.ReplaceAllString(line, " ")
+	name, _, _ = strings.Cut(name, ",")
+
+	words := strings.Fields(name)
+	for len(words) > 0 {
+		word := strings.ToLower(words[0])
+		if !quantityRegex.MatchString(word) && !ingredientLineStopWords[word] {
+			break
+		}
+		words = words[1:]
+	}
+
+	if len(words) == 0 {
+		return line
+	}
+	return strings.Join(words, " ")
+}
+
+// extractJSONLD returns the JSON-LD documents contained in data,
+// which is either a JSON-LD document or an HTML page embedding some.
+func extractJSONLD(data []byte) [][]byte {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || data[0] != '<' {
+		return [][]byte{data}
+	}
+
+	var docs [][]byte
+	for _, match := range ldScriptRegex.FindAllSubmatch(data, -1) {
+		docs = append(docs, bytes.TrimSpace(match[1]))
+	}
+	return docs
+}
+
+// findRecipeNodes returns the schema.org Recipe nodes of a decoded JSON-LD document.
+func findRecipeNodes(v any) []map[string]any {
+	var nodes []map[string]any
+
+	switch value := v.(type) {
+	case []any:
+		for _, elm := range value {
+			nodes = append(nodes, findRecipeNodes(elm)...)
+		}
+	case map[string]any:
+		if hasRecipeType(value["@type"]) {
+			return []map[string]any{value}
+		}
+		if graph, ok := value["@graph"]; ok {
+			nodes = append(nodes, findRecipeNodes(graph)...)
+		}
+	}
+	return nodes
+}
+
+func hasRecipeType(v any) bool {
+	switch value := v.(type) {
+	case string:
+		return value == "Recipe" || strings.HasSuffix(value, "/Recipe") || strings.HasSuffix(value, ":Recipe")
+	case []any:
+		for _, elm := range value {
+			if hasRecipeType(elm) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// fromJSONLD maps a schema.org Recipe node to a recipe. The recipe ingredients
+// only carry the raw ingredient lines.
+func fromJSONLD(node map[string]any) (model.Recipe, []error) {
+	var newErr = exception.NewErrValidation
+	var errs []error
+
+	recipe := model.Recipe{
+		Name:   ldText(node["name"]),
+		Making: strings.Join(ldInstructions(node["recipeInstructions"]), "\n"),
+		Yield:  ldYield(node["recipeYield"]),
+	}
+
+	for _, line := range ldTexts(node["recipeIngredient"]) {
+		recipe.Ingredients = append(recipe.Ingredients, model.Ingredient{Name: line})
+	}
+
+	times := []struct {
+		property string
+		minutes  *int
+	}{
+		{property: "prepTime", minutes: &recipe.PrepTime},
+		{property: "cookTime", minutes: &recipe.CookTime},
+	}
+	for _, t := range times {
+		value := ldText(node[t.property])
+		if value == "" {
+			continue
+		}
+		minutes, err := util.ParseISODuration(value)
+		if err != nil {
+			errs = append(errs, newErr(t.property, exception.CodeInvalidDuration, value))
+			continue
+		}
+		*t.minutes = minutes
+	}
+
+	// keep the total time when only it is given
+	if total := ldText(node["totalTime"]); total != "" && recipe.CookTime == 0 {
+		minutes, err := util.ParseISODuration(total)
+		if err != nil {
+			errs = append(errs, newErr("totalTime", exception.CodeInvalidDuration, total))
+		} else if minutes > recipe.PrepTime {
+			recipe.CookTime = minutes - recipe.PrepTime
+		}
+	}
+
+	return recipe, errs
+}
+
+// ldText returns the text of a JSON-LD value.
+func ldText(v any) string {
+	switch value := v.(type) {
+	case string:
+		return strings.TrimSpace(html.UnescapeString(htmlTagRegex.ReplaceAllString(value, " ")))
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case []any:
+		if len(value) > 0 {
+			return ldText(value[0])
+		}
+	case map[string]any:
+		for _, key := range []string{"text", "name", "@value"} {
+			if text := ldText(value[key]); text != "" {
+				return text
+			}
+		}
+	}
+	return ""
+}
+
+// ldTexts returns the non empty texts of a JSON-LD value or list of values.
+func ldTexts(v any) []string {
+	values, ok := v.([]any)
+	if !ok {
+		values = []any{v}
+	}
+
+	var texts []string
+	for _, value := range values {
+		if text := ldText(value); text != "" {
+			texts = append(texts, text)
+		}
+	}
+	return texts
+}
+
+// ldYield returns the most descriptive of the yields, like "4 servings" over "4".
+func ldYield(v any) string {
+	var yield string
+	for _, text := range ldTexts(v) {
+		if len(text) > len(yield) {
+			yield = text
+		}
+	}
+	return yield
+}
+
+// ldInstructions flattens recipe instructions given as text, HowToStep
+// or HowToSection into a list of steps.
+func ldInstructions(v any) []string {
+	var steps []string
+
+	switch value := v.(type) {
+	case string:
+		for _, line := range strings.Split(value, "\n") {
+			if line = ldText(line); line != "" {
+				steps = append(steps, line)
+			}
+		}
+	case []any:
+		for _, elm := range value {
+			steps = append(steps, ldInstructions(elm)...)
+		}
+	case map[string]any:
+		if items, ok := value["itemListElement"]; ok {
+			return ldInstructions(items)
+		}
+		if text := ldText(value); text != "" {
+			steps = append(steps, text)
+		}
+	}
+	return steps
+}
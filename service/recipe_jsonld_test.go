@@ -0,0 +1,42 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/denisyao1/welsh-academy-api/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchIngredient(t *testing.T) {
+	t.Parallel()
+
+	assert := assert.New(t)
+
+	byName := map[string]model.Ingredient{
+		"cheddar":        {BaseModel: model.BaseModel{ID: 1}, Name: "Cheddar"},
+		"mature cheddar": {BaseModel: model.BaseModel{ID: 2}, Name: "Mature  Cheddar"},
+		"egg":            {BaseModel: model.BaseModel{ID: 3}, Name: "Egg"},
+		"ham":            {BaseModel: model.BaseModel{ID: 4}, Name: "Ham"},
+	}
+
+	testCases := []struct {
+		name        string
+		found       bool
+		ingredient  int
+		description string
+	}{
+		{"Cheddar", true, 1, "exact name should match"},
+		{"grated mature cheddar", true, 2, "longest contained name should match"},
+		{"ham and egg", true, 3, "names of the same length should match in alphabetical order"},
+		{"eggs", false, 0, "partial words shouldn't match"},
+	}
+
+	for _, tt := range testCases {
+		// the map order changes between iterations
+		for i := 0; i < 20; i++ {
+			ingredient, found := matchIngredient(tt.name, byName)
+			assert.Equal(tt.found, found, tt.description)
+			assert.Equal(tt.ingredient, ingredient.ID, tt.description)
+		}
+	}
+}
@@ -1,17 +1,17 @@
 package service
 
 import (
-	"fmt"
-
 	"github.com/denisyao1/welsh-academy-api/exception"
 	"github.com/denisyao1/welsh-academy-api/model"
 	"github.com/denisyao1/welsh-academy-api/repository"
+	"github.com/denisyao1/welsh-academy-api/schema"
 	"github.com/denisyao1/welsh-academy-api/util"
 )
 
 type RecipeService interface {
-	// Validate validates user inputs.
-	Validate(recipe *model.Recipe) []error
+	// Validate converts user inputs checked with schema.Validate to a recipe,
+	// checking that its ingredients exist.
+	Validate(input schema.Recipe) (model.Recipe, []error)
 
 	// Create add new recipe to the database.
 	Create(recipe *model.Recipe) error
@@ -28,6 +28,39 @@ type RecipeService interface {
 
 	// FindUserFavorites lists user favorite recipes.
 	FindUserFavorites(userID int) ([]model.Recipe, error)
+
+	// GetByID returns a recipe with its ingredients.
+	//
+	// It returns exception.ErrRecordNotFound if the recipe doesn't exist.
+	GetByID(recipeID int) (model.Recipe, error)
+
+	// ToJSONLD converts a recipe to a schema.org Recipe.
+	ToJSONLD(recipe model.Recipe) schema.RecipeJSONLD
+
+	// ImportJSONLD creates the schema.org Recipes of a JSON-LD document or
+	// of an HTML page embedding JSON-LD. Missing ingredients are created.
+	//
+	// It returns exception.ErrValidation if the document contains no recipe;
+	// recipes that can't be created are reported in the response.
+	ImportJSONLD(data []byte) (schema.RecipeImportResponse, error)
+
+	// Delete moves a recipe to the trash.
+	//
+	// It returns exception.ErrRecordNotFound if the recipe doesn't exist.
+	Delete(recipeID int) error
+
+	// FindSimilar returns at most limit recipes sharing ingredients or tags
+	// with a recipe, most similar first.
+	//
+	// It returns exception.ErrRecordNotFound if the recipe doesn't exist.
+	FindSimilar(recipeID int, limit int) ([]schema.SimilarRecipe, error)
+
+	// FindDuplicates returns the recipes likely to be duplicates of a recipe,
+	// having a close name and ingredients, most likely first.
+	FindDuplicates(recipe model.Recipe) ([]schema.DuplicateCandidate, error)
+
+	// FindDuplicateClusters returns the groups of likely duplicate recipes.
+	FindDuplicateClusters() ([]schema.DuplicateCluster, error)
 }
 
 type recipeService struct {
@@ -40,45 +73,28 @@ func NewRecipeService(recipeRepo repository.RecipeRepository, ingredientRepo rep
 	return &recipeService{recipeRepo: recipeRepo, ingredientRepo: ingredientRepo}
 }
 
-func (s recipeService) Validate(recipe *model.Recipe) []error {
-	var newErrValidation = exception.NewErrValidation
-	var errs []error
-
-	// recipe name must be non null
-	if recipe.Name == "" {
-		errs = append(errs, newErrValidation("name", "the name is required"))
+func (s recipeService) Validate(input schema.Recipe) (model.Recipe, []error) {
+	recipe := model.Recipe{
+		Name:     input.Name,
+		Making:   input.Making,
+		Yield:    input.Yield,
+		PrepTime: input.PrepTime,
+		CookTime: input.CookTime,
 	}
-
-	// recipe making must not be empty
-
-	if recipe.Making == "" {
-		errs = append(errs, newErrValidation("making", "the making is required"))
+	for _, ingredient := range input.Ingredients {
+		recipe.Ingredients = append(recipe.Ingredients, model.Ingredient{Name: ingredient.Name})
 	}
-
-	// recipe ingredients slice must contains at least one element
-	if len(recipe.Ingredients) == 0 {
-		errs = append(errs, newErrValidation("ingredients", "recipe must contains a least one ingredient"))
+	for _, tag := range input.Tags {
+		recipe.Tags = append(recipe.Tags, model.Tag{Name: tag.Name})
 	}
 
-	// recipe ingredients slice  must not contains duplicate
-	noDuplicate := util.SliceHasNoDuplicate(recipe.Ingredients)
-	if !noDuplicate {
-		errs = append(errs, newErrValidation("ingredients", "recipe ingredients contains duplicate"))
-	}
-
-	if len(errs) != 0 {
-		return errs
-	}
-
-	return s.transform(recipe)
+	return recipe, s.transform(&recipe)
 }
 
 func (s recipeService) transform(recipe *model.Recipe) []error {
-	var names []string
+	normalizeTags(recipe)
 
-	for _, i := range recipe.Ingredients {
-		names = append(names, i.Name)
-	}
+	names := ingredientNamesOf(recipe.Ingredients)
 
 	dbIngredients, err := s.ingredientRepo.FindNamed(names)
 	if err != nil {
@@ -91,21 +107,24 @@ func (s recipeService) transform(recipe *model.Recipe) []error {
 	}
 
 	var errs []error
-	var dbNames []string
 	var newErr = exception.NewErrValidation
-
-	for _, elm := range dbIngredients {
-		dbNames = append(dbNames, elm.Name)
-	}
+	dbKeys := util.NameKeys(ingredientNamesOf(dbIngredients))
 
 	for _, name := range names {
-		if !util.Contains(name, dbNames) {
-			errs = append(errs, newErr("ingredients", fmt.Sprintf("'%s' is not a valid ingredient", name)))
+		if !util.Contains(util.NameKey(name), dbKeys) {
+			errs = append(errs, newErr("ingredients", exception.CodeUnknownIngredient, name))
 		}
 	}
 	return errs
 }
 
+// normalizeTags folds the recipe tag names, tags being case insensitive.
+func normalizeTags(recipe *model.Recipe) {
+	for i, tag := range recipe.Tags {
+		recipe.Tags[i] = model.Tag{Name: util.NameKey(tag.Name)}
+	}
+}
+
 func (s recipeService) Create(recipe *model.Recipe) error {
 	ok, err := s.recipeRepo.IsNotCreated(*recipe)
 
@@ -155,3 +174,38 @@ func (s recipeService) AddOrRemoveFavorite(userID int, recipeID int) (string, er
 func (s recipeService) FindUserFavorites(userID int) ([]model.Recipe, error) {
 	return s.recipeRepo.FindFavorites(userID)
 }
+
+func (s recipeService) GetByID(recipeID int) (model.Recipe, error) {
+	return s.recipeRepo.GetByID(recipeID)
+}
+
+func (s recipeService) Delete(recipeID int) error {
+	return s.recipeRepo.Delete(recipeID)
+}
+
+// newRecipeInput returns the user inputs a recipe can be created from.
+func newRecipeInput(recipe model.Recipe) schema.Recipe {
+	input := schema.Recipe{
+		Name:     recipe.Name,
+		Making:   recipe.Making,
+		Yield:    recipe.Yield,
+		PrepTime: recipe.PrepTime,
+		CookTime: recipe.CookTime,
+	}
+	for _, ingredient := range recipe.Ingredients {
+		input.Ingredients = append(input.Ingredients, schema.Ingredient{Name: ingredient.Name})
+	}
+	for _, tag := range recipe.Tags {
+		input.Tags = append(input.Tags, schema.Tag{Name: tag.Name})
+	}
+	return input
+}
+
+// ingredientNamesOf returns the names of ingredients.
+func ingredientNamesOf(ingredients []model.Ingredient) []string {
+	names := make([]string, 0, len(ingredients))
+	for _, ingredient := range ingredients {
+		names = append(names, ingredient.Name)
+	}
+	return names
+}
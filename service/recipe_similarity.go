@@ -0,0 +1,136 @@
+package service
+
+import (
+	"math"
+	"sort"
+
+	"github.com/denisyao1/welsh-academy-api/model"
+	"github.com/denisyao1/welsh-academy-api/schema"
+)
+
+// weight of shared tags in recipe similarity, the rest goes to shared ingredients
+const tagWeight = 0.25
+
+func (s recipeService) FindSimilar(recipeID int, limit int) ([]schema.SimilarRecipe, error) {
+	recipe, err := s.recipeRepo.GetByID(recipeID)
+	if err != nil {
+		return nil, err
+	}
+
+	related, err := s.recipeRepo.FindRelated(recipeID)
+	if err != nil {
+		return nil, err
+	}
+
+	weights, err := s.ingredientWeights(append(related, recipe))
+	if err != nil {
+		return nil, err
+	}
+
+	similarRecipes := []schema.SimilarRecipe{}
+	for _, other := range related {
+		similarRecipes = append(similarRecipes, compareRecipes(recipe, other, weights))
+	}
+
+	sort.Slice(similarRecipes, func(i, j int) bool {
+		if similarRecipes[i].Score != similarRecipes[j].Score {
+			return similarRecipes[i].Score > similarRecipes[j].Score
+		}
+		return similarRecipes[i].Recipe.ID < similarRecipes[j].Recipe.ID
+	})
+	if len(similarRecipes) > limit {
+		similarRecipes = similarRecipes[:limit]
+	}
+	return similarRecipes, nil
+}
+
+// ingredientWeights returns the inverse recipe frequency of the recipes ingredients,
+// so that common ingredients like salt or butter weigh less than rare ones.
+func (s recipeService) ingredientWeights(recipes []model.Recipe) (map[int]float64, error) {
+	var ingredientIDs []int
+	for _, recipe := range recipes {
+		for _, ingredient := range recipe.Ingredients {
+			ingredientIDs = append(ingredientIDs, ingredient.ID)
+		}
+	}
+
+	total, err := s.recipeRepo.Count()
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := s.recipeRepo.CountByIngredient(ingredientIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	weights := make(map[int]float64)
+	for _, ingredientID := range ingredientIDs {
+		count := counts[ingredientID]
+		if count == 0 {
+			count = 1
+		}
+		weights[ingredientID] = math.Log(1 + float64(total)/float64(count))
+	}
+	return weights, nil
+}
+
+// compareRecipes scores the similarity of other to recipe with the weighted
+// Jaccard index of their ingredients and the Jaccard index of their tags.
+func compareRecipes(recipe, other model.Recipe, weights map[int]float64) schema.SimilarRecipe {
+	similar := schema.SimilarRecipe{
+		Recipe:             other,
+		SharedIngredients:  []string{},
+		MissingIngredients: []string{},
+		ExtraIngredients:   []string{},
+		SharedTags:         []string{},
+	}
+
+	otherIngredients := make(map[int]bool)
+	for _, ingredient := range other.Ingredients {
+		otherIngredients[ingredient.ID] = true
+	}
+
+	var shared, union float64
+	recipeIngredients := make(map[int]bool)
+	for _, ingredient := range recipe.Ingredients {
+		recipeIngredients[ingredient.ID] = true
+		union += weights[ingredient.ID]
+		if otherIngredients[ingredient.ID] {
+			shared += weights[ingredient.ID]
+			similar.SharedIngredients = append(similar.SharedIngredients, ingredient.Name)
+		} else {
+			similar.MissingIngredients = append(similar.MissingIngredients, ingredient.Name)
+		}
+	}
+	for _, ingredient := range other.Ingredients {
+		if !recipeIngredients[ingredient.ID] {
+			union += weights[ingredient.ID]
+			similar.ExtraIngredients = append(similar.ExtraIngredients, ingredient.Name)
+		}
+	}
+
+	var score float64
+	if union != 0 {
+		score = shared / union
+	}
+
+	// tags only count when at least one of the recipes has some
+	if len(recipe.Tags) != 0 || len(other.Tags) != 0 {
+		otherTags := make(map[string]bool)
+		for _, tag := range other.Tags {
+			otherTags[tag.Name] = true
+		}
+		for _, tag := range recipe.Tags {
+			if otherTags[tag.Name] {
+				similar.SharedTags = append(similar.SharedTags, tag.Name)
+			}
+		}
+		tagUnion := len(recipe.Tags) + len(other.Tags) - len(similar.SharedTags)
+		tagScore := float64(len(similar.SharedTags)) / float64(tagUnion)
+		score = (1-tagWeight)*score + tagWeight*tagScore
+	}
+
+	similar.Score = math.Round(score*1000) / 1000
+	return similar
+}
@@ -0,0 +1,273 @@
+package service
+
+import (
+	"log"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/denisyao1/welsh-academy-api/model"
+	"github.com/denisyao1/welsh-academy-api/repository"
+	"github.com/denisyao1/welsh-academy-api/schema"
+)
+
+const (
+	// weights of ingredient overlap and co-favoriting in recipe similarity
+	ingredientWeight = 0.5
+	coFavoriteWeight = 0.5
+
+	// number of most similar recipes kept per recipe in the index
+	maxNeighbors = 50
+
+	// number of favorites listed as the reason of a recommendation
+	maxReasons = 3
+)
+
+// RecommendationService suggests recipes to users from their favorites.
+//
+// Suggestions come from an in-memory similarity index instead of the database,
+// so they only take new recipes and favorites into account once it's refreshed.
+type RecommendationService interface {
+	// Refresh rebuilds the similarity index from the database.
+	Refresh() error
+
+	// RefreshEvery rebuilds the similarity index at each interval, forever.
+	RefreshEvery(interval time.Duration)
+
+	// Recommend returns at most limit recipes the user may like, best first.
+	//
+	// Users without favorites get the most favorited recipes.
+	Recommend(userID int, limit int) ([]schema.Recommendation, error)
+}
+
+type recommendationService struct {
+	recipeRepo repository.RecipeRepository
+
+	mu    sync.RWMutex
+	index *similarityIndex
+}
+
+// NewRecommendationService creates new RecommendationService.
+func NewRecommendationService(recipeRepo repository.RecipeRepository) RecommendationService {
+	return &recommendationService{recipeRepo: recipeRepo}
+}
+
+// similarityIndex holds the recipes and their most similar recipes.
+type similarityIndex struct {
+	recipes   map[int]model.Recipe
+	neighbors map[int][]neighbor
+	popular   []int // recipe IDs, most favorited first
+}
+
+type neighbor struct {
+	recipeID   int
+	similarity float64
+}
+
+func (s *recommendationService) Refresh() error {
+	recipes, err := s.recipeRepo.FindAll()
+	if err != nil {
+		return err
+	}
+
+	favorites, err := s.recipeRepo.FindAllFavorites()
+	if err != nil {
+		return err
+	}
+
+	index := newSimilarityIndex(recipes, favorites)
+
+	s.mu.Lock()
+	s.index = index
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *recommendationService) RefreshEvery(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := s.Refresh(); err != nil {
+			log.Println("Failed to refresh recommendations: ", err.Error())
+		}
+	}
+}
+
+func (s *recommendationService) Recommend(userID int, limit int) ([]schema.Recommendation, error) {
+	s.mu.RLock()
+	index := s.index
+	s.mu.RUnlock()
+
+	// build the index on first use
+	if index == nil {
+		if err := s.Refresh(); err != nil {
+			return nil, err
+		}
+		s.mu.RLock()
+		index = s.index
+		s.mu.RUnlock()
+	}
+
+	favoriteIDs, err := s.recipeRepo.FindFavoriteIDs(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return index.recommend(favoriteIDs, limit), nil
+}
+
+func newSimilarityIndex(recipes []model.Recipe, favorites []model.UserFavorite) *similarityIndex {
+	index := &similarityIndex{
+		recipes:   make(map[int]model.Recipe),
+		neighbors: make(map[int][]neighbor),
+	}
+
+	// inverted indexes to only compare recipes sharing an ingredient or a fan
+	recipesByIngredient := make(map[int][]int)
+	for _, recipe := range recipes {
+		index.recipes[recipe.ID] = recipe
+		for _, ingredient := range recipe.Ingredients {
+			recipesByIngredient[ingredient.ID] = append(recipesByIngredient[ingredient.ID], recipe.ID)
+		}
+	}
+
+	usersByRecipe := make(map[int][]int)
+	recipesByUser := make(map[int][]int)
+	for _, favorite := range favorites {
+		if _, ok := index.recipes[favorite.RecipeID]; !ok {
+			continue
+		}
+		usersByRecipe[favorite.RecipeID] = append(usersByRecipe[favorite.RecipeID], favorite.UserID)
+		recipesByUser[favorite.UserID] = append(recipesByUser[favorite.UserID], favorite.RecipeID)
+	}
+
+	for _, recipe := range recipes {
+		sharedIngredients := make(map[int]int)
+		for _, ingredient := range recipe.Ingredients {
+			for _, other := range recipesByIngredient[ingredient.ID] {
+				sharedIngredients[other]++
+			}
+		}
+
+		sharedFans := make(map[int]int)
+		for _, userID := range usersByRecipe[recipe.ID] {
+			for _, other := range recipesByUser[userID] {
+				sharedFans[other]++
+			}
+		}
+
+		candidates := make(map[int]bool)
+		for other := range sharedIngredients {
+			candidates[other] = true
+		}
+		for other := range sharedFans {
+			candidates[other] = true
+		}
+
+		var neighbors []neighbor
+		for other := range candidates {
+			if other == recipe.ID {
+				continue
+			}
+
+			var similarity float64
+			if shared := sharedIngredients[other]; shared != 0 {
+				union := len(recipe.Ingredients) + len(index.recipes[other].Ingredients) - shared
+				similarity += ingredientWeight * float64(shared) / float64(union)
+			}
+			if shared := sharedFans[other]; shared != 0 {
+				fans := len(usersByRecipe[recipe.ID]) * len(usersByRecipe[other])
+				similarity += coFavoriteWeight * float64(shared) / math.Sqrt(float64(fans))
+			}
+			neighbors = append(neighbors, neighbor{recipeID: other, similarity: similarity})
+		}
+
+		sort.Slice(neighbors, func(i, j int) bool {
+			if neighbors[i].similarity != neighbors[j].similarity {
+				return neighbors[i].similarity > neighbors[j].similarity
+			}
+			return neighbors[i].recipeID < neighbors[j].recipeID
+		})
+		if len(neighbors) > maxNeighbors {
+			neighbors = neighbors[:maxNeighbors]
+		}
+		index.neighbors[recipe.ID] = neighbors
+	}
+
+	for recipeID := range usersByRecipe {
+		index.popular = append(index.popular, recipeID)
+	}
+	sort.Slice(index.popular, func(i, j int) bool {
+		a, b := index.popular[i], index.popular[j]
+		if len(usersByRecipe[a]) != len(usersByRecipe[b]) {
+			return len(usersByRecipe[a]) > len(usersByRecipe[b])
+		}
+		return a < b
+	})
+
+	return index
+}
+
+// recommend sums the similarities of the recipes to the favorites,
+// leaving out the favorites themselves.
+func (index *similarityIndex) recommend(favoriteIDs []int, limit int) []schema.Recommendation {
+	recommendations := []schema.Recommendation{}
+
+	favorites := make(map[int]bool)
+	for _, favoriteID := range favoriteIDs {
+		favorites[favoriteID] = true
+	}
+
+	scores := make(map[int]float64)
+	contributions := make(map[int][]neighbor)
+	for _, favoriteID := range favoriteIDs {
+		for _, n := range index.neighbors[favoriteID] {
+			if favorites[n.recipeID] {
+				continue
+			}
+			scores[n.recipeID] += n.similarity
+			contributions[n.recipeID] = append(contributions[n.recipeID], neighbor{recipeID: favoriteID, similarity: n.similarity})
+		}
+	}
+
+	// cold start: suggest what others like
+	if len(scores) == 0 {
+		for _, recipeID := range index.popular {
+			if len(recommendations) == limit {
+				break
+			}
+			if !favorites[recipeID] {
+				recommendations = append(recommendations, schema.Recommendation{Recipe: index.recipes[recipeID]})
+			}
+		}
+		return recommendations
+	}
+
+	for recipeID, score := range scores {
+		recommendation := schema.Recommendation{
+			Recipe: index.recipes[recipeID],
+			Score:  math.Round(score*1000) / 1000,
+		}
+
+		reasons := contributions[recipeID]
+		sort.Slice(reasons, func(i, j int) bool { return reasons[i].similarity > reasons[j].similarity })
+		for i := 0; i < len(reasons) && i < maxReasons; i++ {
+			recommendation.Because = append(recommendation.Because, index.recipes[reasons[i].recipeID].Name)
+		}
+
+		recommendations = append(recommendations, recommendation)
+	}
+
+	sort.Slice(recommendations, func(i, j int) bool {
+		if recommendations[i].Score != recommendations[j].Score {
+			return recommendations[i].Score > recommendations[j].Score
+		}
+		return recommendations[i].Recipe.ID < recommendations[j].Recipe.ID
+	})
+	if len(recommendations) > limit {
+		recommendations = recommendations[:limit]
+	}
+	return recommendations
+}
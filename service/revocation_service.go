@@ -0,0 +1,94 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/denisyao1/welsh-academy-api/model"
+	"github.com/denisyao1/welsh-academy-api/repository"
+)
+
+// time revocations are cached before being reloaded from the database,
+// to take the ones made by other instances of the API into account
+const revocationCacheTTL = 30 * time.Second
+
+// RevocationService rejects access tokens before they expire.
+type RevocationService interface {
+	// Revoke rejects the access tokens with an ID, or of a session, until expiresAt.
+	Revoke(id string, expiresAt time.Time) error
+
+	// IsRevoked returns true if one of the token or session IDs is revoked.
+	IsRevoked(ids ...string) (bool, error)
+}
+
+type revocationService struct {
+	repo repository.RevocationRepository
+
+	mu       sync.RWMutex
+	revoked  map[string]time.Time // expiry of the revocations by ID
+	loadedAt time.Time
+}
+
+// NewRevocationService creates new RevocationService.
+func NewRevocationService(repo repository.RevocationRepository) RevocationService {
+	return &revocationService{repo: repo}
+}
+
+func (s *revocationService) Revoke(id string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.repo.Create(model.Revocation{ID: id, ExpiresAt: expiresAt}); err != nil {
+		return err
+	}
+	if s.revoked != nil {
+		s.revoked[id] = expiresAt
+	}
+	return nil
+}
+
+func (s *revocationService) IsRevoked(ids ...string) (bool, error) {
+	s.mu.RLock()
+	stale := s.revoked == nil || time.Since(s.loadedAt) > revocationCacheTTL
+	s.mu.RUnlock()
+
+	if stale {
+		if err := s.reload(); err != nil {
+			return false, err
+		}
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+	for _, id := range ids {
+		if expiresAt, ok := s.revoked[id]; ok && id != "" && now.Before(expiresAt) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// reload replaces the cache with the revocations of the database.
+func (s *revocationService) reload() error {
+	// revocations made meanwhile must not be lost
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.repo.DeleteExpired(); err != nil {
+		return err
+	}
+
+	revocations, err := s.repo.FindActive()
+	if err != nil {
+		return err
+	}
+
+	s.revoked = make(map[string]time.Time, len(revocations))
+	for _, revocation := range revocations {
+		s.revoked[revocation.ID] = revocation.ExpiresAt
+	}
+	s.loadedAt = time.Now()
+	return nil
+}
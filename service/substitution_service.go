@@ -0,0 +1,206 @@
+package service
+
+import (
+	"github.com/denisyao1/welsh-academy-api/exception"
+	"github.com/denisyao1/welsh-academy-api/model"
+	"github.com/denisyao1/welsh-academy-api/repository"
+	"github.com/denisyao1/welsh-academy-api/schema"
+	"github.com/denisyao1/welsh-academy-api/util"
+)
+
+// SubstitutionService contains business logic to curate and use ingredient substitutions.
+type SubstitutionService interface {
+	// Validate converts user inputs checked with schema.Validate to a substitution,
+	// checking that its ingredients exist.
+	Validate(substitution schema.Substitution) (model.Substitution, []error)
+
+	// Create adds new substitution to the database.
+	//
+	// It returns exception.ErrDuplicateKey if the ingredient already
+	// has a substitution with the same substitutes.
+	Create(substitution *model.Substitution) error
+
+	// ListForIngredient returns the substitutions of an ingredient.
+	//
+	// It returns exception.ErrRecordNotFound if the ingredient doesn't exist.
+	ListForIngredient(ingredientID int) ([]model.Substitution, error)
+
+	// Delete removes a substitution.
+	//
+	// It returns exception.ErrRecordNotFound if the substitution doesn't exist.
+	Delete(substitutionID int) error
+
+	// Expand adds to ingredientNames the ingredients that can be replaced
+	// with them, and returns the substitutions allowing it.
+	Expand(ingredientNames []string) ([]string, []model.Substitution, error)
+}
+
+type substitutionService struct {
+	substitutionRepo repository.SubstitutionRepository
+	ingredientRepo   repository.IngredientRepository
+}
+
+// NewSubstitutionService creates new SubstitutionService.
+func NewSubstitutionService(substitutionRepo repository.SubstitutionRepository,
+	ingredientRepo repository.IngredientRepository) SubstitutionService {
+	return &substitutionService{substitutionRepo: substitutionRepo, ingredientRepo: ingredientRepo}
+}
+
+func (s substitutionService) Validate(substitution schema.Substitution) (model.Substitution, []error) {
+	var newErrValidation = exception.NewErrValidation
+	var errs []error
+
+	names := []string{substitution.Ingredient}
+	keys := []string{util.NameKey(substitution.Ingredient)}
+	for _, substitute := range substitution.Substitutes {
+		key := util.NameKey(substitute.Name)
+		if key == keys[0] {
+			errs = append(errs, newErrValidation("substitutes", exception.CodeSelfSubstitute))
+		}
+		names = append(names, substitute.Name)
+		keys = append(keys, key)
+	}
+
+	if len(errs) != 0 {
+		return model.Substitution{}, errs
+	}
+
+	dbIngredients, err := s.ingredientRepo.FindNamed(names)
+	if err != nil {
+		return model.Substitution{}, []error{err}
+	}
+
+	byKey := make(map[string]model.Ingredient)
+	for _, ingredient := range dbIngredients {
+		byKey[util.NameKey(ingredient.Name)] = ingredient
+	}
+
+	for i, name := range names {
+		if _, ok := byKey[keys[i]]; !ok {
+			field := "substitutes"
+			if i == 0 {
+				field = "ingredient"
+			}
+			errs = append(errs, newErrValidation(field, exception.CodeUnknownIngredient, name))
+		}
+	}
+
+	if len(errs) != 0 {
+		return model.Substitution{}, errs
+	}
+
+	result := model.Substitution{
+		Ingredient:   byKey[keys[0]],
+		IngredientID: byKey[keys[0]].ID,
+		Notes:        substitution.Notes,
+	}
+	for i, substitute := range substitution.Substitutes {
+		ratio := substitute.Ratio
+		if ratio == 0 {
+			ratio = 1
+		}
+		result.Substitutes = append(result.Substitutes, model.Substitute{
+			Ingredient:   byKey[keys[i+1]],
+			IngredientID: byKey[keys[i+1]].ID,
+			Ratio:        ratio,
+		})
+	}
+	return result, nil
+}
+
+func (s substitutionService) Create(substitution *model.Substitution) error {
+	existing, err := s.substitutionRepo.FindForIngredient(substitution.IngredientID)
+	if err != nil {
+		return err
+	}
+
+	for _, other := range existing {
+		if sameSubstitutes(*substitution, other) {
+			return exception.ErrDuplicateKey
+		}
+	}
+
+	return s.substitutionRepo.Create(substitution)
+}
+
+func (s substitutionService) ListForIngredient(ingredientID int) ([]model.Substitution, error) {
+	if _, err := s.ingredientRepo.GetByID(ingredientID); err != nil {
+		return nil, err
+	}
+
+	substitutions, err := s.substitutionRepo.FindForIngredient(ingredientID)
+	if err != nil {
+		return nil, err
+	}
+	return usableSubstitutions(substitutions), nil
+}
+
+func (s substitutionService) Delete(substitutionID int) error {
+	return s.substitutionRepo.Delete(substitutionID)
+}
+
+func (s substitutionService) Expand(ingredientNames []string) ([]string, []model.Substitution, error) {
+	substitutions, err := s.substitutionRepo.FindAll()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keys := util.NameKeys(ingredientNames)
+	expanded := append([]string{}, ingredientNames...)
+	applied := []model.Substitution{}
+	for _, substitution := range usableSubstitutions(substitutions) {
+		if util.Contains(util.NameKey(substitution.Ingredient.Name), keys) {
+			continue
+		}
+
+		available := true
+		for _, substitute := range substitution.Substitutes {
+			if !util.Contains(util.NameKey(substitute.Ingredient.Name), keys) {
+				available = false
+				break
+			}
+		}
+		if !available {
+			continue
+		}
+
+		if !util.Contains(substitution.Ingredient.Name, expanded) {
+			expanded = append(expanded, substitution.Ingredient.Name)
+		}
+		applied = append(applied, substitution)
+	}
+	return expanded, applied, nil
+}
+
+// usableSubstitutions leaves out the substitutions involving ingredients in the trash.
+func usableSubstitutions(substitutions []model.Substitution) []model.Substitution {
+	usable := []model.Substitution{}
+	for _, substitution := range substitutions {
+		ok := substitution.Ingredient.ID != 0
+		for _, substitute := range substitution.Substitutes {
+			ok = ok && substitute.Ingredient.ID != 0
+		}
+		if ok {
+			usable = append(usable, substitution)
+		}
+	}
+	return usable
+}
+
+// sameSubstitutes returns true if both substitutions use the same ingredients.
+func sameSubstitutes(a, b model.Substitution) bool {
+	if len(a.Substitutes) != len(b.Substitutes) {
+		return false
+	}
+
+	var ids []int
+	for _, substitute := range b.Substitutes {
+		ids = append(ids, substitute.IngredientID)
+	}
+	for _, substitute := range a.Substitutes {
+		if !util.Contains(substitute.IngredientID, ids) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,304 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/denisyao1/welsh-academy-api/exception"
+	"github.com/denisyao1/welsh-academy-api/model"
+	"github.com/denisyao1/welsh-academy-api/schema"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func (s userService) CreateTokens(loginSchema schema.Login, userAgent string, ip string) (schema.Token, *schema.MFAChallenge, error) {
+	// failed logins make the next ones wait, whether the user exists or not
+	if err := s.throttle.Check(loginSchema.Username, ip); err != nil {
+		return schema.Token{}, nil, err
+	}
+
+	// validate user credentials
+	user, err := s.validateCredentials(loginSchema)
+	if errors.Is(err, exception.ErrInvalidCredentials) {
+		if failErr := s.throttle.Fail(loginSchema.Username, ip); failErr != nil {
+			return schema.Token{}, nil, failErr
+		}
+		return schema.Token{}, nil, err
+	}
+	if err != nil {
+		s.throttle.Release(loginSchema.Username, ip)
+		return schema.Token{}, nil, err
+	}
+
+	// the failures are forgotten once the code is checked too
+	if user.TOTPEnabled {
+		s.throttle.Release(loginSchema.Username, ip)
+		challenge, err := s.newMFAChallenge(user)
+		if err != nil {
+			return schema.Token{}, nil, err
+		}
+		return schema.Token{}, &challenge, nil
+	}
+
+	if err = s.throttle.Succeed(loginSchema.Username, ip); err != nil {
+		return schema.Token{}, nil, err
+	}
+
+	token, err := s.openSession(user, userAgent, ip)
+	return token, nil, err
+}
+
+// openSession opens a session of an authenticated user from a device, returning its tokens.
+func (s userService) openSession(user model.User, userAgent string, ip string) (schema.Token, error) {
+	sessionID, err := randomString(16, hex.EncodeToString)
+	if err != nil {
+		return schema.Token{}, err
+	}
+
+	refreshToken, record, err := s.newRefreshToken(user.ID, sessionID)
+	if err != nil {
+		return schema.Token{}, err
+	}
+
+	session := model.Session{
+		ID:         sessionID,
+		UserID:     user.ID,
+		UserAgent:  userAgent,
+		IP:         ip,
+		LastUsedAt: time.Now(),
+		ExpiresAt:  record.ExpiresAt,
+	}
+	if err = s.sessionRepo.Create(&session); err != nil {
+		return schema.Token{}, err
+	}
+	if err = s.tokenRepo.Create(&record); err != nil {
+		return schema.Token{}, err
+	}
+
+	return s.newToken(user, refreshToken, record)
+}
+
+func (s userService) RefreshTokens(refreshToken string, ip string) (schema.Token, error) {
+	stored, err := s.tokenRepo.GetByHash(hashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, exception.ErrRecordNotFound) {
+			return schema.Token{}, exception.ErrInvalidRefreshToken
+		}
+		return schema.Token{}, err
+	}
+
+	if stored.RevokedAt != nil || time.Now().After(stored.ExpiresAt) {
+		return schema.Token{}, exception.ErrInvalidRefreshToken
+	}
+	if stored.UsedAt != nil {
+		return schema.Token{}, s.revokeReusedSession(stored)
+	}
+
+	user := model.User{ID: stored.UserID}
+	if err = s.repo.GetByID(&user); err != nil {
+		if errors.Is(err, exception.ErrRecordNotFound) {
+			return schema.Token{}, exception.ErrInvalidRefreshToken
+		}
+		return schema.Token{}, err
+	}
+	if user.DisabledAt != nil {
+		return schema.Token{}, exception.ErrInvalidRefreshToken
+	}
+
+	newRefreshToken, record, err := s.newRefreshToken(user.ID, stored.Family)
+	if err != nil {
+		return schema.Token{}, err
+	}
+
+	rotated, err := s.tokenRepo.Rotate(stored.ID, &record)
+	if err != nil {
+		return schema.Token{}, err
+	}
+	// the token was used meanwhile
+	if !rotated {
+		return schema.Token{}, s.revokeReusedSession(stored)
+	}
+
+	if err = s.sessionRepo.Touch(stored.Family, ip, record.ExpiresAt); err != nil {
+		return schema.Token{}, err
+	}
+
+	return s.newToken(user, newRefreshToken, record)
+}
+
+// renewTokens replaces the refresh token of a session with new access and refresh tokens.
+func (s userService) renewTokens(user model.User, sessionID string, ip string) (schema.Token, error) {
+	refreshToken, record, err := s.newRefreshToken(user.ID, sessionID)
+	if err != nil {
+		return schema.Token{}, err
+	}
+
+	if err = s.tokenRepo.Replace(&record); err != nil {
+		return schema.Token{}, err
+	}
+	if err = s.sessionRepo.Touch(sessionID, ip, record.ExpiresAt); err != nil {
+		return schema.Token{}, err
+	}
+
+	return s.newToken(user, refreshToken, record)
+}
+
+func (s userService) ListSessions(userID int, currentSessionID string) ([]schema.Session, error) {
+	sessions, err := s.sessionRepo.FindActive(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]schema.Session, 0, len(sessions))
+	for _, session := range sessions {
+		result = append(result, schema.Session{
+			ID:         session.ID,
+			UserAgent:  session.UserAgent,
+			IP:         session.IP,
+			CreatedAt:  session.CreatedAt,
+			LastUsedAt: session.LastUsedAt,
+			Current:    session.ID == currentSessionID,
+		})
+	}
+	return result, nil
+}
+
+func (s userService) RevokeSession(userID int, sessionID string) error {
+	session, err := s.sessionRepo.GetByID(sessionID)
+	if err != nil {
+		return err
+	}
+
+	// sessions of other users are not disclosed
+	if session.UserID != userID || session.RevokedAt != nil {
+		return exception.ErrRecordNotFound
+	}
+
+	return s.revokeSessions(sessionID)
+}
+
+func (s userService) RevokeAllSessions(userID int, exceptSessionID string) error {
+	sessions, err := s.sessionRepo.FindActive(userID)
+	if err != nil {
+		return err
+	}
+
+	var sessionIDs []string
+	for _, session := range sessions {
+		if session.ID != exceptSessionID {
+			sessionIDs = append(sessionIDs, session.ID)
+		}
+	}
+	return s.revokeSessions(sessionIDs...)
+}
+
+func (s userService) Logout(sessionID string) error {
+	if sessionID == "" {
+		return nil
+	}
+	return s.revokeSessions(sessionID)
+}
+
+// revokeSessions revokes the refresh tokens of sessions, and their access tokens until they expire.
+func (s userService) revokeSessions(sessionIDs ...string) error {
+	if err := s.sessionRepo.Revoke(sessionIDs...); err != nil {
+		return err
+	}
+
+	expiresAt := time.Now().Add(s.accessTokenLifetime)
+	for _, sessionID := range sessionIDs {
+		if err := s.revocations.Revoke(sessionID, expiresAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// revokeReusedSession revokes the session of a refresh token used twice, as it may have been stolen.
+func (s userService) revokeReusedSession(token model.RefreshToken) error {
+	log.Printf("Refresh token reused for user %d, revoking session %s", token.UserID, token.Family)
+	if err := s.revokeSessions(token.Family); err != nil {
+		return err
+	}
+	return exception.ErrInvalidRefreshToken
+}
+
+// newToken returns new access token for the user along with its refresh token.
+func (s userService) newToken(user model.User, refreshToken string, record model.RefreshToken) (schema.Token, error) {
+	tokenID, err := randomString(16, hex.EncodeToString)
+	if err != nil {
+		return schema.Token{}, err
+	}
+
+	expiresAt := time.Now().Add(s.accessTokenLifetime)
+
+	// Create the Claims
+	claims := jwt.MapClaims{
+		"ID":    strconv.Itoa(int(user.ID)),
+		"roles": user.RoleNames(),
+		"exp":   expiresAt.Unix(),
+		"jti":   tokenID,
+		"sid":   record.Family,
+	}
+	// the token only lets the user change its password
+	if user.MustChangePassword {
+		claims["pwd_change"] = true
+	}
+	// the token only lets the user enable two-factor authentication
+	mfaSetupRequired := s.isMFARequired(user) && !user.TOTPEnabled
+	if mfaSetupRequired {
+		claims["mfa_setup"] = true
+	}
+
+	// Generate encoded token and send it as response.
+	encodedToken, err := s.keys.Sign(claims)
+	if err != nil {
+		return schema.Token{}, err
+	}
+
+	return schema.Token{
+		AccessToken:        encodedToken,
+		TokenType:          "Bearer",
+		ExpiresAt:          expiresAt,
+		ExpiresIn:          int(s.accessTokenLifetime.Seconds()),
+		RefreshToken:       refreshToken,
+		RefreshExpiresAt:   record.ExpiresAt,
+		MustChangePassword: user.MustChangePassword,
+		MFASetupRequired:   mfaSetupRequired,
+	}, nil
+}
+
+// newRefreshToken returns new refresh token of a session and the record to store it.
+func (s userService) newRefreshToken(userID int, sessionID string) (string, model.RefreshToken, error) {
+	token, err := randomString(32, base64.RawURLEncoding.EncodeToString)
+	if err != nil {
+		return "", model.RefreshToken{}, err
+	}
+
+	record := model.RefreshToken{
+		UserID:    userID,
+		Family:    sessionID,
+		Hash:      hashToken(token),
+		ExpiresAt: time.Now().Add(s.refreshTokenLifetime),
+	}
+	return token, record, nil
+}
+
+// randomString returns n random bytes encoded as a string.
+func randomString(n int, encode func([]byte) string) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return encode(b), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
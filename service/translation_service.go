@@ -0,0 +1,287 @@
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/denisyao1/welsh-academy-api/exception"
+	"github.com/denisyao1/welsh-academy-api/model"
+	"github.com/denisyao1/welsh-academy-api/repository"
+	"github.com/denisyao1/welsh-academy-api/schema"
+	"github.com/denisyao1/welsh-academy-api/util"
+)
+
+// TranslationService contains business logic to translate recipes and ingredients
+// and to show them in the language of the user.
+type TranslationService interface {
+	// ListIngredientTranslations returns the translations of an ingredient.
+	//
+	// It returns exception.ErrRecordNotFound if the ingredient doesn't exist.
+	ListIngredientTranslations(ingredientID int) ([]model.IngredientTranslation, error)
+
+	// ListRecipeTranslations returns the translations of a recipe.
+	//
+	// It returns exception.ErrRecordNotFound if the recipe doesn't exist.
+	ListRecipeTranslations(recipeID int) ([]model.RecipeTranslation, error)
+
+	// TranslateIngredient sets the name of an ingredient in a locale.
+	//
+	// It returns exception.ErrRecordNotFound if the ingredient doesn't exist,
+	// exception.ErrValidation for invalid inputs and exception.ErrDuplicateKey
+	// if another ingredient has the same name in the locale.
+	TranslateIngredient(ingredientID int, locale string, input schema.IngredientTranslation) (model.IngredientTranslation, error)
+
+	// TranslateRecipe sets the name and making of a recipe in a locale.
+	//
+	// It returns exception.ErrRecordNotFound if the recipe doesn't exist
+	// and exception.ErrValidation for invalid inputs.
+	TranslateRecipe(recipeID int, locale string, input schema.RecipeTranslation) (model.RecipeTranslation, error)
+
+	// DeleteIngredientTranslation removes the translation of an ingredient in a locale.
+	//
+	// It returns exception.ErrRecordNotFound if there is no such translation.
+	DeleteIngredientTranslation(ingredientID int, locale string) error
+
+	// DeleteRecipeTranslation removes the translation of a recipe in a locale.
+	//
+	// It returns exception.ErrRecordNotFound if there is no such translation.
+	DeleteRecipeTranslation(recipeID int, locale string) error
+
+	// LocalizeIngredients replaces the ingredient names by their translation in a locale.
+	// Ingredients without translation keep their name.
+	LocalizeIngredients(ingredients []model.Ingredient, locale string) error
+
+	// LocalizeIngredientNames returns the translation of ingredient names in a locale.
+	// Names without translation are kept.
+	LocalizeIngredientNames(names []string, locale string) ([]string, error)
+
+	// LocalizeRecipes replaces the recipe names and makings, and the names of their
+	// ingredients, by their translation in a locale. Untranslated content is kept.
+	LocalizeRecipes(recipes []model.Recipe, locale string) error
+
+	// ResolveIngredientNames adds to names the names in the default locale
+	// of the ingredients translated as one of the names.
+	ResolveIngredientNames(names []string) ([]string, error)
+}
+
+type translationService struct {
+	translationRepo repository.TranslationRepository
+	ingredientRepo  repository.IngredientRepository
+	recipeRepo      repository.RecipeRepository
+}
+
+// NewTranslationService creates new TranslationService.
+func NewTranslationService(translationRepo repository.TranslationRepository,
+	ingredientRepo repository.IngredientRepository, recipeRepo repository.RecipeRepository) TranslationService {
+	return &translationService{translationRepo: translationRepo, ingredientRepo: ingredientRepo, recipeRepo: recipeRepo}
+}
+
+func (s translationService) ListIngredientTranslations(ingredientID int) ([]model.IngredientTranslation, error) {
+	if _, err := s.ingredientRepo.GetByID(ingredientID); err != nil {
+		return nil, err
+	}
+	return s.translationRepo.FindIngredientTranslations(ingredientID)
+}
+
+func (s translationService) ListRecipeTranslations(recipeID int) ([]model.RecipeTranslation, error) {
+	if _, err := s.recipeRepo.GetByID(recipeID); err != nil {
+		return nil, err
+	}
+	return s.translationRepo.FindRecipeTranslations(recipeID)
+}
+
+func (s translationService) TranslateIngredient(ingredientID int, locale string,
+	input schema.IngredientTranslation) (model.IngredientTranslation, error) {
+	translation := model.IngredientTranslation{
+		IngredientID: ingredientID,
+		Locale:       locale,
+		Name:         util.NormalizeName(input.Name),
+		NameKey:      util.NameKey(input.Name),
+	}
+
+	if err := validateTranslationLocale(locale); err != nil {
+		return translation, err
+	}
+
+	if _, err := s.ingredientRepo.GetByID(ingredientID); err != nil {
+		return translation, err
+	}
+
+	// names must stay unique in each locale
+	namesakes, err := s.translationRepo.FindIngredientTranslationsNamed([]string{translation.Name})
+	if err != nil {
+		return translation, err
+	}
+	for _, namesake := range namesakes {
+		if namesake.Locale == locale && namesake.IngredientID != ingredientID {
+			return translation, exception.ErrDuplicateKey
+		}
+	}
+
+	err = s.translationRepo.SaveIngredientTranslation(&translation)
+	return translation, err
+}
+
+func (s translationService) TranslateRecipe(recipeID int, locale string,
+	input schema.RecipeTranslation) (model.RecipeTranslation, error) {
+	translation := model.RecipeTranslation{
+		RecipeID: recipeID,
+		Locale:   locale,
+		Name:     util.NormalizeName(input.Name),
+		Making:   strings.TrimSpace(input.Making),
+	}
+
+	if err := validateTranslationLocale(locale); err != nil {
+		return translation, err
+	}
+
+	if _, err := s.recipeRepo.GetByID(recipeID); err != nil {
+		return translation, err
+	}
+
+	err := s.translationRepo.SaveRecipeTranslation(&translation)
+	return translation, err
+}
+
+func (s translationService) DeleteIngredientTranslation(ingredientID int, locale string) error {
+	return s.translationRepo.DeleteIngredientTranslation(ingredientID, locale)
+}
+
+func (s translationService) DeleteRecipeTranslation(recipeID int, locale string) error {
+	return s.translationRepo.DeleteRecipeTranslation(recipeID, locale)
+}
+
+func (s translationService) LocalizeIngredients(ingredients []model.Ingredient, locale string) error {
+	if locale == model.DefaultLocale || len(ingredients) == 0 {
+		return nil
+	}
+
+	var ingredientIDs []int
+	for _, ingredient := range ingredients {
+		ingredientIDs = append(ingredientIDs, ingredient.ID)
+	}
+
+	translations, err := s.translationRepo.FindIngredientTranslationsIn(locale, ingredientIDs)
+	if err != nil {
+		return err
+	}
+
+	names := make(map[int]string)
+	for _, translation := range translations {
+		names[translation.IngredientID] = translation.Name
+	}
+	for i, ingredient := range ingredients {
+		if name, ok := names[ingredient.ID]; ok {
+			ingredients[i].Name = name
+		}
+	}
+	return nil
+}
+
+func (s translationService) LocalizeIngredientNames(names []string, locale string) ([]string, error) {
+	if locale == model.DefaultLocale || len(names) == 0 {
+		return names, nil
+	}
+
+	ingredients, err := s.ingredientRepo.FindNamed(names)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(ingredients))
+	for _, ingredient := range ingredients {
+		keys = append(keys, util.NameKey(ingredient.Name))
+	}
+	if err = s.LocalizeIngredients(ingredients, locale); err != nil {
+		return nil, err
+	}
+	byKey := make(map[string]string)
+	for i, ingredient := range ingredients {
+		byKey[keys[i]] = ingredient.Name
+	}
+
+	localized := make([]string, 0, len(names))
+	for _, name := range names {
+		if translation, ok := byKey[util.NameKey(name)]; ok {
+			name = translation
+		}
+		localized = append(localized, name)
+	}
+	return localized, nil
+}
+
+func (s translationService) LocalizeRecipes(recipes []model.Recipe, locale string) error {
+	if locale == model.DefaultLocale || len(recipes) == 0 {
+		return nil
+	}
+
+	var recipeIDs []int
+	var ingredients []model.Ingredient
+	for _, recipe := range recipes {
+		recipeIDs = append(recipeIDs, recipe.ID)
+		ingredients = append(ingredients, recipe.Ingredients...)
+	}
+
+	translations, err := s.translationRepo.FindRecipeTranslationsIn(locale, recipeIDs)
+	if err != nil {
+		return err
+	}
+
+	byRecipe := make(map[int]model.RecipeTranslation)
+	for _, translation := range translations {
+		byRecipe[translation.RecipeID] = translation
+	}
+
+	// ingredients are localized all at once, then put back in their recipe
+	if err = s.LocalizeIngredients(ingredients, locale); err != nil {
+		return err
+	}
+
+	for i, recipe := range recipes {
+		if translation, ok := byRecipe[recipe.ID]; ok {
+			recipes[i].Name = translation.Name
+			recipes[i].Making = translation.Making
+		}
+		localized := ingredients[:len(recipe.Ingredients)]
+		ingredients = ingredients[len(recipe.Ingredients):]
+		recipes[i].Ingredients = localized
+	}
+	return nil
+}
+
+func (s translationService) ResolveIngredientNames(names []string) ([]string, error) {
+	if len(names) == 0 {
+		return names, nil
+	}
+
+	translations, err := s.translationRepo.FindIngredientTranslationsNamed(names)
+	if err != nil || len(translations) == 0 {
+		return names, err
+	}
+
+	resolved := append([]string{}, names...)
+	for _, translation := range translations {
+		ingredient, err := s.ingredientRepo.GetByID(translation.IngredientID)
+		if errors.Is(err, exception.ErrRecordNotFound) {
+			continue // in the trash
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !util.Contains(ingredient.Name, resolved) {
+			resolved = append(resolved, ingredient.Name)
+		}
+	}
+	return resolved, nil
+}
+
+// validateTranslationLocale checks a locale content can be translated in.
+func validateTranslationLocale(locale string) error {
+	if locale == model.DefaultLocale {
+		return exception.NewErrValidation("locale", exception.CodeDefaultLocale, locale)
+	}
+	if !util.Contains(locale, model.Locales) {
+		return exception.NewErrValidation("locale", exception.CodeUnsupportedLocale, locale, strings.Join(model.Locales, ", "))
+	}
+	return nil
+}
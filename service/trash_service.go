@@ -0,0 +1,120 @@
+package service
+
+import (
+	"sort"
+	"time"
+
+	"github.com/denisyao1/welsh-academy-api/exception"
+	"github.com/denisyao1/welsh-academy-api/repository"
+	"github.com/denisyao1/welsh-academy-api/schema"
+)
+
+// TrashService contains business logic to list, restore and purge deleted items.
+type TrashService interface {
+	// List returns all the items in the trash, most recently deleted first.
+	List() ([]schema.TrashItem, error)
+
+	// Restore takes an item out of the trash.
+	//
+	// It returns exception.ErrValidation if itemType is unknown
+	// and exception.ErrRecordNotFound if the item is not in the trash.
+	Restore(itemType string, itemID int) error
+
+	// Purge permanently removes items deleted for longer than the retention period.
+	Purge() (schema.PurgeResponse, error)
+}
+
+type trashService struct {
+	ingredientRepo repository.IngredientRepository
+	recipeRepo     repository.RecipeRepository
+	userRepo       repository.UserRepository
+	retention      time.Duration
+}
+
+// NewTrashService creates new TrashService.
+func NewTrashService(
+	ingredientRepo repository.IngredientRepository,
+	recipeRepo repository.RecipeRepository,
+	userRepo repository.UserRepository,
+	retention time.Duration,
+) TrashService {
+	return &trashService{
+		ingredientRepo: ingredientRepo,
+		recipeRepo:     recipeRepo,
+		userRepo:       userRepo,
+		retention:      retention,
+	}
+}
+
+func (s trashService) List() ([]schema.TrashItem, error) {
+	var items []schema.TrashItem
+
+	ingredients, err := s.ingredientRepo.FindDeleted()
+	if err != nil {
+		return nil, err
+	}
+	for _, i := range ingredients {
+		items = append(items, schema.TrashItem{
+			Type: schema.TrashIngredient, ID: i.ID, Name: i.Name, DeletedAt: i.DeletedAt.Time,
+		})
+	}
+
+	recipes, err := s.recipeRepo.FindDeleted()
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range recipes {
+		items = append(items, schema.TrashItem{
+			Type: schema.TrashRecipe, ID: r.ID, Name: r.Name, DeletedAt: r.DeletedAt.Time,
+		})
+	}
+
+	users, err := s.userRepo.FindDeleted()
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range users {
+		items = append(items, schema.TrashItem{
+			Type: schema.TrashUser, ID: u.ID, Name: u.Username, DeletedAt: u.DeletedAt.Time,
+		})
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].DeletedAt.After(items[j].DeletedAt)
+	})
+
+	return items, nil
+}
+
+func (s trashService) Restore(itemType string, itemID int) error {
+	switch itemType {
+	case schema.TrashIngredient:
+		return s.ingredientRepo.Restore(itemID)
+	case schema.TrashRecipe:
+		return s.recipeRepo.Restore(itemID)
+	case schema.TrashUser:
+		return s.userRepo.Restore(itemID)
+	}
+	return exception.NewErrValidation("type", exception.CodeInvalidItemType, itemType)
+}
+
+func (s trashService) Purge() (schema.PurgeResponse, error) {
+	var response schema.PurgeResponse
+	var err error
+
+	deletedBefore := time.Now().Add(-s.retention)
+
+	// recipes first so that their links to ingredients and users are gone
+	response.Recipes, err = s.recipeRepo.Purge(deletedBefore)
+	if err != nil {
+		return response, err
+	}
+
+	response.Ingredients, err = s.ingredientRepo.Purge(deletedBefore)
+	if err != nil {
+		return response, err
+	}
+
+	response.Users, err = s.userRepo.Purge(deletedBefore)
+	return response, err
+}
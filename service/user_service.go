@@ -1,88 +1,218 @@
 package service
 
 import (
+	"encoding/base64"
 	"errors"
+	"fmt"
 	"log"
-	"strconv"
 	"time"
 
 	"github.com/denisyao1/welsh-academy-api/exception"
 	"github.com/denisyao1/welsh-academy-api/model"
 	"github.com/denisyao1/welsh-academy-api/repository"
 	"github.com/denisyao1/welsh-academy-api/schema"
-	"github.com/golang-jwt/jwt/v5"
+	"github.com/denisyao1/welsh-academy-api/util"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type UserService interface {
-	ValidateUserCreation(userSchema schema.User) []error
-
 	// validateCredentials checks if login informations are valid
 	validateCredentials(loginSchema schema.Login) (model.User, error)
 
 	// Create create new user
 	Create(userSchema schema.User) (model.User, error)
 
-	// CreateAccessToken return new access token
-	CreateAccessToken(loginSchema schema.Login) (string, error)
+	// ValidateUserCreation checks the password policy and that the username is free,
+	// returning the user to create with its hashed password and roles, reader by default.
+	//
+	// It returns exception.ErrDuplicateKey if the username is used, and
+	// exception.ErrValidations if the password breaks the policy.
+	ValidateUserCreation(userSchema schema.User) (model.User, error)
+
+	// CreateTokens checks the credentials and opens a session from a device,
+	// returning its access and refresh tokens.
+	//
+	// For users with two-factor authentication, it returns a challenge instead,
+	// to complete with CompleteMFALogin.
+	CreateTokens(loginSchema schema.Login, userAgent string, ip string) (schema.Token, *schema.MFAChallenge, error)
+
+	// CompleteMFALogin checks the code of a challenge and opens the session like CreateTokens.
+	//
+	// It returns an error of code exception.CodeInvalidMFAChallenge if the challenge
+	// is unknown or expired, and of code exception.CodeInvalidOTP if the code is wrong.
+	CompleteMFALogin(input schema.MFALogin, userAgent string, ip string) (schema.Token, error)
+
+	// EnrolTOTP generates new TOTP secret for a user given its password, used once
+	// enabled with EnableTOTP.
+	//
+	// It returns exception.ErrValidations if the password is wrong, and an error
+	// of code exception.CodeMFAAlreadyEnabled if it's already enabled.
+	EnrolTOTP(userID int, input schema.PasswordConfirmation) (schema.TOTPEnrolment, error)
+
+	// EnableTOTP enables two-factor authentication given a code of the enrolled secret,
+	// returning new recovery codes.
+	//
+	// It returns an error of code exception.CodeMFANotEnrolled if there is no enrolled secret.
+	EnableTOTP(userID int, input schema.TOTPCode) (schema.RecoveryCodes, error)
+
+	// DisableTOTP disables two-factor authentication given a code, or a recovery code.
+	//
+	// It returns an error of code exception.CodeMFAMandatory for users who must use it.
+	DisableTOTP(userID int, input schema.TOTPCode) error
+
+	// RefreshTokens replaces a refresh token with new access and refresh tokens.
+	//
+	// It returns exception.ErrInvalidRefreshToken if the refresh token is unknown,
+	// expired or revoked. Reusing a replaced refresh token revokes all the tokens
+	// issued since the login.
+	RefreshTokens(refreshToken string, ip string) (schema.Token, error)
+
+	// ListSessions returns the active sessions of a user, most recently used first.
+	ListSessions(userID int, currentSessionID string) ([]schema.Session, error)
 
-	// UpdatePaswword Updates connected user password.
+	// RevokeSession revokes a session of a user: its tokens are rejected.
+	//
+	// It returns exception.ErrRecordNotFound if the user has no such active session.
+	RevokeSession(userID int, sessionID string) error
+
+	// RevokeAllSessions revokes the sessions of a user, except one if exceptSessionID isn't empty.
+	RevokeAllSessions(userID int, exceptSessionID string) error
+
+	// Logout revokes the session of the connected user, if any.
+	Logout(sessionID string) error
+
+	// UpdatePaswword Updates connected user password after checking the current one,
+	// revoking the other sessions of the user. It returns new tokens for the current session.
 	//
 	// if it receives bad input, it can returns :
-	//		- exception.ErrInvalidPassword
 	//		- exception.ErrRecordNotFound
 	//      - exception.ErrPasswordSame
-	UpdatePaswword(userID int, newPwd schema.Password) error
+	//      - exception.ErrValidations if the current password is wrong or the new one breaks the policy
+	UpdatePaswword(userID int, currentSessionID string, ip string, newPwd schema.Password) (schema.Token, error)
 
 	// GetInfos returns the connected user model object.
 	//
 	//it returns exception.ErrRecordNotFound if user is not found
 	GetInfos(userID int) (model.User, error)
 
-	// CreateDefaultAdmin adds default admin user to DB
-	CreateDefaultAdmin()
+	// Bootstrap creates the initial admin when there is no admin, with a random
+	// password when none is given. Its password must be changed on first login.
+	// A user in the trash with the username is restored as the initial admin.
+	//
+	// An admin still having the former default credentials admin/admin
+	// is also made to change its password.
+	Bootstrap(username string, password string) error
 
 	// CreateIfNotExist creates a user in the DB if it's not already created.
 	CreateIfNotExist(user *model.User) error
-}
 
-type userService struct {
-	repo       repository.UserRepository
-	jwt_secret string
-}
+	// ListRoles returns the roles which can be assigned to users, with their permissions.
+	ListRoles() []schema.Role
+
+	// SetRoles replaces the roles of a user. They are in the tokens issued afterwards.
+	//
+	// It returns exception.ErrRecordNotFound if the user doesn't exist,
+	// and an error of code exception.CodeLastAdmin if it is the last admin
+	// losing the admin role.
+	SetRoles(userID int, input schema.UserRoles) (model.User, error)
+
+	// List returns a page of users whose username contains search, and the number of matching users.
+	List(search string, page int, limit int) ([]model.User, int64, error)
+
+	// Update changes the roles of a user when they are given.
+	//
+	// It returns the same errors as SetRoles.
+	Update(userID int, input schema.UserUpdate) (model.User, error)
 
-func NewUserService(repo repository.UserRepository, jwt_secret string) UserService {
-	return &userService{repo: repo, jwt_secret: jwt_secret}
+	// Disable prevents a user from logging in and revokes its sessions.
+	//
+	// It returns exception.ErrRecordNotFound if the user doesn't exist,
+	// and an error of code exception.CodeLastAdmin if it is the last admin.
+	Disable(userID int) error
+
+	// Enable lets a disabled user log in again.
+	//
+	// It returns exception.ErrRecordNotFound if the user doesn't exist.
+	Enable(userID int) error
+
+	// Unlock lets a user locked out after failed logins log in again at once.
+	//
+	// It returns exception.ErrRecordNotFound if the user doesn't exist.
+	Unlock(userID int) error
+
+	// ResetPassword replaces the password of a user with a random one, returned
+	// only once, and revokes its sessions. The user must change it on login.
+	//
+	// It returns exception.ErrRecordNotFound if the user doesn't exist.
+	ResetPassword(userID int) (string, error)
+
+	// Delete moves a user to the trash, removes its favorites and revokes its sessions.
+	//
+	// It returns exception.ErrRecordNotFound if the user doesn't exist,
+	// and an error of code exception.CodeLastAdmin if it is the last admin.
+	Delete(userID int) error
 }
 
-func (s userService) ValidateUserCreation(userSchema schema.User) []error {
-	var newErrValidation = exception.NewErrValidation
-	var errs []error
+// number of random bytes of the temporary passwords, giving 16 characters
+const temporaryPasswordBytes = 12
 
-	if userSchema.Username == "" {
-		errs = append(errs, newErrValidation("username", "username is required"))
-	}
+type userService struct {
+	repo                 repository.UserRepository
+	sessionRepo          repository.SessionRepository
+	tokenRepo            repository.RefreshTokenRepository
+	revocations          RevocationService
+	statuses             UserStatusService
+	keys                 KeyService
+	passwords            PasswordPolicy
+	throttle             LoginThrottle
+	mfaRepo              repository.MFARepository
+	mfaRequiredForAdmins bool
+	accessTokenLifetime  time.Duration
+	refreshTokenLifetime time.Duration
+}
 
-	// Username must be at least 3 characters long
-	if len([]rune(userSchema.Username)) < 3 {
-		errs = append(errs, newErrValidation("username", "username must be at least 3 characters long"))
+func NewUserService(repo repository.UserRepository, sessionRepo repository.SessionRepository,
+	tokenRepo repository.RefreshTokenRepository, revocations RevocationService, statuses UserStatusService, keys KeyService,
+	passwords PasswordPolicy, throttle LoginThrottle, mfaRepo repository.MFARepository, mfaRequiredForAdmins bool,
+	accessTokenLifetime time.Duration, refreshTokenLifetime time.Duration) UserService {
+	return &userService{
+		repo:                 repo,
+		sessionRepo:          sessionRepo,
+		tokenRepo:            tokenRepo,
+		revocations:          revocations,
+		statuses:             statuses,
+		keys:                 keys,
+		passwords:            passwords,
+		throttle:             throttle,
+		mfaRepo:              mfaRepo,
+		mfaRequiredForAdmins: mfaRequiredForAdmins,
+		accessTokenLifetime:  accessTokenLifetime,
+		refreshTokenLifetime: refreshTokenLifetime,
 	}
+}
 
-	if userSchema.Password == "" {
-		errs = append(errs, newErrValidation("password", "password is required"))
+func (s userService) Create(userSchema schema.User) (model.User, error) {
+	user, err := s.ValidateUserCreation(userSchema)
+	if err != nil {
+		return user, err
 	}
 
-	// Password must be at least 4 characters long
-	if len([]rune(userSchema.Password)) < 4 {
-		errs = append(errs, newErrValidation("password", "password must be at least 4 characters long"))
-	}
+	err = s.repo.Create(&user)
 
-	return errs
+	return user, err
 }
 
-func (s userService) Create(userSchema schema.User) (model.User, error) {
+func (s userService) ValidateUserCreation(userSchema schema.User) (model.User, error) {
 
-	user := model.User{Username: userSchema.Username, IsAdmin: userSchema.IsAdmin}
+	roles := userSchema.Roles
+	if len(roles) == 0 {
+		roles = []model.Role{model.RoleReader}
+	}
+	user := model.User{Username: userSchema.Username, Roles: newUserRoles(roles)}
+
+	if errs := s.passwords.Check(userSchema.Username, userSchema.Password); errs != nil {
+		return user, exception.NewErrValidations(errs...)
+	}
 
 	// Check if username is already used in DB
 	ok, checkErr := s.repo.IsNotCreated(user)
@@ -96,137 +226,242 @@ func (s userService) Create(userSchema schema.User) (model.User, error) {
 	}
 
 	// hash password
-	hash, hashErr := bcrypt.GenerateFromPassword([]byte(userSchema.Password), 10)
+	hash, hashErr := s.passwords.Hash(userSchema.Password)
 	if hashErr != nil {
 		return user, hashErr
 	}
 
-	user.Password = string(hash)
-
-	err := s.repo.Create(&user)
+	user.Password = hash
 
-	return user, err
+	return user, nil
 }
 
 func (s userService) validateCredentials(loginSchema schema.Login) (model.User, error) {
 	var user model.User
 
-	if loginSchema.Username == "" || loginSchema.Password == "" {
-		return user, exception.ErrInvalidCredentials
-	}
-
-	// username must be at least 3 characters long; password at least 4 characters long
-	if len([]rune(loginSchema.Username)) < 3 || len([]rune(loginSchema.Password)) < 4 {
-		return user, exception.ErrInvalidCredentials
-	}
-
 	user.Username = loginSchema.Username
 	err := s.repo.GetByUsername(&user)
-	if err != nil {
-		if errors.Is(err, exception.ErrRecordNotFound) {
-			return user, exception.ErrInvalidCredentials
-		}
+	if err != nil && !errors.Is(err, exception.ErrRecordNotFound) {
 		return user, err
 	}
 
-	if user.ID == 0 {
+	// unknown users take as long as wrong passwords, not to disclose which usernames exist
+	if !s.passwords.Verify(user.Password, loginSchema.Password) || user.ID == 0 {
 		return user, exception.ErrInvalidCredentials
 	}
 
-	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(loginSchema.Password))
+	// only told to users knowing the password
+	if user.DisabledAt != nil {
+		return user, exception.New(exception.CodeUserDisabled)
+	}
 
-	if err != nil {
-		return user, exception.ErrInvalidCredentials
+	// the password is only known here to hash it with a new cost
+	if s.passwords.NeedsRehash(user.Password) {
+		if err = s.rehashPassword(&user, loginSchema.Password); err != nil {
+			log.Printf("Failed to rehash the password of user %d: %v", user.ID, err)
+		}
 	}
 
 	return user, nil
 }
 
-func (s userService) CreateAccessToken(loginSchema schema.Login) (string, error) {
-	// validate user credentials
-	user, err := s.validateCredentials(loginSchema)
+func (s userService) UpdatePaswword(userID int, currentSessionID string, ip string, newPwdSchema schema.Password) (schema.Token, error) {
+	// retrieve user from database
+	var user model.User
+	user.ID = userID
+	err := s.repo.GetByID(&user)
 	if err != nil {
-		return "", err
+		return schema.Token{}, err
 	}
 
-	role := model.RoleUser
-	if user.IsAdmin {
-		role = model.RoleAdmin
+	if user.ID == 0 {
+		return schema.Token{}, exception.ErrRecordNotFound
 	}
 
-	// Create the Claims
-	claims := jwt.MapClaims{
-		"ID":   strconv.Itoa(int(user.ID)),
-		"role": role,
-		"exp":  time.Now().Add(time.Hour * 24).Unix(),
+	// a stolen token is not enough to change the password
+	if !s.passwords.Verify(user.Password, newPwdSchema.CurrentPassword) {
+		return schema.Token{}, exception.NewErrValidations(exception.NewErrValidation("currentPassword", exception.CodeWrongPassword))
 	}
 
-	// Create token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	if errs := s.passwords.Check(user.Username, newPwdSchema.Password); errs != nil {
+		return schema.Token{}, exception.NewErrValidations(errs...)
+	}
 
-	// Generate encoded token and send it as response.
-	encodedToken, err := token.SignedString([]byte(s.jwt_secret))
-	if err != nil {
-		return "", err
+	if newPwdSchema.Password == newPwdSchema.CurrentPassword {
+		return schema.Token{}, exception.ErrPasswordSame
+	}
+
+	hash, hashErr := s.passwords.Hash(newPwdSchema.Password)
+	if hashErr != nil {
+		return schema.Token{}, hashErr
+	}
+
+	now := time.Now()
+	user.Password = hash
+	user.MustChangePassword = false
+	user.PasswordChangedAt = &now
+
+	if err = s.repo.UpdatePassword(&user); err != nil {
+		return schema.Token{}, err
 	}
+	log.Printf("User %d changed its password, revoking its other sessions", userID)
 
-	return encodedToken, nil
+	if err = s.RevokeAllSessions(userID, currentSessionID); err != nil {
+		return schema.Token{}, err
+	}
+	return s.renewTokens(user, currentSessionID, ip)
 }
 
-func (s userService) UpdatePaswword(userID int, newPwdSchema schema.Password) error {
-	//check if password is valid
-	if newPwdSchema.Password == "" || len([]rune(newPwdSchema.Password)) < 4 {
-		return exception.ErrInvalidPassword
+// rehashPassword hashes the password of a user again with the current cost.
+func (s userService) rehashPassword(user *model.User, password string) error {
+	hash, err := s.passwords.Hash(password)
+	if err != nil {
+		return err
 	}
+	user.Password = hash
+	return s.repo.UpdatePassword(user)
+}
 
-	// retrieve user from database
+func (s userService) GetInfos(userID int) (model.User, error) {
 	var user model.User
 	user.ID = userID
 	err := s.repo.GetByID(&user)
+	return user, err
+}
+
+func (s userService) Bootstrap(username string, password string) error {
+	admins, err := s.repo.CountWithRole(model.RoleAdmin)
 	if err != nil {
 		return err
 	}
-
-	if user.ID == 0 {
-		return exception.ErrRecordNotFound
+	if admins != 0 {
+		return s.expireDefaultAdminPassword()
 	}
-	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(newPwdSchema.Password))
-	if err == nil {
-		return exception.ErrPasswordSame
+
+	generated := password == ""
+	if generated {
+		password, err = randomString(temporaryPasswordBytes, base64.RawURLEncoding.EncodeToString)
+		if err != nil {
+			return err
+		}
 	}
 
-	hash, hashErr := bcrypt.GenerateFromPassword([]byte(newPwdSchema.Password), 10)
-	if hashErr != nil {
-		return hashErr
+	admin := model.User{
+		Username:           username,
+		Password:           password,
+		Roles:              newUserRoles([]model.Role{model.RoleAdmin}),
+		MustChangePassword: true,
 	}
 
-	user.Password = string(hash)
+	ok, err := s.repo.IsNotCreated(admin)
+	if err != nil {
+		return err
+	}
+	action := "created"
+	if ok {
+		err = s.CreateIfNotExist(&admin)
+	} else {
+		// users in the trash still hold their username
+		action = "restored from the trash"
+		ok, err = s.restoreAsAdmin(&admin)
+	}
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("username %q of the initial admin is already used", username)
+	}
 
-	return s.repo.UpdatePassword(&user)
+	// the generated password is only shown here
+	if generated {
+		log.Printf("Initial admin %q %s with the temporary password %q, to be changed on first login.", admin.Username, action, password)
+	} else {
+		log.Printf("Initial admin %q %s, its password must be changed on first login.", admin.Username, action)
+	}
+	return nil
 }
 
-func (s userService) GetInfos(userID int) (model.User, error) {
+// restoreAsAdmin takes the user in the trash with the username of admin out of it,
+// as an enabled admin with the password of admin and without second factor.
+// It returns false if there is no such user.
+func (s userService) restoreAsAdmin(admin *model.User) (bool, error) {
+	deleted, err := s.repo.FindDeleted()
+	if err != nil {
+		return false, err
+	}
+
 	var user model.User
-	user.ID = userID
-	err := s.repo.GetByID(&user)
-	return user, err
-}
+	for _, candidate := range deleted {
+		if util.NameKey(candidate.Username) == util.NameKey(admin.Username) {
+			user = candidate
+			break
+		}
+	}
+	if user.ID == 0 {
+		return false, nil
+	}
 
-func (s userService) CreateDefaultAdmin() {
-	var admin model.User
-	admin.Username = "admin"
-	admin.Password = "admin"
-	admin.IsAdmin = true
+	if err = s.repo.Restore(user.ID); err != nil {
+		return false, err
+	}
+	if err = s.repo.SetRoles(user.ID, []model.Role{model.RoleAdmin}); err != nil {
+		return false, err
+	}
+	if err = s.repo.SetDisabledAt(user.ID, nil); err != nil {
+		return false, err
+	}
 
-	// check if admin is already created
-	// or created it if already exist
-	err := s.CreateIfNotExist(&admin)
+	hash, err := s.passwords.Hash(admin.Password)
 	if err != nil {
-		// Unexpected error occured exist
-		log.Fatalln("Unexpected error : ", err.Error())
-		log.Fatal("Failed to create default admin user")
+		return false, err
 	}
-	log.Println("Default admin user created succssefully.")
+	user.Password = hash
+	user.MustChangePassword = true
+	if err = s.repo.UpdatePassword(&user); err != nil {
+		return false, err
+	}
+
+	// the former second factor may be lost with the account
+	if user.TOTPEnabled {
+		user.TOTPEnabled = false
+		user.TOTPSecret = ""
+		if err = s.repo.UpdateTOTP(&user); err != nil {
+			return false, err
+		}
+		if err = s.mfaRepo.SetRecoveryCodes(user.ID, nil); err != nil {
+			return false, err
+		}
+	}
+
+	admin.ID = user.ID
+	admin.Username = user.Username
+	return true, nil
+}
+
+// expireDefaultAdminPassword makes the admin created with the former default
+// credentials change its password.
+func (s userService) expireDefaultAdminPassword() error {
+	user := model.User{Username: "admin"}
+	if err := s.repo.GetByUsername(&user); err != nil {
+		if errors.Is(err, exception.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	if !user.HasRole(model.RoleAdmin) || user.MustChangePassword {
+		return nil
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte("admin")) != nil {
+		return nil
+	}
+
+	user.MustChangePassword = true
+	if err := s.repo.UpdatePassword(&user); err != nil {
+		return err
+	}
+	log.Println("The admin user still has the default password, it must be changed on next login.")
+	return nil
 }
 
 func (s userService) CreateIfNotExist(user *model.User) error {
@@ -237,10 +472,152 @@ func (s userService) CreateIfNotExist(user *model.User) error {
 	if user.ID != 0 {
 		return nil
 	}
-	hash, hashErr := bcrypt.GenerateFromPassword([]byte(user.Password), 10)
+	if len(user.Roles) == 0 {
+		user.Roles = newUserRoles([]model.Role{model.RoleReader})
+	}
+	hash, hashErr := s.passwords.Hash(user.Password)
 	if hashErr != nil {
 		return hashErr
 	}
-	user.Password = string(hash)
+	user.Password = hash
 	return s.repo.Create(user)
 }
+
+func (s userService) ListRoles() []schema.Role {
+	roles := make([]schema.Role, 0, len(model.Roles))
+	for _, role := range model.Roles {
+		roles = append(roles, schema.Role{Name: role, Permissions: model.RolePermissions[role]})
+	}
+	return roles
+}
+
+func (s userService) SetRoles(userID int, input schema.UserRoles) (model.User, error) {
+	user := model.User{ID: userID}
+	if err := s.repo.GetByID(&user); err != nil {
+		return user, err
+	}
+
+	if !util.Contains(model.RoleAdmin, input.Roles) {
+		if err := s.checkNotLastAdmin(user); err != nil {
+			return user, err
+		}
+	}
+
+	if err := s.repo.SetRoles(userID, input.Roles); err != nil {
+		return user, err
+	}
+
+	user = model.User{ID: userID}
+	err := s.repo.GetByID(&user)
+	return user, err
+}
+
+func (s userService) List(search string, page int, limit int) ([]model.User, int64, error) {
+	return s.repo.Find(search, (page-1)*limit, limit)
+}
+
+func (s userService) Update(userID int, input schema.UserUpdate) (model.User, error) {
+	if len(input.Roles) != 0 {
+		return s.SetRoles(userID, schema.UserRoles{Roles: input.Roles})
+	}
+	return s.GetInfos(userID)
+}
+
+func (s userService) Disable(userID int) error {
+	user := model.User{ID: userID}
+	if err := s.repo.GetByID(&user); err != nil {
+		return err
+	}
+	if err := s.checkNotLastAdmin(user); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if err := s.repo.SetDisabledAt(userID, &now); err != nil {
+		return err
+	}
+	s.statuses.Invalidate(userID)
+	return s.RevokeAllSessions(userID, "")
+}
+
+func (s userService) Enable(userID int) error {
+	if err := s.repo.SetDisabledAt(userID, nil); err != nil {
+		return err
+	}
+	s.statuses.Invalidate(userID)
+	return nil
+}
+
+func (s userService) Unlock(userID int) error {
+	user := model.User{ID: userID}
+	if err := s.repo.GetByID(&user); err != nil {
+		return err
+	}
+	return s.throttle.Unlock(user.Username)
+}
+
+func (s userService) ResetPassword(userID int) (string, error) {
+	user := model.User{ID: userID}
+	if err := s.repo.GetByID(&user); err != nil {
+		return "", err
+	}
+
+	password, err := randomString(temporaryPasswordBytes, base64.RawURLEncoding.EncodeToString)
+	if err != nil {
+		return "", err
+	}
+
+	hash, err := s.passwords.Hash(password)
+	if err != nil {
+		return "", err
+	}
+
+	user.Password = hash
+	user.MustChangePassword = true
+	if err = s.repo.UpdatePassword(&user); err != nil {
+		return "", err
+	}
+	return password, s.RevokeAllSessions(userID, "")
+}
+
+func (s userService) Delete(userID int) error {
+	user := model.User{ID: userID}
+	if err := s.repo.GetByID(&user); err != nil {
+		return err
+	}
+	if err := s.checkNotLastAdmin(user); err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(userID); err != nil {
+		return err
+	}
+	s.statuses.Invalidate(userID)
+	return s.RevokeAllSessions(userID, "")
+}
+
+// checkNotLastAdmin returns an error of code exception.CodeLastAdmin if the user
+// is the last enabled admin, there must be one left to manage users.
+func (s userService) checkNotLastAdmin(user model.User) error {
+	if !user.HasRole(model.RoleAdmin) || user.DisabledAt != nil {
+		return nil
+	}
+
+	admins, err := s.repo.CountWithRole(model.RoleAdmin)
+	if err != nil {
+		return err
+	}
+	if admins <= 1 {
+		return exception.New(exception.CodeLastAdmin)
+	}
+	return nil
+}
+
+// newUserRoles returns the assignments of roles to a user.
+func newUserRoles(roles []model.Role) []model.UserRole {
+	userRoles := make([]model.UserRole, 0, len(roles))
+	for _, role := range roles {
+		userRoles = append(userRoles, model.UserRole{Role: role})
+	}
+	return userRoles
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/denisyao1/welsh-academy-api/exception"
+	"github.com/denisyao1/welsh-academy-api/model"
+	"github.com/denisyao1/welsh-academy-api/repository"
+)
+
+// time the status of a user is cached before being reloaded from the database,
+// to take the users disabled or deleted by other instances of the API into account
+const userStatusCacheTTL = 5 * time.Second
+
+// UserStatusService tells if the users of access tokens can still use them.
+type UserStatusService interface {
+	// IsActive returns true if the user with an ID is neither disabled nor deleted.
+	IsActive(userID int) (bool, error)
+
+	// Invalidate forgets the cached status of a user whose status changed.
+	Invalidate(userID int)
+}
+
+type userStatus struct {
+	active   bool
+	loadedAt time.Time
+}
+
+type userStatusService struct {
+	repo repository.UserRepository
+
+	mu          sync.RWMutex
+	statuses    map[int]userStatus
+	invalidated uint64 // number of invalidations, not to cache statuses loaded before one
+}
+
+// NewUserStatusService creates new UserStatusService.
+func NewUserStatusService(repo repository.UserRepository) UserStatusService {
+	return &userStatusService{repo: repo, statuses: make(map[int]userStatus)}
+}
+
+func (s *userStatusService) IsActive(userID int) (bool, error) {
+	s.mu.RLock()
+	status, ok := s.statuses[userID]
+	invalidated := s.invalidated
+	s.mu.RUnlock()
+	if ok && time.Since(status.loadedAt) <= userStatusCacheTTL {
+		return status.active, nil
+	}
+
+	// deleted users are not found
+	user := model.User{ID: userID}
+	err := s.repo.GetByID(&user)
+	if err != nil && !errors.Is(err, exception.ErrRecordNotFound) {
+		return false, err
+	}
+	status = userStatus{active: err == nil && user.DisabledAt == nil, loadedAt: time.Now()}
+
+	s.mu.Lock()
+	if s.invalidated == invalidated {
+		s.statuses[userID] = status
+	}
+	s.mu.Unlock()
+	return status.active, nil
+}
+
+func (s *userStatusService) Invalidate(userID int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.statuses, userID)
+	s.invalidated++
+}
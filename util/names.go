@@ -0,0 +1,29 @@
+package util
+
+import (
+	"strings"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// NormalizeName trims a name, collapses its inner whitespace
+// and puts it in Unicode normalization form C.
+func NormalizeName(name string) string {
+	return norm.NFC.String(strings.Join(strings.Fields(name), " "))
+}
+
+// NameKey returns the key names are compared with for uniqueness,
+// so that "Tomato" and "tomato " are the same name.
+func NameKey(name string) string {
+	return norm.NFC.String(cases.Fold().String(NormalizeName(name)))
+}
+
+// NameKeys returns the keys of names.
+func NameKeys(names []string) []string {
+	keys := make([]string, 0, len(names))
+	for _, name := range names {
+		keys = append(keys, NameKey(name))
+	}
+	return keys
+}
@@ -1,5 +1,12 @@
 package util
 
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+)
+
 func SliceHasNoDuplicate[T comparable](slice []T) bool {
 	m := make(map[T]int)
 	for _, v := range slice {
@@ -20,3 +27,39 @@ func Contains[T comparable](elm T, slice []T) bool {
 	}
 	return false
 }
+
+var isoDurationRegex = regexp.MustCompile(`^P(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+
+// ParseISODuration converts an ISO 8601 duration like PT1H30M to minutes.
+func ParseISODuration(duration string) (int, error) {
+	matches := isoDurationRegex.FindStringSubmatch(duration)
+	if matches == nil || duration == "P" || duration == "PT" {
+		return 0, fmt.Errorf("invalid ISO 8601 duration: %q", duration)
+	}
+
+	var minutes float64
+	units := []float64{24 * 60, 60, 1, 1.0 / 60}
+	for i, unit := range units {
+		if matches[i+1] == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(matches[i+1], 64)
+		if err != nil {
+			return 0, err
+		}
+		minutes += v * unit
+	}
+	return int(math.Round(minutes)), nil
+}
+
+// FormatISODuration converts minutes to an ISO 8601 duration like PT1H30M.
+func FormatISODuration(minutes int) string {
+	hours, minutes := minutes/60, minutes%60
+	switch {
+	case hours == 0:
+		return fmt.Sprintf("PT%dM", minutes)
+	case minutes == 0:
+		return fmt.Sprintf("PT%dH", hours)
+	}
+	return fmt.Sprintf("PT%dH%dM", hours, minutes)
+}
@@ -61,3 +61,57 @@ func TestContains(t *testing.T) {
 		assert.Equal(d.output, Contains(d.elm, d.slice))
 	}
 }
+
+func TestParseISODuration(t *testing.T) {
+	assert := assert.New(t)
+
+	data := []struct {
+		input   string
+		minutes int
+		valid   bool
+	}{
+		{input: "PT20M", minutes: 20, valid: true},
+		{input: "PT1H30M", minutes: 90, valid: true},
+		{input: "P0DT2H", minutes: 120, valid: true},
+		{input: "PT90S", minutes: 2, valid: true},
+		{input: "P1D", minutes: 1440, valid: true},
+		{input: "20 minutes", valid: false},
+		{input: "PT", valid: false},
+	}
+
+	for _, d := range data {
+		minutes, err := ParseISODuration(d.input)
+		assert.Equal(d.valid, err == nil, d.input)
+		assert.Equal(d.minutes, minutes, d.input)
+	}
+}
+
+func TestFormatISODuration(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("PT20M", FormatISODuration(20))
+	assert.Equal("PT2H", FormatISODuration(120))
+	assert.Equal("PT1H5M", FormatISODuration(65))
+}
+
+func TestNameKey(t *testing.T) {
+	assert := assert.New(t)
+
+	data := []struct {
+		input      string
+		normalized string
+		key        string
+	}{
+		{input: "Tomato", normalized: "Tomato", key: "tomato"},
+		{input: "  tomato ", normalized: "tomato", key: "tomato"},
+		{input: "Welsh   Rarebit", normalized: "Welsh Rarebit", key: "welsh rarebit"},
+		{input: "Café", normalized: "Café", key: "café"},
+		{input: "STRASSE", normalized: "STRASSE", key: "strasse"},
+		{input: "Straße", normalized: "Straße", key: "strasse"},
+	}
+
+	for _, d := range data {
+		assert.Equal(d.normalized, NormalizeName(d.input), d.input)
+		assert.Equal(d.key, NameKey(d.input), d.input)
+	}
+}